@@ -0,0 +1,224 @@
+// Package client типизированный Go клиент HTTP API сервиса вопросов и ответов.
+//
+//	c, err := client.New("http://localhost:8080", client.Options{Timeout: 5 * time.Second})
+//	question, err := c.CreateQuestion(ctx, "Что такое Go?")
+//	if errors.Is(err, client.ErrQuestionAlreadyExists) { ... }
+//
+// Ошибки API возвращаются как *Error и сравниваются с ErrXxx через errors.Is.
+// GET и DELETE повторяются при сетевых ошибках, 429 и 502-504; POST повторяется
+// с тем же Idempotency-Key, поэтому повтор не создаёт дубликат на сервере с включённой идемпотентностью
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout     = 10 * time.Second
+	defaultMaxAttempts = 3
+	defaultBackoffBase = 100 * time.Millisecond
+	defaultBackoffMax  = 2 * time.Second
+
+	// maxErrorBodySize ограничивает чтение тела ответа с ошибкой
+	maxErrorBodySize = 64 << 10
+)
+
+// RetryPolicy задаёт повторы: всего MaxAttempts попыток, пауза BackoffBase * 2^(n-1),
+// но не больше BackoffMax. Retry-After из ответа 429/503 имеет приоритет.
+// Нулевые поля заменяются значениями по умолчанию: 3 попытки, 100ms, 2s
+type RetryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// Options настройки клиента; нулевое значение пригодно для использования
+type Options struct {
+	// HTTPClient по умолчанию http.DefaultClient
+	HTTPClient *http.Client
+	// Token передаётся в Authorization: Bearer, если задан
+	Token string
+	// Timeout ограничивает одну попытку запроса; по умолчанию 10s
+	Timeout time.Duration
+	Retry   RetryPolicy
+}
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	token      string
+	timeout    time.Duration
+	retry      RetryPolicy
+}
+
+// New создаёт клиент для сервиса по адресу baseURL, например http://localhost:8080
+func New(baseURL string, options Options) (*Client, error) {
+	parsed, err := url.Parse(baseURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("client: некорректный адрес сервиса %q", baseURL)
+	}
+	parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+
+	c := &Client{
+		baseURL:    parsed,
+		httpClient: options.HTTPClient,
+		token:      options.Token,
+		timeout:    options.Timeout,
+		retry:      options.Retry,
+	}
+	if c.httpClient == nil {
+		c.httpClient = http.DefaultClient
+	}
+	if c.timeout <= 0 {
+		c.timeout = defaultTimeout
+	}
+	if c.retry.MaxAttempts <= 0 {
+		c.retry.MaxAttempts = defaultMaxAttempts
+	}
+	if c.retry.BackoffBase <= 0 {
+		c.retry.BackoffBase = defaultBackoffBase
+	}
+	if c.retry.BackoffMax <= 0 {
+		c.retry.BackoffMax = defaultBackoffMax
+	}
+	return c, nil
+}
+
+// do выполняет запрос с повторами и декодирует JSON ответа в out, если out не nil
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	idempotencyKey := ""
+	if method == http.MethodPost {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := c.attempt(ctx, method, path, query, payload, idempotencyKey, out)
+		if err == nil || attempt >= c.retry.MaxAttempts || !retryable(ctx, err) {
+			return err
+		}
+
+		delay := max(c.backoff(attempt), retryAfter)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt выполняет одну попытку; retryAfter берётся из заголовка Retry-After ответа с ошибкой
+func (c *Client) attempt(ctx context.Context, method, path string, query url.Values, payload []byte, idempotencyKey string, out any) (retryAfter time.Duration, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	endpoint := *c.baseURL
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), body)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := decodeError(resp)
+		return apiErr.RetryAfter, apiErr
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return 0, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return 0, fmt.Errorf("client: некорректный ответ %s %s: %w", method, path, err)
+	}
+	return 0, nil
+}
+
+// retryable: сетевые ошибки и таймаут попытки, но не отмена контекста вызывающего
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retry.BackoffBase
+	for i := 1; i < attempt && delay < c.retry.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.retry.BackoffMax)
+}
+
+func decodeError(resp *http.Response) *Error {
+	apiErr := &Error{StatusCode: resp.StatusCode}
+	var body struct {
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxErrorBodySize)).Decode(&body); err == nil {
+		apiErr.Message = body.Error
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(resp.StatusCode)
+	}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+func newIdempotencyKey() string {
+	key := make([]byte, 16)
+	_, _ = rand.Read(key)
+	return hex.EncodeToString(key)
+}
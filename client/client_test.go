@@ -0,0 +1,421 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/api"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// memoryStore хранилище вопросов и ответов для настоящих сервисов и Router
+type memoryStore struct {
+	mu        sync.Mutex
+	questions []entity.Question
+	answers   []entity.Answer
+	nextID    int
+}
+
+func (s *memoryStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type memoryQuestionRepository struct {
+	repository.QuestionRepository
+	store *memoryStore
+}
+
+func (r *memoryQuestionRepository) Create(ctx context.Context, question *entity.Question) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.nextID++
+	question.ID = r.store.nextID
+	question.CreatedAt = time.Now()
+	r.store.questions = append(r.store.questions, *question)
+	return nil
+}
+
+func (r *memoryQuestionRepository) GetByID(ctx context.Context, id int) (*entity.Question, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, q := range r.store.questions {
+		if q.ID == id {
+			return &q, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryQuestionRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Question, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var found []entity.Question
+	for _, q := range r.store.questions {
+		if slices.Contains(ids, q.ID) {
+			found = append(found, q)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryQuestionRepository) GetByIDForShare(ctx context.Context, id int) (*entity.Question, error) {
+	return r.GetByID(ctx, id)
+}
+
+func (r *memoryQuestionRepository) GetByText(ctx context.Context, text string) (*entity.Question, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, q := range r.store.questions {
+		if q.Text == text {
+			return &q, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryQuestionRepository) GetPublishedPage(ctx context.Context, afterID, limit int, viewerID string) ([]entity.Question, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var page []entity.Question
+	for _, q := range r.store.questions {
+		if q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+type memoryAnswerRepository struct {
+	repository.AnswerRepository
+	store *memoryStore
+}
+
+func (r *memoryAnswerRepository) Create(ctx context.Context, answer *entity.Answer) (*entity.Answer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.store.nextID++
+	answer.ID = r.store.nextID
+	answer.CreatedAt = time.Now()
+	r.store.answers = append(r.store.answers, *answer)
+	return answer, nil
+}
+
+func (r *memoryAnswerRepository) GetByID(ctx context.Context, id int) (*entity.Answer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for _, a := range r.store.answers {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryAnswerRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Answer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	var found []entity.Answer
+	for _, a := range r.store.answers {
+		if slices.Contains(ids, a.ID) {
+			found = append(found, a)
+		}
+	}
+	return found, nil
+}
+
+func (r *memoryAnswerRepository) GetPublishedByQuestionID(ctx context.Context, questionID int, viewerID string) ([]entity.Answer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	answers := []entity.Answer{}
+	for _, a := range r.store.answers {
+		if a.QuestionID == questionID {
+			answers = append(answers, a)
+		}
+	}
+	return answers, nil
+}
+
+func (r *memoryAnswerRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	for i, a := range r.store.answers {
+		if a.ID == id {
+			r.store.answers = append(r.store.answers[:i], r.store.answers[i+1:]...)
+			return nil
+		}
+	}
+	return entity.ErrAnswerNotFound
+}
+
+// newTestServer поднимает настоящий Router; wrap позволяет вмешаться в запросы до него
+// testToken токен пользователя alice на тестовом сервере: ответы создаются от авторизованного пользователя
+const testToken = "alice-token"
+
+func newTestServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	store := &memoryStore{}
+	questionRepo := &memoryQuestionRepository{store: store}
+	answerRepo := &memoryAnswerRepository{store: store}
+
+	router := api.NewRouter(api.Handlers{
+		API: api.NewHandler(
+			service.NewQuestionService(questionRepo, store, nil, nil, nil),
+			service.NewAnswerService(answerRepo, questionRepo, store, nil, nil, nil),
+			nil,
+			5,
+		),
+	})
+	auditMiddleware, err := api.AuditMiddleware(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var handler http.Handler = api.Chain(router.Setup(),
+		api.RecoverMiddleware,
+		api.AuthMiddleware(service.NewStaticTokenAuthService(map[string]string{testToken: "alice"})),
+		auditMiddleware,
+	)
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, server *httptest.Server, options Options) *Client {
+	t.Helper()
+	c, err := New(server.URL, options)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return c
+}
+
+func TestClient_QuestionsAndAnswers(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newTestServer(t, nil), Options{Token: testToken})
+
+	question, err := c.CreateQuestion(ctx, "Что такое Go?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if question.ID == 0 || question.Text != "Что такое Go?" {
+		t.Errorf("expected created question, got %+v", question)
+	}
+
+	if _, err := c.CreateAnswer(ctx, question.ID, "bob", "Чужое имя"); !errors.Is(err, ErrUserIDMismatch) {
+		t.Errorf("expected ErrUserIDMismatch, got %v", err)
+	}
+	answer, err := c.CreateAnswer(ctx, question.ID, "", "Язык программирования")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer.QuestionID != question.ID || answer.UserID != "alice" {
+		t.Errorf("expected answer to question %d by alice, got %+v", question.ID, answer)
+	}
+
+	got, err := c.GetQuestion(ctx, question.ID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got.Answers) != 1 || got.Answers[0].ID != answer.ID || got.Answers[0].QuestionID != question.ID {
+		t.Errorf("expected question with the created answer, got %+v", got.Answers)
+	}
+
+	if err := c.DeleteAnswer(ctx, answer.ID); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := c.GetAnswer(ctx, answer.ID); !errors.Is(err, ErrAnswerNotFound) {
+		t.Errorf("expected ErrAnswerNotFound, got %v", err)
+	}
+}
+
+func TestClient_BatchGet(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newTestServer(t, nil), Options{Token: testToken})
+
+	first, err := c.CreateQuestion(ctx, "Первый")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := c.CreateQuestion(ctx, "Второй")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	answer, err := c.CreateAnswer(ctx, first.ID, "alice", "Ответ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	questions, missing, err := c.GetQuestionsByIDs(ctx, []int{second.ID, 999, first.ID})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(questions) != 2 || questions[0].ID != second.ID || questions[1].ID != first.ID {
+		t.Errorf("expected questions in requested order, got %+v", questions)
+	}
+	if !slices.Equal(missing, []int{999}) {
+		t.Errorf("expected missing [999], got %v", missing)
+	}
+
+	answers, missing, err := c.GetAnswersByIDs(ctx, []int{answer.ID, 998})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(answers) != 1 || answers[0].ID != answer.ID || !slices.Equal(missing, []int{998}) {
+		t.Errorf("expected answer %d and missing [998], got %+v and %v", answer.ID, answers, missing)
+	}
+}
+
+func TestClient_TypedErrors(t *testing.T) {
+	ctx := context.Background()
+	c := newTestClient(t, newTestServer(t, nil), Options{})
+
+	if _, err := c.GetQuestion(ctx, 42); !errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("expected ErrQuestionNotFound, got %v", err)
+	}
+	if _, err := c.CreateQuestion(ctx, "  "); !errors.Is(err, ErrInvalidQuestionText) {
+		t.Errorf("expected ErrInvalidQuestionText, got %v", err)
+	}
+	if _, err := c.CreateQuestion(ctx, "Дубликат"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := c.CreateQuestion(ctx, "Дубликат")
+	var apiErr *Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusConflict {
+		t.Fatalf("expected *Error with status 409, got %v", err)
+	}
+	if !errors.Is(err, ErrQuestionAlreadyExists) || errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("expected error to match only ErrQuestionAlreadyExists, got %v", err)
+	}
+}
+
+func TestClient_ListQuestionsIteratesPages(t *testing.T) {
+	ctx := context.Background()
+	var pageRequests int
+	server := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Query().Has("limit") {
+				pageRequests++
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := newTestClient(t, server, Options{})
+
+	for _, text := range []string{"Q1", "Q2", "Q3", "Q4", "Q5"} {
+		if _, err := c.CreateQuestion(ctx, text); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var texts []string
+	for question, err := range c.ListQuestions(ctx, 2) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		texts = append(texts, question.Text)
+	}
+	if strings.Join(texts, ",") != "Q1,Q2,Q3,Q4,Q5" {
+		t.Errorf("expected all 5 questions in order, got %v", texts)
+	}
+	if pageRequests != 3 {
+		t.Errorf("expected 3 page requests, got %d", pageRequests)
+	}
+
+	// прерванный обход не запрашивает следующие страницы
+	pageRequests = 0
+	for range c.ListQuestions(ctx, 2) {
+		break
+	}
+	if pageRequests != 1 {
+		t.Errorf("expected 1 page request after break, got %d", pageRequests)
+	}
+}
+
+func TestClient_RetriesWithSameIdempotencyKey(t *testing.T) {
+	ctx := context.Background()
+	var keys []string
+	server := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if len(keys) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := newTestClient(t, server, Options{Retry: RetryPolicy{MaxAttempts: 3, BackoffBase: time.Millisecond}})
+
+	if _, err := c.CreateQuestion(ctx, "Повтор"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(keys) != 3 {
+		t.Fatalf("expected 3 attempts, got %d", len(keys))
+	}
+	if keys[0] == "" || keys[0] != keys[1] || keys[1] != keys[2] {
+		t.Errorf("expected the same Idempotency-Key on every attempt, got %v", keys)
+	}
+
+	// 4xx кроме 429 не повторяется
+	keys = nil
+	if _, err := c.GetQuestion(ctx, 999); !errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("expected ErrQuestionNotFound, got %v", err)
+	}
+	if len(keys) != 3 {
+		t.Errorf("expected 2 failed attempts and one request to the router, got %d", len(keys))
+	}
+}
+
+func TestClient_TimeoutPerAttempt(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	var attempts int
+	server := newTestServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				select {
+				case <-release:
+				case <-r.Context().Done():
+				}
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	c := newTestClient(t, server, Options{
+		Timeout: 50 * time.Millisecond,
+		Retry:   RetryPolicy{MaxAttempts: 2, BackoffBase: time.Millisecond},
+	})
+
+	// первая попытка зависает и обрывается по таймауту, вторая получает ответ
+	if _, err := c.GetQuestion(context.Background(), 1); !errors.Is(err, ErrQuestionNotFound) {
+		t.Errorf("expected ErrQuestionNotFound after retry, got %v", err)
+	}
+	if attempts != 2 {
+		t.Errorf("expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestNew_RejectsInvalidURL(t *testing.T) {
+	for _, baseURL := range []string{"", "localhost:8080", "ftp://example.com"} {
+		if _, err := New(baseURL, Options{}); err == nil {
+			t.Errorf("expected error for %q", baseURL)
+		}
+	}
+}
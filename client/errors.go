@@ -0,0 +1,50 @@
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// Error ответ API с ошибкой: HTTP статус и сообщение из {"error": "..."}
+type Error struct {
+	StatusCode int
+	Message    string
+	// RetryAfter из заголовка Retry-After, если сервер его прислал
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Message)
+}
+
+// Is сравнивает статус и сообщение, чтобы errors.Is(err, client.ErrQuestionNotFound) работал
+// для ошибок, полученных по сети
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.StatusCode == e.StatusCode && t.Message == e.Message
+}
+
+// Ошибки сервиса; совпадают с ошибками, которые возвращает API
+var (
+	ErrQuestionNotFound             = fromCustomError(entity.ErrQuestionNotFound)
+	ErrInvalidQuestionText          = fromCustomError(entity.ErrInvalidQuestionText)
+	ErrQuestionAlreadyExists        = fromCustomError(entity.ErrQuestionAlreadyExists)
+	ErrAnswerNotFound               = fromCustomError(entity.ErrAnswerNotFound)
+	ErrInvalidAnswerText            = fromCustomError(entity.ErrInvalidAnswerText)
+	ErrInvalidUserID                = fromCustomError(entity.ErrInvalidUserID)
+	ErrTooManyIDs                   = fromCustomError(entity.ErrTooManyIDs)
+	ErrDatabaseQuery                = fromCustomError(entity.ErrDatabaseQuery)
+	ErrUnauthorized                 = fromCustomError(entity.ErrUnauthorized)
+	ErrUserIDMismatch               = fromCustomError(entity.ErrUserIDMismatch)
+	ErrRouteNotFound                = fromCustomError(entity.ErrRouteNotFound)
+	ErrIdempotencyKeyMismatch       = fromCustomError(entity.ErrIdempotencyKeyMismatch)
+	ErrIdempotencyRequestInProgress = fromCustomError(entity.ErrIdempotencyRequestInProgress)
+	ErrPreconditionFailed           = fromCustomError(entity.ErrPreconditionFailed)
+	ErrTooManyRequests              = fromCustomError(entity.ErrTooManyRequests)
+)
+
+func fromCustomError(err entity.CustomError) *Error {
+	return &Error{StatusCode: err.Code, Message: err.Message}
+}
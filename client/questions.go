@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Question struct {
+	ID        int       `json:"id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at"`
+	// Answers заполняется только в GetQuestion
+	Answers []Answer `json:"answers,omitempty"`
+}
+
+type Answer struct {
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// QuestionsPage страница списка вопросов. В списке у вопросов есть только ID и Text
+type QuestionsPage struct {
+	Questions []Question
+	// NextAfter передаётся в следующий ListQuestionsPage; 0 — страница последняя
+	NextAfter int
+}
+
+func (c *Client) CreateQuestion(ctx context.Context, text string) (*Question, error) {
+	var question Question
+	err := c.do(ctx, http.MethodPost, "/questions/", nil, map[string]string{"text": text}, &question)
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// GetQuestion возвращает вопрос вместе с ответами
+func (c *Client) GetQuestion(ctx context.Context, id int) (*Question, error) {
+	var question Question
+	if err := c.do(ctx, http.MethodGet, "/questions/"+strconv.Itoa(id), nil, nil, &question); err != nil {
+		return nil, err
+	}
+	for i := range question.Answers {
+		question.Answers[i].QuestionID = question.ID
+	}
+	return &question, nil
+}
+
+// GetQuestionsByIDs возвращает вопросы в порядке ids одним запросом и отдельно ID, которых нет.
+// У вопросов нет ответов; ids не больше 100
+func (c *Client) GetQuestionsByIDs(ctx context.Context, ids []int) ([]Question, []int, error) {
+	query := url.Values{}
+	query.Set("ids", joinIDs(ids))
+
+	var body struct {
+		Questions []Question `json:"questions"`
+		Missing   []int      `json:"missing"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/questions", query, nil, &body); err != nil {
+		return nil, nil, err
+	}
+	return body.Questions, body.Missing, nil
+}
+
+// ListQuestionsPage возвращает до limit вопросов с ID больше after в порядке ID
+func (c *Client) ListQuestionsPage(ctx context.Context, after, limit int) (*QuestionsPage, error) {
+	query := url.Values{}
+	query.Set("after", strconv.Itoa(after))
+	query.Set("limit", strconv.Itoa(limit))
+
+	var body struct {
+		Questions []Question `json:"questions"`
+		NextAfter int        `json:"next_after"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/questions/", query, nil, &body); err != nil {
+		return nil, err
+	}
+	return &QuestionsPage{Questions: body.Questions, NextAfter: body.NextAfter}, nil
+}
+
+// ListQuestions обходит все вопросы страницами по pageSize. Ошибка возвращается последним
+// элементом итерации, после неё обход прекращается
+//
+//	for question, err := range c.ListQuestions(ctx, 100) {
+//		if err != nil { ... }
+//	}
+func (c *Client) ListQuestions(ctx context.Context, pageSize int) iter.Seq2[Question, error] {
+	return func(yield func(Question, error) bool) {
+		after := 0
+		for {
+			page, err := c.ListQuestionsPage(ctx, after, pageSize)
+			if err != nil {
+				yield(Question{}, err)
+				return
+			}
+			for _, question := range page.Questions {
+				if !yield(question, nil) {
+					return
+				}
+			}
+			if page.NextAfter == 0 {
+				return
+			}
+			after = page.NextAfter
+		}
+	}
+}
+
+func (c *Client) DeleteQuestion(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/questions/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+// CreateAnswer создаёт ответ от пользователя токена (Options.Token). userID необязателен;
+// если задан и не совпадает с пользователем токена, вернётся ErrUserIDMismatch
+func (c *Client) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*Answer, error) {
+	var answer Answer
+	path := "/questions/" + strconv.Itoa(questionID) + "/answers/"
+	body := map[string]string{"text": text}
+	if userID != "" {
+		body["user_id"] = userID
+	}
+	err := c.do(ctx, http.MethodPost, path, nil, body, &answer)
+	if err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+func (c *Client) GetAnswer(ctx context.Context, id int) (*Answer, error) {
+	var answer Answer
+	if err := c.do(ctx, http.MethodGet, "/answers/"+strconv.Itoa(id), nil, nil, &answer); err != nil {
+		return nil, err
+	}
+	return &answer, nil
+}
+
+// GetAnswersByIDs возвращает ответы в порядке ids одним запросом и отдельно ID, которых нет; ids не больше 100
+func (c *Client) GetAnswersByIDs(ctx context.Context, ids []int) ([]Answer, []int, error) {
+	var body struct {
+		Answers []Answer `json:"answers"`
+		Missing []int    `json:"missing"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/answers/batch-get", nil, map[string][]int{"ids": ids}, &body); err != nil {
+		return nil, nil, err
+	}
+	return body.Answers, body.Missing, nil
+}
+
+func (c *Client) DeleteAnswer(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/answers/"+strconv.Itoa(id), nil, nil, nil)
+}
+
+func joinIDs(ids []int) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/config"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+var errAborted = errors.New("отменено пользователем")
+
+// adminFlags общие флаги административных команд
+type adminFlags struct {
+	*flag.FlagSet
+	format *string
+	dryRun *bool
+	yes    *bool
+}
+
+func newAdminFlags(name string, destructive bool) *adminFlags {
+	flags := &adminFlags{FlagSet: flag.NewFlagSet(name, flag.ExitOnError)}
+	flags.format = flags.String("format", formatTable, "формат вывода: table или json")
+	if destructive {
+		flags.dryRun = flags.Bool("dry-run", false, "показать, что будет изменено, ничего не меняя")
+		flags.yes = flags.Bool("yes", false, "не спрашивать подтверждение")
+	}
+	return flags
+}
+
+func (f *adminFlags) parse(args []string) error {
+	_ = f.Parse(args)
+	if *f.format != formatTable && *f.format != formatJSON {
+		return fmt.Errorf("неизвестный формат вывода %q, ожидается table или json", *f.format)
+	}
+	return nil
+}
+
+// runMigrate: app migrate [status|up|down] [-steps N] [-dry-run] [-yes] [-format table|json]
+func runMigrate(cfg *config.Config, args []string) error {
+	action := "status"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+
+	flags := newAdminFlags("migrate "+action, true)
+	steps := flags.Int("steps", 1, "сколько миграций откатить (для down)")
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	db, closeDB := openDB(cfg)
+	defer closeDB()
+	migrationService := service.NewMigrationService(repository.NewMigrationRepository(db, cfg.Health.MigrationsDir))
+
+	switch action {
+	case "status":
+		migrations, err := migrationService.Status(ctx)
+		if err != nil {
+			return err
+		}
+		return printMigrations(*flags.format, migrations)
+	case "up":
+		migrations, err := migrationService.Up(ctx, *flags.dryRun)
+		if printErr := printMigrations(*flags.format, migrations); printErr != nil {
+			return printErr
+		}
+		return err
+	case "down":
+		if *steps < 1 {
+			return fmt.Errorf("-steps должен быть положительным")
+		}
+		planned, err := migrationService.Down(ctx, *steps, true)
+		if err != nil {
+			return err
+		}
+		if *flags.dryRun || len(planned) == 0 {
+			return printMigrations(*flags.format, planned)
+		}
+		if err := confirm(*flags.yes, fmt.Sprintf("Откатить миграций: %d (последняя %s)?", len(planned), planned[0].Name)); err != nil {
+			return err
+		}
+		migrations, err := migrationService.Down(ctx, *steps, false)
+		if printErr := printMigrations(*flags.format, migrations); printErr != nil {
+			return printErr
+		}
+		return err
+	default:
+		return fmt.Errorf("неизвестное действие migrate %q, ожидается status, up или down", action)
+	}
+}
+
+// runQuestions: app questions list [-after ID] [-limit N] | app questions delete [-dry-run] [-yes] ID...
+func runQuestions(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("укажите действие: list или delete")
+	}
+	action, args := args[0], args[1:]
+
+	switch action {
+	case "list":
+		flags := newAdminFlags("questions list", false)
+		after := flags.Int("after", 0, "показать вопросы с ID больше указанного")
+		limit := flags.Int("limit", 50, "сколько вопросов показать")
+		if err := flags.parse(args); err != nil {
+			return err
+		}
+
+		ctx, stop := commandContext()
+		defer stop()
+		adminService, closeDB := newAdminService(cfg)
+		defer closeDB()
+
+		questions, err := adminService.ListQuestions(ctx, *after, *limit)
+		if err != nil {
+			return err
+		}
+		return printQuestions(*flags.format, questions)
+	case "delete":
+		flags := newAdminFlags("questions delete", true)
+		if err := flags.parse(args); err != nil {
+			return err
+		}
+		ids, err := parseIDs(flags.Args())
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := commandContext()
+		defer stop()
+		adminService, closeDB := newAdminService(cfg)
+		defer closeDB()
+
+		planned, err := adminService.DeleteQuestions(ctx, ids, true)
+		if err != nil {
+			return err
+		}
+		if *flags.dryRun {
+			return printQuestions(*flags.format, planned)
+		}
+		answers := 0
+		for _, question := range planned {
+			answers += question.Answers
+		}
+		if err := confirm(*flags.yes, fmt.Sprintf("Удалить вопросов: %d и ответов к ним: %d?", len(planned), answers)); err != nil {
+			return err
+		}
+		deleted, err := adminService.DeleteQuestions(ctx, ids, false)
+		if err != nil {
+			return err
+		}
+		return printQuestions(*flags.format, deleted)
+	default:
+		return fmt.Errorf("неизвестное действие questions %q, ожидается list или delete", action)
+	}
+}
+
+// runAnswers: app answers purge -user ID [-dry-run] [-yes]
+func runAnswers(cfg *config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "purge" {
+		return fmt.Errorf("укажите действие: purge")
+	}
+
+	flags := newAdminFlags("answers purge", true)
+	userID := flags.String("user", "", "ID пользователя, чьи ответы удалить")
+	if err := flags.parse(args[1:]); err != nil {
+		return err
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+	adminService, closeDB := newAdminService(cfg)
+	defer closeDB()
+
+	planned, err := adminService.PurgeUserAnswers(ctx, *userID, true)
+	if err != nil {
+		return err
+	}
+	if *flags.dryRun || len(planned) == 0 {
+		return printAnswers(*flags.format, planned)
+	}
+	if err := confirm(*flags.yes, fmt.Sprintf("Удалить ответов пользователя %s: %d?", *userID, len(planned))); err != nil {
+		return err
+	}
+	purged, err := adminService.PurgeUserAnswers(ctx, *userID, false)
+	if err != nil {
+		return err
+	}
+	return printAnswers(*flags.format, purged)
+}
+
+// runStats: app stats [-format table|json]
+func runStats(cfg *config.Config, args []string) error {
+	flags := newAdminFlags("stats", false)
+	if err := flags.parse(args); err != nil {
+		return err
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+	adminService, closeDB := newAdminService(cfg)
+	defer closeDB()
+
+	stats, err := adminService.Stats(ctx)
+	if err != nil {
+		return err
+	}
+	if *flags.format == formatJSON {
+		return printJSON(stats)
+	}
+	return printTable([]string{"МЕТРИКА", "ЗНАЧЕНИЕ"}, [][]string{
+		{"Вопросы", strconv.FormatInt(stats.Questions, 10)},
+		{"Ответы", strconv.FormatInt(stats.Answers, 10)},
+		{"Пользователи", strconv.FormatInt(stats.Users, 10)},
+		{"Outbox: не опубликовано", strconv.FormatInt(stats.OutboxPending, 10)},
+		{"Outbox: отложено", strconv.FormatInt(stats.OutboxParked, 10)},
+		{"Активные вебхуки", strconv.FormatInt(stats.WebhooksActive, 10)},
+		{"Доставки: в очереди", strconv.FormatInt(stats.WebhookDeliveriesPending, 10)},
+		{"Доставки: dead", strconv.FormatInt(stats.WebhookDeliveriesDead, 10)},
+	})
+}
+
+// commandContext контекст команды: отменяется по SIGINT/SIGTERM и несёт данные для журнала аудита,
+// автор изменений — "cli:" и пользователь ОС
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx := service.WithAuditMeta(context.Background(), entity.AuditMeta{
+		Actor:     "cli:" + os.Getenv("USER"),
+		RequestID: service.NewRequestID(),
+	})
+	return signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+}
+
+func newAdminService(cfg *config.Config) (service.AdminService, func()) {
+	db, closeDB := openDB(cfg)
+	return service.NewAdminService(
+		repository.NewQuestionRepository(db),
+		repository.NewAnswerRepository(db),
+		repository.NewStatsRepository(db),
+		repository.NewTxManager(db),
+		repository.NewOutboxRepository(db),
+		repository.NewAuditRepository(db),
+	), closeDB
+}
+
+func parseIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("укажите хотя бы один ID")
+	}
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			return nil, fmt.Errorf("некорректный ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// confirm спрашивает подтверждение в stderr, чтобы stdout оставался пригодным для JSON
+func confirm(yes bool, prompt string) error {
+	if yes {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%s [y/N]: ", prompt)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes", "д", "да":
+		return nil
+	}
+	return errAborted
+}
+
+func printMigrations(format string, migrations []entity.Migration) error {
+	if format == formatJSON {
+		return printJSON(migrations)
+	}
+	rows := make([][]string, len(migrations))
+	for i, migration := range migrations {
+		status, appliedAt := "pending", ""
+		if migration.Applied {
+			status = "applied"
+			appliedAt = migration.AppliedAt.Format(time.DateTime)
+		}
+		rows[i] = []string{strconv.FormatInt(migration.Version, 10), migration.Name, status, appliedAt}
+	}
+	return printTable([]string{"VERSION", "NAME", "STATUS", "APPLIED_AT"}, rows)
+}
+
+func printQuestions(format string, questions []entity.QuestionSummary) error {
+	if format == formatJSON {
+		return printJSON(questions)
+	}
+	rows := make([][]string, len(questions))
+	for i, question := range questions {
+		rows[i] = []string{
+			strconv.Itoa(question.ID),
+			strconv.Itoa(question.Answers),
+			question.CreatedAt.Format(time.DateTime),
+			shorten(question.Text, 60),
+		}
+	}
+	return printTable([]string{"ID", "ANSWERS", "CREATED_AT", "TEXT"}, rows)
+}
+
+func printAnswers(format string, answers []entity.Answer) error {
+	if format == formatJSON {
+		return printJSON(answers)
+	}
+	rows := make([][]string, len(answers))
+	for i, answer := range answers {
+		rows[i] = []string{
+			strconv.Itoa(answer.ID),
+			strconv.Itoa(answer.QuestionID),
+			answer.UserID,
+			answer.CreatedAt.Format(time.DateTime),
+			shorten(answer.Text, 60),
+		}
+	}
+	return printTable([]string{"ID", "QUESTION_ID", "USER_ID", "CREATED_AT", "TEXT"}, rows)
+}
+
+func printJSON(v any) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func printTable(header []string, rows [][]string) error {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// shorten обрезает текст для таблицы по границе символа и убирает переводы строк
+func shorten(text string, limit int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	return string(runes[:limit-1]) + "…"
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,57 +12,328 @@ import (
 	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/andrey-samosuk/answer-questions/internal/api"
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
 	"github.com/andrey-samosuk/answer-questions/internal/config"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/graphqlapi"
+	"github.com/andrey-samosuk/answer-questions/internal/grpcapi"
+	"github.com/andrey-samosuk/answer-questions/internal/ratelimit"
 	"github.com/andrey-samosuk/answer-questions/internal/repository"
 	"github.com/andrey-samosuk/answer-questions/internal/service"
+	"github.com/andrey-samosuk/answer-questions/internal/tracing"
 )
 
+const usage = `Использование: app <команда> [флаги]
+
+Команды:
+  serve                       запустить HTTP сервер (по умолчанию)
+  migrate [status|up|down]    показать, применить или откатить миграции
+  questions list              список вопросов с числом ответов
+  questions delete ID...      удалить вопросы вместе с ответами
+  answers purge -user ID      удалить все ответы пользователя
+  stats                       сводка по данным
+  export                      выгрузить вопросы с ответами (jsonl или csv)
+  import                      загрузить вопросы с ответами из файла или stdin
+
+Команды, которые удаляют данные, поддерживают -dry-run и спрашивают подтверждение (-yes, чтобы не спрашивать).
+Вывод таблицей или JSON: -format table|json. Справка по флагам: app <команда> -h
+`
+
 func main() {
 	cfg := config.Load()
 
+	command, args := "serve", []string(nil)
+	if len(os.Args) > 1 {
+		command, args = os.Args[1], os.Args[2:]
+	}
+
+	var err error
+	switch command {
+	case "serve":
+		serve(cfg)
+	case "migrate":
+		err = runMigrate(cfg, args)
+	case "questions":
+		err = runQuestions(cfg, args)
+	case "answers":
+		err = runAnswers(cfg, args)
+	case "stats":
+		err = runStats(cfg, args)
+	case "export":
+		err = runExport(cfg, args)
+	case "import":
+		err = runImport(cfg, args)
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Неизвестная команда %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("Ошибка команды %s: %v", command, err)
+	}
+}
+
+// openDB подключается к БД; возвращённая функция закрывает пул соединений
+func openDB(cfg *config.Config) (*gorm.DB, func()) {
 	db, err := gorm.Open(postgres.Open(cfg.Database.DSN()), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Ошибка подключения к БД: %v", err)
 	}
 
+	if err := repository.RegisterTracing(db); err != nil {
+		log.Fatalf("Ошибка регистрации трассировки GORM: %v", err)
+	}
+
 	sqlDB, err := db.DB()
 	if err != nil {
 		log.Fatalf("Ошибка получения SQL DB: %v", err)
 	}
-	defer func() {
+	return db, func() {
 		if err := sqlDB.Close(); err != nil {
 			log.Printf("Ошибка при закрытии БД: %v", err)
 		}
+	}
+}
+
+func serve(cfg *config.Config) {
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Ошибка инициализации трассировки: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			log.Printf("Ошибка при остановке трассировки: %v", err)
+		}
 	}()
 
+	db, closeDB := openDB(cfg)
+	defer closeDB()
+
 	questionRepo := repository.NewQuestionRepository(db)
 	answerRepo := repository.NewAnswerRepository(db)
+	healthRepo := repository.NewHealthRepository(db, cfg.Health.MigrationsDir)
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	outboxRepo := repository.NewOutboxRepository(db)
+	jobRepo := repository.NewJobRepository(db)
+	auditRepo := repository.NewAuditRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	flagRepo := repository.NewFlagRepository(db)
+	commentRepo := repository.NewCommentRepository(db)
+	txManager := repository.NewTxManager(db)
+
+	broker := events.NewBroker(cfg.Events.ReplayBufferSize)
+	var publisher events.Publisher = broker
+	var notifier *events.PGNotifier
+	if cfg.Events.Backend == "postgres" {
+		notifier = events.NewPGNotifier(db, cfg.Database.DSN(), broker, service.OutboxEventLoader(outboxRepo))
+		publisher = notifier
+	}
+
+	webhookService := service.NewWebhookService(webhookRepo, service.NewWebhookClient(), service.WebhookDeliveryPolicy{
+		MaxAttempts: cfg.Webhooks.MaxAttempts,
+		BackoffBase: time.Duration(cfg.Webhooks.BackoffBaseSeconds) * time.Second,
+		BackoffMax:  time.Duration(cfg.Webhooks.BackoffMaxSeconds) * time.Second,
+		Timeout:     time.Duration(cfg.Webhooks.TimeoutSeconds) * time.Second,
+		BatchSize:   cfg.Webhooks.BatchSize,
+	})
+
+	// сервисы пишут события в outbox, relay доставляет их в sink после коммита
+	sinks := []service.OutboxSink{{Name: "events", Publisher: publisher}, {Name: "webhooks", Publisher: webhookService}}
+	if cfg.Outbox.FilePath != "" {
+		fileSink, err := events.NewFileSink(cfg.Outbox.FilePath)
+		if err != nil {
+			log.Fatalf("Ошибка открытия файла событий: %v", err)
+		}
+		defer func() {
+			if err := fileSink.Close(); err != nil {
+				log.Printf("Ошибка при закрытии файла событий: %v", err)
+			}
+		}()
+		sinks = append(sinks, service.OutboxSink{Name: "file", Publisher: fileSink})
+	}
+	outboxRelay := service.NewOutboxRelay(outboxRepo, txManager, sinks, service.OutboxRelayPolicy{
+		BatchSize:   cfg.Outbox.BatchSize,
+		MaxAttempts: cfg.Outbox.MaxAttempts,
+	})
+
+	moderator := newModerator(cfg)
+	questionService := service.NewQuestionService(questionRepo, txManager, outboxRepo, auditRepo, moderator)
+	answerService := service.NewAnswerService(answerRepo, questionRepo, txManager, outboxRepo, auditRepo, moderator)
+	// invalidator сбрасывает кэш после модерации и жалоб, которые меняют видимость в обход кэширующих сервисов
+	var invalidator service.CacheInvalidator
+	if cfg.Cache.Enabled {
+		servicesCache := cache.NewInstrumented(cache.NewLRU(cfg.Cache.Size), "services")
+		questionService, answerService = service.NewCachedServices(
+			questionService,
+			answerService,
+			servicesCache,
+			time.Duration(cfg.Cache.TTLSeconds)*time.Second,
+			time.Duration(cfg.Server.RequestTimeout)*time.Second,
+		)
+		invalidator = service.NewCacheInvalidator(servicesCache)
+	}
+	// видимость проверяется поверх кэша: вопрос, ожидающий модерации, видит только его автор
+	questionService, answerService = service.NewVisibleServices(questionService, answerService)
+	healthService := service.NewHealthService(healthRepo, time.Duration(cfg.Health.CheckTimeout)*time.Second)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, time.Duration(cfg.Idempotency.TTLHours)*time.Hour,
+		time.Duration(cfg.Server.RequestTimeout)*time.Second)
 
-	questionService := service.NewQuestionService(questionRepo)
-	answerService := service.NewAnswerService(answerRepo, questionRepo)
+	commentService := service.NewCommentService(commentRepo, questionRepo, answerRepo, txManager, outboxRepo, auditRepo, moderator)
+	handler := api.NewHandler(questionService, answerService, commentService, cfg.Server.RequestTimeout)
 
-	handler := api.NewHandler(questionService, answerService, cfg.Server.RequestTimeout)
+	healthHandler := api.NewHealthHandler(healthService)
+	idempotency := api.NewIdempotency(idempotencyService)
+	eventsHandler := api.NewEventsHandler(broker, questionService, time.Duration(cfg.Events.HeartbeatSeconds)*time.Second, cfg.Server.RequestTimeout)
+	wsHandler := api.NewWebSocketHandler(broker, questionService, cfg.Auth.WSAllowedOrigins, cfg.Server.RequestTimeout)
+	webhookHandler := api.NewWebhookHandler(webhookService, cfg.Server.RequestTimeout)
+	transferHandler := api.NewTransferHandler(
+		service.NewTransferService(questionRepo, answerRepo, txManager, outboxRepo, auditRepo, moderator, invalidator),
+		cfg.Admin.Users,
+		int64(cfg.Transfer.MaxImportMB)<<20,
+	)
 
-	router := api.NewRouter(handler)
+	bulkService := service.NewBulkService(answerRepo, jobRepo, txManager, outboxRepo, auditRepo, invalidator, service.BulkPolicy{
+		SyncLimit: cfg.Admin.BulkSyncLimit,
+		BatchSize: cfg.Admin.BulkBatchSize,
+	})
+
+	graphqlExecutor, err := graphqlapi.NewExecutor(questionService, answerService, graphqlapi.Limits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	})
+	if err != nil {
+		log.Fatalf("Ошибка построения GraphQL схемы: %v", err)
+	}
+
+	router := api.NewRouter(api.Handlers{
+		API:         handler,
+		Health:      healthHandler,
+		Idempotency: idempotency,
+		Events:      eventsHandler,
+		WebSocket:   wsHandler,
+		Webhooks:    webhookHandler,
+		Transfer:    transferHandler,
+		GraphQL:     api.NewGraphQLHandler(graphqlExecutor, cfg.Server.RequestTimeout),
+		Admin:       api.NewAdminHandler(bulkService, service.NewAuditService(auditRepo), cfg.Admin.Users, cfg.Server.RequestTimeout),
+		Privacy: api.NewPrivacyHandler(
+			service.NewPrivacyService(answerRepo, questionRepo, webhookRepo, notificationRepo, flagRepo, commentRepo, txManager, outboxRepo, auditRepo, invalidator),
+			cfg.Admin.Users,
+			cfg.Server.RequestTimeout,
+		),
+		Moderation: api.NewModerationHandler(
+			service.NewModerationService(questionRepo, answerRepo, notificationRepo, txManager, outboxRepo, auditRepo, invalidator),
+			cfg.Admin.Users,
+			cfg.Server.RequestTimeout,
+		),
+		Notifications: api.NewNotificationHandler(service.NewNotificationService(notificationRepo), cfg.Server.RequestTimeout),
+		Flags: api.NewFlagHandler(
+			service.NewFlagService(flagRepo, questionRepo, answerRepo, notificationRepo, txManager, outboxRepo, auditRepo, invalidator,
+				cfg.Moderation.FlagHideThreshold),
+			cfg.Admin.Users,
+			cfg.Server.RequestTimeout,
+		),
+		Comments: api.NewCommentHandler(commentService, cfg.Admin.Users, cfg.Server.RequestTimeout),
+	})
 	mux := router.Setup()
 
+	rateLimiter, err := api.NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		api.RouteClassRead: {
+			Requests: cfg.RateLimit.ReadPerMinute,
+			Period:   time.Minute,
+			Burst:    cfg.RateLimit.ReadBurst,
+		},
+		api.RouteClassWrite: {
+			Requests: cfg.RateLimit.WritePerMinute,
+			Period:   time.Minute,
+			Burst:    cfg.RateLimit.WriteBurst,
+		},
+	}, cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Ошибка настройки rate limit: %v", err)
+	}
+	auditMiddleware, err := api.AuditMiddleware(cfg.RateLimit.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Ошибка настройки аудита: %v", err)
+	}
+	authService := service.NewStaticTokenAuthService(cfg.Auth.Tokens)
+
 	server := &http.Server{
-		Addr:         ":" + cfg.Server.HTTPPort,
-		Handler:      mux,
+		Addr: ":" + cfg.Server.HTTPPort,
+		Handler: api.Chain(mux,
+			api.RecoverMiddleware,
+			api.TracingMiddleware,
+			api.LogMiddleware,
+			api.CORSMiddleware,
+			api.AuthMiddleware(authService),
+			auditMiddleware,
+			rateLimiter.Middleware,
+		),
 		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout) * time.Second,
 		WriteTimeout: time.Duration(cfg.Server.WriteTimeout) * time.Second,
 		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout) * time.Second,
 	}
+	server.RegisterOnShutdown(eventsHandler.Close)
+	server.RegisterOnShutdown(wsHandler.Close)
+
+	var grpcServer *grpc.Server
+	var grpcListener net.Listener
+	if cfg.Server.GRPCPort != "" {
+		grpcListener, err = net.Listen("tcp", ":"+cfg.Server.GRPCPort)
+		if err != nil {
+			log.Fatalf("Ошибка открытия порта gRPC: %v", err)
+		}
+		grpcServer = grpcapi.NewGRPCServer(grpcapi.NewServer(questionService, answerService, cfg.Server.RequestTimeout), authService)
+	}
 
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	var wg sync.WaitGroup
 
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		purgeIdempotencyKeys(workersCtx, idempotencyService, time.Duration(cfg.Idempotency.CleanupIntervalHours)*time.Hour)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		relayOutbox(workersCtx, outboxRelay, time.Duration(cfg.Outbox.PollIntervalMs)*time.Millisecond, time.Duration(cfg.Outbox.RetentionHours)*time.Hour)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		deliverWebhooks(workersCtx, webhookService, time.Duration(cfg.Webhooks.PollIntervalSeconds)*time.Second)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		runJobs(workersCtx, bulkService, time.Duration(cfg.Admin.JobsPollIntervalSeconds)*time.Second)
+	}()
+
+	if notifier != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			notifier.Listen(workersCtx)
+		}()
+	}
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
@@ -70,16 +343,177 @@ func main() {
 		}
 	}()
 
+	if grpcServer != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			log.Printf("Запуск gRPC сервера на порту %s", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(grpcListener); err != nil {
+				log.Printf("Ошибка gRPC сервера: %v", err)
+			}
+		}()
+	}
+
 	<-sigChan
 	log.Println("Получен сигнал завершения, останавливаем приложение...")
 
+	// readyz начинает отвечать 503, балансировщик успевает вывести инстанс из ротации
+	healthService.StartDraining()
+	time.Sleep(time.Duration(cfg.Server.DrainDelay) * time.Second)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(cfg.Server.ShutdownTimeout)*time.Second)
 	defer cancel()
 
+	// HTTP и gRPC серверы дожидаются текущих запросов одновременно, в пределах общего таймаута
+	grpcStopped := make(chan struct{})
+	go func() {
+		defer close(grpcStopped)
+		if grpcServer != nil {
+			stopGRPCServer(ctx, grpcServer)
+		}
+	}()
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("Ошибка при остановке сервера: %v", err)
 	}
+	<-grpcStopped
+	stopWorkers()
 
 	wg.Wait()
 	log.Println("Приложение остановлено")
 }
+
+// stopGRPCServer ждёт завершения текущих вызовов; по истечении ctx обрывает их
+func stopGRPCServer(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Printf("gRPC сервер не остановился за отведённое время, обрываем вызовы")
+		grpcServer.Stop()
+		<-stopped
+	}
+}
+
+// purgeIdempotencyKeys периодически удаляет просроченные Idempotency-Key
+func purgeIdempotencyKeys(ctx context.Context, idempotencyService service.IdempotencyService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := idempotencyService.PurgeExpired(ctx)
+			if err != nil {
+				log.Printf("Ошибка очистки Idempotency-Key: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Удалено просроченных Idempotency-Key: %d", deleted)
+			}
+		}
+	}
+}
+
+// deliverWebhooks периодически отправляет доставки вебхуков, чьё время подошло.
+// Пока очередь не разобрана, следующая пачка берётся без ожидания
+func deliverWebhooks(ctx context.Context, webhookService service.WebhookService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				processed, err := webhookService.DeliverDue(ctx)
+				if err != nil {
+					log.Printf("Ошибка доставки вебхуков: %v", err)
+					break
+				}
+				if processed == 0 {
+					break
+				}
+			}
+		}
+	}
+}
+
+// runJobs периодически выполняет фоновые административные задачи, пока они есть.
+// При остановке задача прерывается между пачками и продолжается после истечения аренды
+func runJobs(ctx context.Context, bulkService service.BulkService, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				ran, err := bulkService.RunNextJob(ctx)
+				if err != nil {
+					log.Printf("Ошибка выполнения фоновой задачи: %v", err)
+					break
+				}
+				if !ran {
+					break
+				}
+			}
+		}
+	}
+}
+
+// relayOutbox публикует события из outbox и раз в час удаляет опубликованные старше retention
+func relayOutbox(ctx context.Context, relay service.OutboxRelay, interval, retention time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	purge := time.NewTicker(time.Hour)
+	defer purge.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for ctx.Err() == nil {
+				published, err := relay.Relay(ctx)
+				if err != nil {
+					log.Printf("Ошибка публикации событий из outbox: %v", err)
+					break
+				}
+				if published == 0 {
+					break
+				}
+			}
+		case <-purge.C:
+			deleted, err := relay.PurgePublished(ctx, retention)
+			if err != nil {
+				log.Printf("Ошибка очистки outbox: %v", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Printf("Удалено опубликованных событий из outbox: %d", deleted)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/andrey-samosuk/answer-questions/internal/config"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// runExport: app export [-format jsonl|csv] [-output file]
+func runExport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", service.TransferFormatJSONL, "формат выгрузки: jsonl или csv")
+	output := flags.String("output", "-", "файл для выгрузки, - для stdout")
+	_ = flags.Parse(args)
+
+	var out io.Writer = os.Stdout
+	if *output != "-" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	transferService, closeDB := newTransferService(cfg)
+	defer closeDB()
+
+	return transferService.Export(ctx, *format, out)
+}
+
+// runImport: app import [-format jsonl|csv] [-dry-run] [-upsert] [-batch-size N] [file]
+// Отчёт печатается в stdout как JSON; при ошибках в записях код выхода 1
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", service.TransferFormatJSONL, "формат файла: jsonl или csv")
+	dryRun := flags.Bool("dry-run", false, "проверить импорт и откатить изменения")
+	upsert := flags.Bool("upsert", false, "сопоставлять вопросы по тексту и добавлять только новые ответы")
+	batchSize := flags.Int("batch-size", 500, "записей в одной транзакции")
+	_ = flags.Parse(args)
+
+	var in io.Reader = os.Stdin
+	if path := flags.Arg(0); path != "" && path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	ctx, stop := commandContext()
+	defer stop()
+
+	transferService, closeDB := newTransferService(cfg)
+	defer closeDB()
+
+	report, err := transferService.Import(ctx, *format, in, service.ImportOptions{
+		DryRun:    *dryRun,
+		Upsert:    *upsert,
+		BatchSize: *batchSize,
+	})
+	if report != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		_ = encoder.Encode(report)
+	}
+	if err != nil {
+		return err
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("записей с ошибками: %d", report.Failed)
+	}
+	return nil
+}
+
+func newTransferService(cfg *config.Config) (service.TransferService, func()) {
+	db, closeDB := openDB(cfg)
+	return service.NewTransferService(
+		repository.NewQuestionRepository(db),
+		repository.NewAnswerRepository(db),
+		repository.NewTxManager(db),
+		repository.NewOutboxRepository(db),
+		repository.NewAuditRepository(db),
+		newModerator(cfg),
+		// кэш живёт в процессе сервера, CLI его не видит: импорт появится в кэшированных ответах через CACHE_TTL_SECONDS
+		nil,
+	), closeDB
+}
+
+func newModerator(cfg *config.Config) *moderation.Chain {
+	return moderation.NewDefaultChain(moderation.Policy{
+		BannedWords:      cfg.Moderation.BannedWords,
+		MaxLinks:         cfg.Moderation.MaxLinks,
+		MaxRepeatedChars: cfg.Moderation.MaxRepeatedChars,
+	})
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// AdminHandler административные маршруты /admin; доступны только пользователям из ADMIN_USERS
+type AdminHandler struct {
+	bulkService    service.BulkService
+	auditService   service.AuditService
+	admins         map[string]bool
+	requestTimeout int
+}
+
+func NewAdminHandler(bulkService service.BulkService, auditService service.AuditService, admins []string, requestTimeout int) *AdminHandler {
+	return &AdminHandler{
+		bulkService:    bulkService,
+		auditService:   auditService,
+		admins:         newUserSet(admins),
+		requestTimeout: requestTimeout,
+	}
+}
+
+// BulkDeleteAnswers удаляет ответы по ID и фильтрам. Небольшая выборка удаляется сразу (200),
+// для большой создаётся задача (202 и Location на GET /admin/jobs/{id}); dry_run только считает ответы
+func (h *AdminHandler) BulkDeleteAnswers(w http.ResponseWriter, r *http.Request) {
+	adminID, ok := requireAdmin(w, r, h.admins)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	var req BulkDeleteAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	filter := entity.AnswerFilter{
+		IDs:         req.IDs,
+		UserID:      req.UserID,
+		QuestionID:  req.QuestionID,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+	}
+	result, err := h.bulkService.DeleteAnswers(ctx, adminID, filter, req.DryRun)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при массовом удалении ответов")
+		return
+	}
+
+	response := BulkDeleteAnswersResponse{
+		Matched: result.Matched,
+		Deleted: result.Deleted,
+		DryRun:  result.DryRun,
+		JobID:   result.JobID,
+	}
+	if result.JobID != 0 {
+		w.Header().Set("Location", "/admin/jobs/"+strconv.FormatInt(result.JobID, 10))
+		sendJSON(w, http.StatusAccepted, response)
+		return
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+func (h *AdminHandler) GetJob(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	job, err := h.bulkService.GetJob(ctx, id)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении задачи")
+		return
+	}
+	sendJSON(w, http.StatusOK, JobResponse{
+		ID:         job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Total:      job.Total,
+		Processed:  job.Processed,
+		Error:      job.Error,
+		CreatedBy:  job.CreatedBy,
+		CreatedAt:  job.CreatedAt,
+		UpdatedAt:  job.UpdatedAt,
+		FinishedAt: job.FinishedAt,
+	})
+}
+
+// ListAudit отдаёт журнал аудита, новые записи первыми. Фильтры: actor, action, entity_type,
+// entity_id, from и to (RFC 3339); следующая страница — ?before=next_before
+func (h *AdminHandler) ListAudit(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _, ok := parsePage(w, query.Get("limit"), "")
+	if !ok {
+		return
+	}
+	var before int64
+	if value := query.Get("before"); value != "" {
+		var err error
+		before, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || before < 1 {
+			sendError(w, http.StatusBadRequest, "Параметр before должен быть положительным числом")
+			return
+		}
+	}
+	filter := entity.AuditFilter{
+		Actor:      query.Get("actor"),
+		Action:     query.Get("action"),
+		EntityType: query.Get("entity_type"),
+	}
+	if value := query.Get("entity_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+			return
+		}
+		filter.EntityID = id
+	}
+	for name, target := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Параметры from и to должны быть в формате RFC 3339")
+			return
+		}
+		*target = &parsed
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	events, err := h.auditService.ListEvents(ctx, filter, before, limit+1)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении журнала аудита")
+		return
+	}
+
+	response := AuditEventsResponse{Events: events}
+	if len(events) > limit {
+		response.Events = events[:limit]
+		response.NextBefore = &events[limit-1].ID
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+func newUserSet(userIDs []string) map[string]bool {
+	set := make(map[string]bool, len(userIDs))
+	for _, userID := range userIDs {
+		set[userID] = true
+	}
+	return set
+}
+
+// requireAdmin отвечает 401 без токена и 403, если пользователь не администратор
+func requireAdmin(w http.ResponseWriter, r *http.Request, admins map[string]bool) (string, bool) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return "", false
+	}
+	if !admins[userID] {
+		sendError(w, entity.ErrForbidden.Code, entity.ErrForbidden.Message)
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockBulkService struct {
+	service.BulkService
+	filter entity.AnswerFilter
+	jobID  int64
+}
+
+func (m *mockBulkService) DeleteAnswers(ctx context.Context, requestedBy string, filter entity.AnswerFilter, dryRun bool) (*entity.BulkDeleteResult, error) {
+	m.filter = filter
+	if err := service.ValidateAnswerFilter(filter); err != nil {
+		return nil, err
+	}
+	return &entity.BulkDeleteResult{Matched: 5000, DryRun: dryRun, JobID: m.jobID}, nil
+}
+
+func (m *mockBulkService) GetJob(ctx context.Context, id int64) (*entity.Job, error) {
+	if id != m.jobID {
+		return nil, entity.ErrJobNotFound
+	}
+	return &entity.Job{ID: id, Status: entity.JobRunning, Total: 5000, Processed: 1500, CreatedBy: "admin"}, nil
+}
+
+func TestAdminHandler_BulkDeleteAnswers(t *testing.T) {
+	mock := &mockBulkService{jobID: 7}
+	handler := NewAdminHandler(mock, nil, []string{"admin"}, 5)
+
+	tests := []struct {
+		name   string
+		userID string
+		body   string
+		status int
+	}{
+		{"anonymous", "", `{"user_id":"spammer"}`, http.StatusUnauthorized},
+		{"not an admin", "alice", `{"user_id":"spammer"}`, http.StatusForbidden},
+		{"empty filter", "admin", `{}`, http.StatusBadRequest},
+		{"async job", "admin", `{"user_id":"spammer","created_from":"2025-12-01T00:00:00Z"}`, http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/admin/answers/bulk-delete", strings.NewReader(tt.body))
+			if tt.userID != "" {
+				req = req.WithContext(WithUserID(req.Context(), tt.userID))
+			}
+			w := httptest.NewRecorder()
+			handler.BulkDeleteAnswers(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusAccepted && w.Header().Get("Location") != "/admin/jobs/7" {
+				t.Errorf("expected Location /admin/jobs/7, got %q", w.Header().Get("Location"))
+			}
+		})
+	}
+
+	if mock.filter.UserID != "spammer" || mock.filter.CreatedFrom == nil {
+		t.Errorf("expected filter by user and time, got %+v", mock.filter)
+	}
+}
+
+func TestAdminHandler_GetJob(t *testing.T) {
+	handler := NewAdminHandler(&mockBulkService{jobID: 7}, nil, []string{"admin"}, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/jobs/7", nil)
+	req.SetPathValue("id", "7")
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w := httptest.NewRecorder()
+	handler.GetJob(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response JobResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Status != entity.JobRunning || response.Processed != 1500 || response.Total != 5000 {
+		t.Errorf("expected running job with progress 1500/5000, got %+v", response)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/jobs/8", nil)
+	req.SetPathValue("id", "8")
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w = httptest.NewRecorder()
+	handler.GetJob(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+type mockAuditService struct {
+	filter   entity.AuditFilter
+	beforeID int64
+	limit    int
+}
+
+func (m *mockAuditService) ListEvents(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error) {
+	m.filter, m.beforeID, m.limit = filter, beforeID, limit
+	events := make([]entity.AuditEvent, 0, limit)
+	for id := int64(100); id > 100-int64(limit); id-- {
+		events = append(events, entity.AuditEvent{ID: id, Actor: filter.Actor})
+	}
+	return events, nil
+}
+
+func TestAdminHandler_ListAudit(t *testing.T) {
+	mock := &mockAuditService{}
+	handler := NewAdminHandler(nil, mock, []string{"admin"}, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/audit?actor=alice&entity_type=answer&entity_id=3&from=2025-12-01T00:00:00Z&before=200&limit=2", nil)
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w := httptest.NewRecorder()
+	handler.ListAudit(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+	var response AuditEventsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Events) != 2 || response.NextBefore == nil || *response.NextBefore != 99 {
+		t.Errorf("expected 2 events and next_before 99, got %+v", response)
+	}
+	if mock.filter.Actor != "alice" || mock.filter.EntityID != 3 || mock.filter.From == nil || mock.beforeID != 200 || mock.limit != 3 {
+		t.Errorf("unexpected query: filter %+v, before %d, limit %d", mock.filter, mock.beforeID, mock.limit)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/audit?from=yesterday", nil)
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w = httptest.NewRecorder()
+	handler.ListAudit(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
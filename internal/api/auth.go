@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "user_id"
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+func UserIDFromContext(ctx context.Context) (string, bool) {
+	userID, ok := ctx.Value(userIDContextKey).(string)
+	return userID, ok && userID != ""
+}
+
+// AuthMiddleware проверяет токен из Authorization: Bearer и кладёт пользователя в контекст.
+// Запросы без токена проходят анонимно, обработчики сами решают, нужна ли авторизация.
+// Для WebSocket токен можно передать в access_token: браузер не умеет задавать заголовки при handshake
+func AuthMiddleware(authService service.AuthService) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := bearerToken(r)
+			if token == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			userID, err := authService.Authenticate(r.Context(), token)
+			if err != nil {
+				sendCustomError(w, err, "Ошибка авторизации")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUserID(r.Context(), userID)))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return r.URL.Query().Get("access_token")
+	}
+	return ""
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+const (
+	includeAnswerCount  = "answer_count"
+	includeLatestAnswer = "latest_answer"
+)
+
+// questionIncludes разобранный параметр ?include=answer_count,latest_answer
+type questionIncludes struct {
+	answerCount  bool
+	latestAnswer bool
+}
+
+func (inc questionIncludes) any() bool {
+	return inc.answerCount || inc.latestAnswer
+}
+
+func parseIncludes(w http.ResponseWriter, value string) (questionIncludes, bool) {
+	var inc questionIncludes
+	if value == "" {
+		return inc, true
+	}
+	for _, name := range strings.Split(value, ",") {
+		switch strings.TrimSpace(name) {
+		case includeAnswerCount:
+			inc.answerCount = true
+		case includeLatestAnswer:
+			inc.latestAnswer = true
+		default:
+			sendError(w, http.StatusBadRequest, "Параметр include может содержать только answer_count и latest_answer")
+			return inc, false
+		}
+	}
+	return inc, true
+}
+
+// parseIDs разбирает список ID через запятую: ?ids=1,2,3
+func parseIDs(w http.ResponseWriter, value string) ([]int, bool) {
+	var ids []int
+	for _, part := range strings.Split(value, ",") {
+		id, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || id < 1 {
+			sendError(w, http.StatusBadRequest, "Параметр ids должен быть списком ID через запятую")
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// loadIncludes одним запросом получает сводку ответов, если include что-то запрашивает,
+// и вторым — число комментариев к самым новым ответам, если запрошен latest_answer
+func (h *Handler) loadIncludes(ctx context.Context, inc questionIncludes, questions []entity.Question) (map[int]entity.AnswerStats, map[int]int, error) {
+	if !inc.any() {
+		return nil, nil, nil
+	}
+	ids := make([]int, len(questions))
+	for i, q := range questions {
+		ids[i] = q.ID
+	}
+	stats, err := h.answerService.GetAnswerStats(ctx, ids)
+	if err != nil || !inc.latestAnswer {
+		return stats, nil, err
+	}
+	var latestIDs []int
+	for _, s := range stats {
+		if s.Latest != nil {
+			latestIDs = append(latestIDs, s.Latest.ID)
+		}
+	}
+	comments, err := h.commentCounts(ctx, latestIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stats, comments, nil
+}
+
+// commentCounts число комментариев к каждому ответу; nil, если комментарии не подключены
+func (h *Handler) commentCounts(ctx context.Context, answerIDs []int) (map[int]int, error) {
+	if h.commentService == nil || len(answerIDs) == 0 {
+		return nil, nil
+	}
+	return h.commentService.CountByAnswerIDs(ctx, answerIDs)
+}
+
+func answerIDs(answers []entity.Answer) []int {
+	ids := make([]int, len(answers))
+	for i, a := range answers {
+		ids[i] = a.ID
+	}
+	return ids
+}
+
+func (h *Handler) includesFor(inc questionIncludes, stats entity.AnswerStats, comments map[int]int, render bool) QuestionIncludes {
+	var result QuestionIncludes
+	if inc.answerCount {
+		count := stats.Count
+		result.AnswerCount = &count
+	}
+	if inc.latestAnswer && stats.Latest != nil {
+		latest := h.answerResponse(stats.Latest, comments[stats.Latest.ID], render)
+		result.LatestAnswer = &latest
+	}
+	return result
+}
+
+func (h *Handler) answerResponse(answer *entity.Answer, comments int, render bool) AnswerResponse {
+	response := AnswerResponse{
+		ID:           answer.ID,
+		QuestionID:   answer.QuestionID,
+		UserID:       answer.UserID,
+		Text:         answer.Text,
+		HTML:         h.answerHTML(answer, render),
+		CommentCount: comments,
+		CreatedAt:    answer.CreatedAt,
+	}
+	if !entity.Published(answer.ModerationStatus) {
+		response.ModerationStatus = answer.ModerationStatus
+	}
+	return response
+}
+
+// getQuestionsByIDs отвечает на GET /questions?ids=...: вопросы в порядке запроса и отдельно ID, которых нет
+func (h *Handler) getQuestionsByIDs(ctx context.Context, w http.ResponseWriter, r *http.Request, ids []int, inc questionIncludes, render bool) {
+	questions, missing, err := h.questionService.GetQuestionsByIDs(ctx, ids)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении вопросов")
+		return
+	}
+	stats, comments, err := h.loadIncludes(ctx, inc, questions)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении ответов")
+		return
+	}
+
+	// Last-Modified не отражает удалённые вопросы и ответы, поэтому список проверяется только по ETag
+	if writeNotModified(w, r, questionsListETag(questions, stats, comments), time.Time{}) {
+		return
+	}
+
+	responses := make([]QuestionResponse, len(questions))
+	for i, q := range questions {
+		responses[i] = QuestionResponse{
+			ID:               q.ID,
+			Text:             q.Text,
+			HTML:             h.questionHTML(&q, render),
+			CreatedAt:        q.CreatedAt,
+			QuestionIncludes: h.includesFor(inc, stats[q.ID], comments, render),
+		}
+		if !entity.Published(q.ModerationStatus) {
+			responses[i].ModerationStatus = q.ModerationStatus
+		}
+	}
+	sendJSON(w, http.StatusOK, QuestionsBatchResponse{Questions: responses, Missing: missing})
+}
+
+// BatchGetAnswers возвращает ответы по списку ID одним запросом. Ненайденные ID перечисляются в missing
+func (h *Handler) BatchGetAnswers(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	render, ok := parseRender(w, r.URL.Query().Get("render"))
+	if !ok {
+		return
+	}
+
+	var req BatchGetAnswersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+	if len(req.IDs) == 0 {
+		sendError(w, http.StatusBadRequest, "Поле ids не может быть пустым")
+		return
+	}
+
+	answers, missing, err := h.answerService.GetAnswersByIDs(ctx, req.IDs)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении ответов")
+		return
+	}
+	comments, err := h.commentCounts(ctx, answerIDs(answers))
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении комментариев")
+		return
+	}
+
+	responses := make([]AnswerResponse, len(answers))
+	for i := range answers {
+		responses[i] = h.answerResponse(&answers[i], comments[answers[i].ID], render)
+	}
+	sendJSON(w, http.StatusOK, AnswersBatchResponse{Answers: responses, Missing: missing})
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// CommentHandler комментарии к ответам и вопросам. Писать, менять и удалять комментарии может только
+// пользователь с токеном; чужой комментарий может удалить администратор из ADMIN_USERS
+type CommentHandler struct {
+	commentService service.CommentService
+	admins         map[string]bool
+	requestTimeout int
+}
+
+func NewCommentHandler(commentService service.CommentService, admins []string, requestTimeout int) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		admins:         newUserSet(admins),
+		requestTimeout: requestTimeout,
+	}
+}
+
+func (h *CommentHandler) CreateAnswerComment(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, entity.ModerationTypeAnswer)
+}
+
+func (h *CommentHandler) CreateQuestionComment(w http.ResponseWriter, r *http.Request) {
+	h.create(w, r, entity.ModerationTypeQuestion)
+}
+
+func (h *CommentHandler) GetAnswerComments(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, entity.ModerationTypeAnswer)
+}
+
+func (h *CommentHandler) GetQuestionComments(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, entity.ModerationTypeQuestion)
+}
+
+// create общая часть CreateAnswerComment и CreateQuestionComment: комментарий от пользователя из токена
+func (h *CommentHandler) create(w http.ResponseWriter, r *http.Request, itemType string) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	comment, err := h.commentService.CreateComment(ctx, userID, itemType, id, req.ParentID, req.Text)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при создании комментария")
+		return
+	}
+	sendJSON(w, http.StatusCreated, comment)
+}
+
+// list отдаёт комментарии к записи от старых к новым; следующая страница — ?after=next_after
+func (h *CommentHandler) list(w http.ResponseWriter, r *http.Request, itemType string) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+	query := r.URL.Query()
+	limit, after, ok := parsePage(w, query.Get("limit"), query.Get("after"))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	comments, err := h.commentService.GetComments(ctx, itemType, id, after, limit+1)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении комментариев")
+		return
+	}
+
+	response := CommentsResponse{Comments: comments}
+	if len(comments) > limit {
+		response.Comments = comments[:limit]
+		response.NextAfter = &comments[limit-1].ID
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+// Update меняет текст комментария; чужой комментарий менять нельзя
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	comment, err := h.commentService.UpdateComment(ctx, userID, id, req.Text)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при изменении комментария")
+		return
+	}
+	sendJSON(w, http.StatusOK, comment)
+}
+
+// Delete удаляет комментарий автора или, для администратора, любой комментарий
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	if err := h.commentService.DeleteComment(ctx, userID, id, h.admins[userID]); err != nil {
+		sendCustomError(w, err, "Ошибка при удалении комментария")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
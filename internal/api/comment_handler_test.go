@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockCommentService struct {
+	service.CommentService
+	comments []entity.Comment
+}
+
+func (m *mockCommentService) CreateComment(ctx context.Context, userID, itemType string, itemID int, parentID *int, text string) (*entity.Comment, error) {
+	if err := service.ValidateComment(userID, text); err != nil {
+		return nil, err
+	}
+	comment := entity.Comment{ID: len(m.comments) + 1, AnswerID: &itemID, ParentID: parentID, UserID: userID, Text: text}
+	m.comments = append(m.comments, comment)
+	return &comment, nil
+}
+
+func (m *mockCommentService) GetComments(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error) {
+	page := []entity.Comment{}
+	for _, c := range m.comments {
+		if c.ID > afterID && len(page) < limit {
+			page = append(page, c)
+		}
+	}
+	return page, nil
+}
+
+func (m *mockCommentService) CountByAnswerIDs(ctx context.Context, answerIDs []int) (map[int]int, error) {
+	counts := map[int]int{}
+	for _, c := range m.comments {
+		counts[*c.AnswerID]++
+	}
+	return counts, nil
+}
+
+func TestCommentHandler_CreateAndList(t *testing.T) {
+	comments := &mockCommentService{}
+	handler := NewCommentHandler(comments, nil, 5)
+
+	tests := []struct {
+		name   string
+		userID string
+		body   string
+		status int
+	}{
+		{"without token", "", `{"text":"Какая версия Go?"}`, http.StatusUnauthorized},
+		{"empty text", "alice", `{"text":" "}`, http.StatusBadRequest},
+		{"first comment", "alice", `{"text":"Какая версия Go?"}`, http.StatusCreated},
+		{"second comment", "bob", `{"text":"1.24"}`, http.StatusCreated},
+		{"reply", "carol", `{"parent_id":1,"text":"И какая ОС?"}`, http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/answers/7/comments", strings.NewReader(tt.body))
+			req.SetPathValue("id", "7")
+			if tt.userID != "" {
+				req = req.WithContext(WithUserID(req.Context(), tt.userID))
+			}
+			w := httptest.NewRecorder()
+			handler.CreateAnswerComment(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	if reply := comments.comments[2]; reply.ParentID == nil || *reply.ParentID != 1 {
+		t.Errorf("expected reply to comment 1, got %+v", reply)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/answers/7/comments?limit=1", nil)
+	req.SetPathValue("id", "7")
+	w := httptest.NewRecorder()
+	handler.GetAnswerComments(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response CommentsResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Comments) != 1 || response.Comments[0].UserID != "alice" || response.NextAfter == nil || *response.NextAfter != 1 {
+		t.Errorf("expected first comment and next_after 1, got %+v", response)
+	}
+}
+
+func TestGetAnswer_CommentCount(t *testing.T) {
+	comments := &mockCommentService{comments: []entity.Comment{
+		{ID: 1, AnswerID: intPtr(1), UserID: "alice", Text: "Какая версия Go?"},
+		{ID: 2, AnswerID: intPtr(1), UserID: "bob", Text: "1.24"},
+	}}
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			return &entity.Answer{ID: id, QuestionID: 1, UserID: "carol", Text: "Ответ", Version: 1}, nil
+		},
+	}
+	handler := NewHandler(&mockQuestionService{}, mockAService, comments, 5)
+
+	req := createTestRequest(http.MethodGet, "/answers/1", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	handler.GetAnswer(w, req)
+
+	var response map[string]interface{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response["comment_count"] != float64(2) {
+		t.Errorf("expected comment_count 2, got %v", response["comment_count"])
+	}
+	if etag := w.Header().Get("ETag"); etag != `"a1-v1-c2"` {
+		t.Errorf("expected ETag to include comment count, got %s", etag)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
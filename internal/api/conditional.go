@@ -0,0 +1,111 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// answerETag строится из ID и версии ответа и числа комментариев к нему: счётчик входит в представление,
+// а версию ответа комментарии не меняют. Без комментариев ETag тот же, что до их появления
+func answerETag(answer *entity.Answer, comments int) string {
+	if comments > 0 {
+		return fmt.Sprintf(`"a%d-v%d-c%d"`, answer.ID, answer.Version, comments)
+	}
+	return fmt.Sprintf(`"a%d-v%d"`, answer.ID, answer.Version)
+}
+
+// questionETag учитывает версию вопроса и его ответы с числом комментариев, так как они входят
+// в представление GetQuestion
+func questionETag(question *entity.Question, answers []entity.Answer, comments map[int]int) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "q%d-v%d", question.ID, question.Version)
+	for _, a := range answers {
+		fmt.Fprintf(hash, ";a%d-v%d", a.ID, a.Version)
+		if comments[a.ID] > 0 {
+			fmt.Fprintf(hash, "-c%d", comments[a.ID])
+		}
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+// questionsListETag меняется при добавлении, удалении и изменении любого вопроса, а если
+// запрошена сводка ответов (stats не nil) — и при изменении числа или самого нового ответа
+// и числа комментариев к нему
+func questionsListETag(questions []entity.Question, stats map[int]entity.AnswerStats, comments map[int]int) string {
+	hash := sha256.New()
+	for _, q := range questions {
+		fmt.Fprintf(hash, "q%d-v%d;", q.ID, q.Version)
+		if stats != nil {
+			s := stats[q.ID]
+			fmt.Fprintf(hash, "c%d;", s.Count)
+			if s.Latest != nil {
+				fmt.Fprintf(hash, "a%d-v%d-c%d;", s.Latest.ID, s.Latest.Version, comments[s.Latest.ID])
+			}
+		}
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil))[:32] + `"`
+}
+
+// etagMatches проверяет список ETag из If-Match/If-None-Match. weak включает слабое сравнение (для If-None-Match)
+func etagMatches(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		} else if strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeNotModified выставляет валидаторы и отвечает 304, если представление у клиента актуально.
+// If-Modified-Since учитывается, только если нет If-None-Match (RFC 9110)
+func writeNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		if !etagMatches(inm, etag, true) {
+			return false
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		if err != nil || lastModified.Truncate(time.Second).After(since) {
+			return false
+		}
+	} else {
+		return false
+	}
+
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// checkIfMatch отвечает 412, если If-Match передан и не совпадает с текущим ETag
+func checkIfMatch(w http.ResponseWriter, r *http.Request, etag string) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" || etagMatches(ifMatch, etag, false) {
+		return true
+	}
+	sendError(w, entity.ErrPreconditionFailed.Code, entity.ErrPreconditionFailed.Message)
+	return false
+}
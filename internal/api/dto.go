@@ -1,16 +1,31 @@
 package api
 
-import "time"
+import (
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
 
 type CreateQuestionRequest struct {
 	Text string `json:"text"`
 }
 
 type QuestionResponse struct {
-	ID        int              `json:"id"`
-	Text      string           `json:"text"`
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+	// HTML текст в Markdown, переведённый в очищенный HTML; заполнен только при ?render=html
+	HTML      string           `json:"html,omitempty"`
 	CreatedAt time.Time        `json:"created_at"`
 	Answers   []AnswerResponse `json:"answers,omitempty"`
+	// ModerationStatus заполнен, только если вопрос ждёт проверки или отклонён; такой вопрос видит только автор
+	ModerationStatus string `json:"moderation_status,omitempty"`
+	QuestionIncludes
+}
+
+// QuestionIncludes поля, запрошенные через ?include=answer_count,latest_answer
+type QuestionIncludes struct {
+	AnswerCount  *int            `json:"answer_count,omitempty"`
+	LatestAnswer *AnswerResponse `json:"latest_answer,omitempty"`
 }
 
 type QuestionsListResponse struct {
@@ -21,25 +36,207 @@ type QuestionsListResponse struct {
 type QuestionMinimalResponse struct {
 	ID   int    `json:"id"`
 	Text string `json:"text"`
+	HTML string `json:"html,omitempty"`
+	QuestionIncludes
 }
 
 type QuestionsMinimalListResponse struct {
 	Questions []QuestionMinimalResponse `json:"questions"`
+	// NextAfter значение after для следующей страницы; нет, если страница последняя
+	NextAfter *int `json:"next_after,omitempty"`
+}
+
+// QuestionsBatchResponse ответ GET /questions?ids=...; Missing — запрошенные ID, которых нет
+type QuestionsBatchResponse struct {
+	Questions []QuestionResponse `json:"questions"`
+	Missing   []int              `json:"missing"`
 }
 
+// CreateAnswerRequest UserID необязателен: автор — авторизованный пользователь
 type CreateAnswerRequest struct {
-	UserID string `json:"user_id"`
+	UserID string `json:"user_id,omitempty"`
 	Text   string `json:"text"`
 }
 
 type AnswerResponse struct {
-	ID         int       `json:"id"`
-	QuestionID int       `json:"question_id"`
-	UserID     string    `json:"user_id"`
-	Text       string    `json:"text"`
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int    `json:"id"`
+	QuestionID int    `json:"question_id"`
+	UserID     string `json:"user_id"`
+	Text       string `json:"text"`
+	HTML       string `json:"html,omitempty"`
+	// CommentCount число комментариев к ответу; сами комментарии — GET /answers/{id}/comments
+	CommentCount int       `json:"comment_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	// ModerationStatus заполнен, только если ответ ждёт проверки или отклонён; такой ответ видит только автор
+	ModerationStatus string `json:"moderation_status,omitempty"`
+}
+
+type BatchGetAnswersRequest struct {
+	IDs []int `json:"ids"`
+}
+
+// AnswersBatchResponse ответ POST /answers/batch-get; Missing — запрошенные ID, которых нет
+type AnswersBatchResponse struct {
+	Answers []AnswerResponse `json:"answers"`
+	Missing []int            `json:"missing"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+type ComponentHealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthResponse struct {
+	Status     string                             `json:"status"`
+	Components map[string]ComponentHealthResponse `json:"components"`
+}
+
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+type WebhookResponse struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+	Secret     string    `json:"secret,omitempty"`
+}
+
+type WebhooksListResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+type WebhookDeliveryResponse struct {
+	ID             int64      `json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type WebhookDeliveriesListResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// BulkDeleteAnswersRequest тело POST /admin/answers/bulk-delete; условия объединяются через AND
+type BulkDeleteAnswersRequest struct {
+	IDs         []int      `json:"ids"`
+	UserID      string     `json:"user_id"`
+	QuestionID  int        `json:"question_id"`
+	CreatedFrom *time.Time `json:"created_from"`
+	CreatedTo   *time.Time `json:"created_to"`
+	DryRun      bool       `json:"dry_run"`
+}
+
+// BulkDeleteAnswersResponse JobID есть, если удаление выполняется фоновой задачей
+type BulkDeleteAnswersResponse struct {
+	Matched int64 `json:"matched"`
+	Deleted int   `json:"deleted"`
+	DryRun  bool  `json:"dry_run"`
+	JobID   int64 `json:"job_id,omitempty"`
+}
+
+type JobResponse struct {
+	ID         int64      `json:"id"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	Total      int64      `json:"total"`
+	Processed  int64      `json:"processed"`
+	Error      string     `json:"error,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+type AuditEventsResponse struct {
+	Events []entity.AuditEvent `json:"events"`
+	// NextBefore значение before для следующей страницы; нет, если страница последняя
+	NextBefore *int64 `json:"next_before,omitempty"`
+}
+
+// EraseUserDataRequest тело POST /users/{id}/erase; mode — anonymize или delete
+type EraseUserDataRequest struct {
+	Mode string `json:"mode"`
+}
+
+type EraseUserDataResponse struct {
+	UserID        string `json:"user_id"`
+	Mode          string `json:"mode"`
+	Answers       int64  `json:"answers"`
+	Questions     int64  `json:"questions"`
+	Webhooks      int64  `json:"webhooks"`
+	Notifications int64  `json:"notifications"`
+	Flags         int64  `json:"flags"`
+	Comments      int64  `json:"comments"`
+}
+
+type ModerationQueueResponse struct {
+	Items []entity.ModerationItem `json:"items"`
+	// NextAfter значение after для следующей страницы; нет, если страница последняя
+	NextAfter *int `json:"next_after,omitempty"`
+}
+
+// ModerationDecisionRequest тело POST /admin/moderation/{type}/{id}/approve и /reject;
+// reason обязателен при отклонении и приходит автору в уведомлении
+type ModerationDecisionRequest struct {
+	Reason string `json:"reason"`
+}
+
+// CreateFlagRequest тело POST /questions/{id}/flags и /answers/{id}/flags;
+// reason — spam, offensive, off_topic или other, comment необязателен
+type CreateFlagRequest struct {
+	Reason  string `json:"reason"`
+	Comment string `json:"comment"`
+}
+
+type FlaggedItemsResponse struct {
+	Items []entity.FlagSummary `json:"items"`
+	// NextAfter значение after для следующей страницы; нет, если страница последняя
+	NextAfter *int `json:"next_after,omitempty"`
+}
+
+// ResolveFlagsRequest тело POST /admin/flags/{type}/{id}/resolve; action — dismiss или uphold,
+// comment при uphold становится причиной отклонения
+type ResolveFlagsRequest struct {
+	Action  string `json:"action"`
+	Comment string `json:"comment"`
+}
+
+type NotificationsResponse struct {
+	Notifications []entity.Notification `json:"notifications"`
+	// NextBefore значение before для следующей страницы; нет, если страница последняя
+	NextBefore *int64 `json:"next_before,omitempty"`
+}
+
+// CreateCommentRequest тело POST /answers/{id}/comments и /questions/{id}/comments
+type CreateCommentRequest struct {
+	// ParentID комментарий верхнего уровня к той же записи, на который отвечают
+	ParentID *int   `json:"parent_id,omitempty"`
+	Text     string `json:"text"`
+}
+
+// UpdateCommentRequest тело PATCH /comments/{id}
+type UpdateCommentRequest struct {
+	Text string `json:"text"`
+}
+
+type CommentsResponse struct {
+	Comments []entity.Comment `json:"comments"`
+	// NextAfter значение after для следующей страницы; нет, если страница последняя
+	NextAfter *int `json:"next_after,omitempty"`
 }
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	sseWriteTimeout       = 10 * time.Second
+	sseSubscriptionBuffer = 64
+	sseRetryMillis        = 3000
+)
+
+type EventsHandler struct {
+	broker            *events.Broker
+	questionService   service.QuestionService
+	heartbeatInterval time.Duration
+	requestTimeout    int
+	shutdown          chan struct{}
+	closeOnce         sync.Once
+}
+
+func NewEventsHandler(broker *events.Broker, questionService service.QuestionService, heartbeatInterval time.Duration, requestTimeout int) *EventsHandler {
+	return &EventsHandler{
+		broker:            broker,
+		questionService:   questionService,
+		heartbeatInterval: heartbeatInterval,
+		requestTimeout:    requestTimeout,
+		shutdown:          make(chan struct{}),
+	}
+}
+
+// Close завершает открытые потоки, иначе server.Shutdown ждал бы их до таймаута
+func (h *EventsHandler) Close() {
+	h.closeOnce.Do(func() { close(h.shutdown) })
+}
+
+// QuestionEvents отдаёт SSE поток событий answer.created/answer.deleted по вопросу
+func (h *EventsHandler) QuestionEvents(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	_, err = h.questionService.GetQuestion(ctx, questionID)
+	cancel()
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении вопроса")
+		return
+	}
+
+	sub, missed, resumed := h.broker.Subscribe(func(e events.Event) bool {
+		return e.QuestionID == questionID && strings.HasPrefix(e.Type, "answer.")
+	}, sseSubscriptionBuffer, r.Header.Get("Last-Event-ID"))
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	// поток живёт дольше WriteTimeout сервера, дедлайн выставляется на каждую запись
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		log.Printf("Ошибка сброса дедлайна записи SSE: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(format string, args ...any) bool {
+		if err := rc.SetWriteDeadline(time.Now().Add(sseWriteTimeout)); err != nil && err != http.ErrNotSupported {
+			return false
+		}
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send("retry: %d\n\n", sseRetryMillis) {
+		return
+	}
+	if !resumed {
+		// событие Last-Event-ID вытеснено из буфера, клиенту нужно перечитать вопрос целиком
+		if !send("event: resync\ndata: {}\n\n") {
+			return
+		}
+	}
+	for _, event := range missed {
+		if !send("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data) {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.shutdown:
+			return
+		case <-heartbeat.C:
+			if !send(": heartbeat\n\n") {
+				return
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				// клиент не успевал читать поток; он переподключится с Last-Event-ID
+				log.Printf("SSE подписчик вопроса %d отключён из-за переполнения буфера", questionID)
+				return
+			}
+			if !send("id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, event.Data) {
+				return
+			}
+		}
+	}
+}
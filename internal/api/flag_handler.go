@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// FlagHandler жалобы пользователей на вопросы и ответы; разбор жалоб /admin/flags доступен
+// только администраторам из ADMIN_USERS
+type FlagHandler struct {
+	flagService    service.FlagService
+	admins         map[string]bool
+	requestTimeout int
+}
+
+func NewFlagHandler(flagService service.FlagService, admins []string, requestTimeout int) *FlagHandler {
+	return &FlagHandler{
+		flagService:    flagService,
+		admins:         newUserSet(admins),
+		requestTimeout: requestTimeout,
+	}
+}
+
+func (h *FlagHandler) FlagQuestion(w http.ResponseWriter, r *http.Request) {
+	h.flag(w, r, entity.ModerationTypeQuestion)
+}
+
+func (h *FlagHandler) FlagAnswer(w http.ResponseWriter, r *http.Request) {
+	h.flag(w, r, entity.ModerationTypeAnswer)
+}
+
+// flag общая часть FlagQuestion и FlagAnswer: жалоба от пользователя из токена на запись из пути
+func (h *FlagHandler) flag(w http.ResponseWriter, r *http.Request, itemType string) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	var req CreateFlagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	flag, err := h.flagService.Flag(ctx, userID, itemType, id, req.Reason, req.Comment)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при создании жалобы")
+		return
+	}
+	sendJSON(w, http.StatusCreated, flag)
+}
+
+// ListFlagged отдаёт вопросы (?type=question) или ответы (?type=answer) с открытыми жалобами
+// в порядке ID; следующая страница — ?after=next_after
+func (h *FlagHandler) ListFlagged(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, after, ok := parsePage(w, query.Get("limit"), query.Get("after"))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	items, err := h.flagService.ListFlagged(ctx, query.Get("type"), after, limit+1)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении жалоб")
+		return
+	}
+
+	response := FlaggedItemsResponse{Items: items}
+	if len(items) > limit {
+		response.Items = items[:limit]
+		response.NextAfter = &items[limit-1].Item.ID
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+// Resolve закрывает открытые жалобы на вопрос или ответ из пути решением модератора
+func (h *FlagHandler) Resolve(w http.ResponseWriter, r *http.Request) {
+	moderatorID, ok := requireAdmin(w, r, h.admins)
+	if !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	var req ResolveFlagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	result, err := h.flagService.Resolve(ctx, moderatorID, r.PathValue("type"), id, req.Action, req.Comment)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при разборе жалоб")
+		return
+	}
+	sendJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockFlagService struct {
+	service.FlagService
+	flagged map[string]bool
+}
+
+func (m *mockFlagService) Flag(ctx context.Context, userID, itemType string, itemID int, reason, comment string) (*entity.Flag, error) {
+	if err := service.ValidateFlag(reason, comment); err != nil {
+		return nil, err
+	}
+	if m.flagged[userID] {
+		return nil, entity.ErrFlagAlreadyExists
+	}
+	m.flagged[userID] = true
+	return &entity.Flag{ID: 1, ItemType: itemType, ItemID: itemID, UserID: userID, Reason: reason, Status: entity.FlagOpen}, nil
+}
+
+func TestFlagHandler_FlagAnswer(t *testing.T) {
+	handler := NewFlagHandler(&mockFlagService{flagged: map[string]bool{}}, nil, 5)
+
+	tests := []struct {
+		name   string
+		userID string
+		body   string
+		status int
+	}{
+		{"without token", "", `{"reason":"spam"}`, http.StatusUnauthorized},
+		{"unknown reason", "alice", `{"reason":"boring"}`, http.StatusBadRequest},
+		{"first flag", "alice", `{"reason":"spam","comment":"реклама"}`, http.StatusCreated},
+		{"second flag", "alice", `{"reason":"other"}`, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/answers/7/flags", strings.NewReader(tt.body))
+			req.SetPathValue("id", "7")
+			if tt.userID != "" {
+				req = req.WithContext(WithUserID(req.Context(), tt.userID))
+			}
+			w := httptest.NewRecorder()
+			handler.FlagAnswer(w, req)
+			if w.Code != tt.status {
+				t.Errorf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/graphqlapi"
+)
+
+// maxGraphQLBodyBytes ограничивает размер тела запроса к /graphql
+const maxGraphQLBodyBytes = 1 << 20
+
+type GraphQLHandler struct {
+	executor       *graphqlapi.Executor
+	requestTimeout int
+}
+
+func NewGraphQLHandler(executor *graphqlapi.Executor, requestTimeout int) *GraphQLHandler {
+	return &GraphQLHandler{
+		executor:       executor,
+		requestTimeout: requestTimeout,
+	}
+}
+
+// Query выполняет GraphQL запрос. Ошибки запроса и резолверов отдаются с кодом 200
+// в поле errors ответа; 400 означает, что тело не удалось разобрать
+func (h *GraphQLHandler) Query(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	var req graphqlapi.Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxGraphQLBodyBytes)).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+	if req.Query == "" {
+		sendError(w, http.StatusBadRequest, "Поле query не может быть пустым")
+		return
+	}
+
+	sendJSON(w, http.StatusOK, h.executor.Execute(ctx, req))
+}
@@ -8,20 +8,39 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/markdown"
 	"github.com/andrey-samosuk/answer-questions/internal/service"
 )
 
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+
+	renderHTMLFormat = "html"
+
+	// renderCacheSize и renderCacheTTL кэш HTML вопросов и ответов: рендеринг Markdown заметно дороже чтения
+	renderCacheSize = 1000
+	renderCacheTTL  = time.Hour
+)
+
 type Handler struct {
 	questionService service.QuestionService
 	answerService   service.AnswerService
+	commentService  service.CommentService
 	requestTimeout  int
+	rendered        *cache.LRU
 }
 
-func NewHandler(questionService service.QuestionService, answerService service.AnswerService, requestTimeout int) *Handler {
+// NewHandler commentService nil — комментарии не подключены, comment_count у ответов всегда 0
+func NewHandler(questionService service.QuestionService, answerService service.AnswerService, commentService service.CommentService, requestTimeout int) *Handler {
 	return &Handler{
 		questionService: questionService,
 		answerService:   answerService,
+		commentService:  commentService,
 		requestTimeout:  requestTimeout,
+		rendered:        cache.NewLRU(renderCacheSize),
 	}
 }
 
@@ -29,29 +48,103 @@ func (h *Handler) GetQuestions(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
 	defer cancel()
 
-	questions, err := h.questionService.GetAllQuestions(ctx)
+	query := r.URL.Query()
+	inc, ok := parseIncludes(w, query.Get("include"))
+	if !ok {
+		return
+	}
+	render, ok := parseRender(w, query.Get("render"))
+	if !ok {
+		return
+	}
+	if query.Has("ids") {
+		ids, ok := parseIDs(w, query.Get("ids"))
+		if !ok {
+			return
+		}
+		h.getQuestionsByIDs(ctx, w, r, ids, inc, render)
+		return
+	}
+
+	var questions []entity.Question
+	var nextAfter *int
+	var err error
+	if query.Has("limit") || query.Has("after") {
+		limit, after, ok := parsePage(w, query.Get("limit"), query.Get("after"))
+		if !ok {
+			return
+		}
+		// лишний вопрос показывает, что за страницей есть ещё
+		questions, err = h.questionService.GetQuestionsPage(ctx, after, limit+1)
+		if err == nil && len(questions) > limit {
+			questions = questions[:limit]
+			nextAfter = &questions[limit-1].ID
+		}
+	} else {
+		questions, err = h.questionService.GetAllQuestions(ctx)
+	}
 	if err != nil {
 		sendCustomError(w, err, "Ошибка при получении вопросов")
 		return
 	}
+	stats, comments, err := h.loadIncludes(ctx, inc, questions)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении ответов")
+		return
+	}
+
+	// Last-Modified не отражает удалённые вопросы и ответы, поэтому список проверяется только по ETag
+	if writeNotModified(w, r, questionsListETag(questions, stats, comments), time.Time{}) {
+		return
+	}
 
 	minimalQuestions := make([]QuestionMinimalResponse, len(questions))
 	for i, q := range questions {
 		minimalQuestions[i] = QuestionMinimalResponse{
-			ID:   q.ID,
-			Text: q.Text,
+			ID:               q.ID,
+			Text:             q.Text,
+			HTML:             h.questionHTML(&q, render),
+			QuestionIncludes: h.includesFor(inc, stats[q.ID], comments, render),
 		}
 	}
 
 	sendJSON(w, http.StatusOK, QuestionsMinimalListResponse{
 		Questions: minimalQuestions,
+		NextAfter: nextAfter,
 	})
 }
 
+// parsePage разбирает параметры keyset-пагинации: limit по умолчанию defaultPageSize, after по умолчанию 0
+func parsePage(w http.ResponseWriter, limitStr, afterStr string) (limit, after int, ok bool) {
+	limit = defaultPageSize
+	if limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			sendError(w, http.StatusBadRequest, "Параметр limit должен быть числом от 1 до "+strconv.Itoa(maxPageSize))
+			return 0, 0, false
+		}
+	}
+	if afterStr != "" {
+		var err error
+		after, err = strconv.Atoi(afterStr)
+		if err != nil || after < 0 {
+			sendError(w, http.StatusBadRequest, "Параметр after должен быть неотрицательным числом")
+			return 0, 0, false
+		}
+	}
+	return limit, after, true
+}
+
 func (h *Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
 	defer cancel()
 
+	render, ok := parseRender(w, r.URL.Query().Get("render"))
+	if !ok {
+		return
+	}
+
 	var req CreateQuestionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("Ошибка парсинга JSON: %v", err)
@@ -65,11 +158,13 @@ func (h *Handler) CreateQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sendJSON(w, http.StatusCreated, map[string]interface{}{
+	w.Header().Set("ETag", questionETag(question, nil, nil))
+
+	sendJSON(w, http.StatusCreated, withModeration(withHTML(map[string]interface{}{
 		"id":         question.ID,
 		"text":       question.Text,
 		"created_at": question.CreatedAt,
-	})
+	}, h.questionHTML(question, render), render), question.ModerationStatus, question.ModerationReason))
 }
 
 func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
@@ -83,6 +178,10 @@ func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
 		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
 		return
 	}
+	render, ok := parseRender(w, r.URL.Query().Get("render"))
+	if !ok {
+		return
+	}
 
 	question, err := h.questionService.GetQuestion(ctx, id)
 	if err != nil {
@@ -95,23 +194,35 @@ func (h *Handler) GetQuestion(w http.ResponseWriter, r *http.Request) {
 		sendCustomError(w, err, "Ошибка при получении ответов")
 		return
 	}
+	comments, err := h.commentCounts(ctx, answerIDs(answers))
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении комментариев")
+		return
+	}
+
+	// без Last-Modified: представление меняется и при удалении ответа или новом комментарии,
+	// а время изменения вопроса и оставшихся ответов при этом не растёт. Кэши проверяют ETag
+	if writeNotModified(w, r, questionETag(question, answers, comments), time.Time{}) {
+		return
+	}
 
 	answerResponses := make([]map[string]interface{}, len(answers))
 	for i, a := range answers {
-		answerResponses[i] = map[string]interface{}{
-			"id":         a.ID,
-			"user_id":    a.UserID,
-			"text":       a.Text,
-			"created_at": a.CreatedAt,
-		}
+		answerResponses[i] = withHTML(map[string]interface{}{
+			"id":            a.ID,
+			"user_id":       a.UserID,
+			"text":          a.Text,
+			"comment_count": comments[a.ID],
+			"created_at":    a.CreatedAt,
+		}, h.answerHTML(&a, render), render)
 	}
 
-	sendJSON(w, http.StatusOK, map[string]interface{}{
+	sendJSON(w, http.StatusOK, withModeration(withHTML(map[string]interface{}{
 		"id":         question.ID,
 		"text":       question.Text,
 		"created_at": question.CreatedAt,
 		"answers":    answerResponses,
-	})
+	}, h.questionHTML(question, render), render), question.ModerationStatus, question.ModerationReason))
 }
 
 func (h *Handler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
@@ -126,7 +237,31 @@ func (h *Handler) DeleteQuestion(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.questionService.DeleteQuestion(ctx, id)
+	// версия, проверенная по If-Match, передаётся в удаление: вопрос могли изменить после проверки
+	version := 0
+	if r.Header.Get("If-Match") != "" {
+		question, err := h.questionService.GetQuestion(ctx, id)
+		if err != nil {
+			sendCustomError(w, err, "Ошибка при получении вопроса")
+			return
+		}
+		answers, err := h.answerService.GetAnswersByQuestion(ctx, id)
+		if err != nil {
+			sendCustomError(w, err, "Ошибка при получении ответов")
+			return
+		}
+		comments, err := h.commentCounts(ctx, answerIDs(answers))
+		if err != nil {
+			sendCustomError(w, err, "Ошибка при получении комментариев")
+			return
+		}
+		if !checkIfMatch(w, r, questionETag(question, answers, comments)) {
+			return
+		}
+		version = question.Version
+	}
+
+	err = h.questionService.DeleteQuestion(ctx, id, version)
 	if err != nil {
 		sendCustomError(w, err, "Ошибка при удалении вопроса")
 		return
@@ -147,6 +282,15 @@ func (h *Handler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	render, ok := parseRender(w, r.URL.Query().Get("render"))
+	if !ok {
+		return
+	}
+	// автор берётся из токена, user_id в теле необязателен и проверяется сервисом
+	if _, ok := requireUser(w, r); !ok {
+		return
+	}
+
 	var req CreateAnswerRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		log.Printf("Ошибка парсинга JSON: %v", err)
@@ -160,13 +304,16 @@ func (h *Handler) CreateAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	sendJSON(w, http.StatusCreated, map[string]interface{}{
-		"id":          answer.ID,
-		"question_id": answer.QuestionID,
-		"user_id":     answer.UserID,
-		"text":        answer.Text,
-		"created_at":  answer.CreatedAt,
-	})
+	w.Header().Set("ETag", answerETag(answer, 0))
+
+	sendJSON(w, http.StatusCreated, withModeration(withHTML(map[string]interface{}{
+		"id":            answer.ID,
+		"question_id":   answer.QuestionID,
+		"user_id":       answer.UserID,
+		"text":          answer.Text,
+		"comment_count": 0,
+		"created_at":    answer.CreatedAt,
+	}, h.answerHTML(answer, render), render), answer.ModerationStatus, answer.ModerationReason))
 }
 
 func (h *Handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
@@ -180,20 +327,34 @@ func (h *Handler) GetAnswer(w http.ResponseWriter, r *http.Request) {
 		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
 		return
 	}
+	render, ok := parseRender(w, r.URL.Query().Get("render"))
+	if !ok {
+		return
+	}
 
 	answer, err := h.answerService.GetAnswer(ctx, id)
 	if err != nil {
 		sendCustomError(w, err, "Ошибка при получении ответа")
 		return
 	}
+	comments, err := h.commentCounts(ctx, []int{answer.ID})
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении комментариев")
+		return
+	}
 
-	sendJSON(w, http.StatusOK, map[string]interface{}{
-		"id":          answer.ID,
-		"question_id": answer.QuestionID,
-		"user_id":     answer.UserID,
-		"text":        answer.Text,
-		"created_at":  answer.CreatedAt,
-	})
+	if writeNotModified(w, r, answerETag(answer, comments[answer.ID]), answer.UpdatedAt) {
+		return
+	}
+
+	sendJSON(w, http.StatusOK, withModeration(withHTML(map[string]interface{}{
+		"id":            answer.ID,
+		"question_id":   answer.QuestionID,
+		"user_id":       answer.UserID,
+		"text":          answer.Text,
+		"comment_count": comments[answer.ID],
+		"created_at":    answer.CreatedAt,
+	}, h.answerHTML(answer, render), render), answer.ModerationStatus, answer.ModerationReason))
 }
 
 func (h *Handler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
@@ -208,7 +369,25 @@ func (h *Handler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.answerService.DeleteAnswer(ctx, id)
+	version := 0
+	if r.Header.Get("If-Match") != "" {
+		answer, err := h.answerService.GetAnswer(ctx, id)
+		if err != nil {
+			sendCustomError(w, err, "Ошибка при получении ответа")
+			return
+		}
+		comments, err := h.commentCounts(ctx, []int{answer.ID})
+		if err != nil {
+			sendCustomError(w, err, "Ошибка при получении комментариев")
+			return
+		}
+		if !checkIfMatch(w, r, answerETag(answer, comments[answer.ID])) {
+			return
+		}
+		version = answer.Version
+	}
+
+	err = h.answerService.DeleteAnswer(ctx, id, version)
 	if err != nil {
 		sendCustomError(w, err, "Ошибка при удалении ответа")
 		return
@@ -217,10 +396,63 @@ func (h *Handler) DeleteAnswer(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
-func (h *Handler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
-	if _, err := w.Write([]byte("сервер запущен")); err != nil {
-		log.Printf("Ошибка при отправке ответа: %v", err)
+func (h *Handler) NotFound(w http.ResponseWriter, r *http.Request) {
+	sendError(w, http.StatusNotFound, entity.ErrRouteNotFound.Message)
+}
+
+// withModeration добавляет статус модерации и причину, если вопрос или ответ ещё не опубликован.
+// Такой ответ видит только автор; у опубликованных полей нет, чтобы ответ API не менялся
+func withModeration(response map[string]interface{}, status, reason string) map[string]interface{} {
+	if !entity.Published(status) {
+		response["moderation_status"] = status
+		response["moderation_reason"] = reason
+	}
+	return response
+}
+
+// parseRender разбирает ?render=html: тогда в ответ добавляется поле html с текстом, переведённым из Markdown
+func parseRender(w http.ResponseWriter, value string) (bool, bool) {
+	switch value {
+	case "":
+		return false, true
+	case renderHTMLFormat:
+		return true, true
+	default:
+		sendError(w, http.StatusBadRequest, "Параметр render может быть только html")
+		return false, false
+	}
+}
+
+func (h *Handler) questionHTML(q *entity.Question, render bool) string {
+	return h.renderHTML("question", q.ID, q.Version, q.Text, render)
+}
+
+func (h *Handler) answerHTML(a *entity.Answer, render bool) string {
+	return h.renderHTML("answer", a.ID, a.Version, a.Text, render)
+}
+
+// renderHTML текст в Markdown как очищенный HTML; пустая строка, если HTML не запрошен.
+// Текст меняется только вместе с версией, поэтому результат кэшируется по ID и версии
+func (h *Handler) renderHTML(kind string, id, version int, text string, render bool) string {
+	if !render {
+		return ""
+	}
+	if version == 0 {
+		return markdown.Render(text)
+	}
+	key := kind + ":" + strconv.Itoa(id) + ":" + strconv.Itoa(version)
+	if html, ok, _ := h.rendered.Get(context.Background(), key); ok {
+		return string(html)
+	}
+	html := markdown.Render(text)
+	_ = h.rendered.Set(context.Background(), key, []byte(html), renderCacheTTL)
+	return html
+}
+
+// withHTML добавляет в ответ поле html, если клиент запросил ?render=html
+func withHTML(response map[string]interface{}, html string, render bool) map[string]interface{} {
+	if render {
+		response["html"] = html
 	}
+	return response
 }
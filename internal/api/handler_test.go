@@ -14,9 +14,11 @@ import (
 
 type mockQuestionService struct {
 	getAll         func(ctx context.Context) ([]entity.Question, error)
+	getPage        func(ctx context.Context, afterID, limit int) ([]entity.Question, error)
 	createQuestion func(ctx context.Context, text string) (*entity.Question, error)
 	getQuestion    func(ctx context.Context, id int) (*entity.Question, error)
-	deleteQuestion func(ctx context.Context, id int) error
+	getByIDs       func(ctx context.Context, ids []int) ([]entity.Question, []int, error)
+	deleteQuestion func(ctx context.Context, id, version int) error
 }
 
 func (m *mockQuestionService) GetAllQuestions(ctx context.Context) ([]entity.Question, error) {
@@ -26,6 +28,13 @@ func (m *mockQuestionService) GetAllQuestions(ctx context.Context) ([]entity.Que
 	return nil, nil
 }
 
+func (m *mockQuestionService) GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	if m.getPage != nil {
+		return m.getPage(ctx, afterID, limit)
+	}
+	return nil, nil
+}
+
 func (m *mockQuestionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
 	if m.createQuestion != nil {
 		return m.createQuestion(ctx, text)
@@ -40,9 +49,16 @@ func (m *mockQuestionService) GetQuestion(ctx context.Context, id int) (*entity.
 	return nil, nil
 }
 
-func (m *mockQuestionService) DeleteQuestion(ctx context.Context, id int) error {
+func (m *mockQuestionService) GetQuestionsByIDs(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+	if m.getByIDs != nil {
+		return m.getByIDs(ctx, ids)
+	}
+	return nil, nil, nil
+}
+
+func (m *mockQuestionService) DeleteQuestion(ctx context.Context, id, version int) error {
 	if m.deleteQuestion != nil {
-		return m.deleteQuestion(ctx, id)
+		return m.deleteQuestion(ctx, id, version)
 	}
 	return nil
 }
@@ -51,7 +67,10 @@ type mockAnswerService struct {
 	createAnswer         func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error)
 	getAnswer            func(ctx context.Context, id int) (*entity.Answer, error)
 	getAnswersByQuestion func(ctx context.Context, questionID int) ([]entity.Answer, error)
-	deleteAnswer         func(ctx context.Context, id int) error
+	getAnswersByIDs      func(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error)
+	getByIDs             func(ctx context.Context, ids []int) ([]entity.Answer, []int, error)
+	getStats             func(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error)
+	deleteAnswer         func(ctx context.Context, id, version int) error
 }
 
 func (m *mockAnswerService) CreateAnswer(ctx context.Context, questionID int, userID string, text string) (*entity.Answer, error) {
@@ -75,9 +94,30 @@ func (m *mockAnswerService) GetAnswersByQuestion(ctx context.Context, questionID
 	return nil, nil
 }
 
-func (m *mockAnswerService) DeleteAnswer(ctx context.Context, id int) error {
+func (m *mockAnswerService) GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+	if m.getAnswersByIDs != nil {
+		return m.getAnswersByIDs(ctx, questionIDs)
+	}
+	return nil, nil
+}
+
+func (m *mockAnswerService) GetAnswersByIDs(ctx context.Context, ids []int) ([]entity.Answer, []int, error) {
+	if m.getByIDs != nil {
+		return m.getByIDs(ctx, ids)
+	}
+	return nil, nil, nil
+}
+
+func (m *mockAnswerService) GetAnswerStats(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error) {
+	if m.getStats != nil {
+		return m.getStats(ctx, questionIDs)
+	}
+	return nil, nil
+}
+
+func (m *mockAnswerService) DeleteAnswer(ctx context.Context, id, version int) error {
 	if m.deleteAnswer != nil {
-		return m.deleteAnswer(ctx, id)
+		return m.deleteAnswer(ctx, id, version)
 	}
 	return nil
 }
@@ -100,7 +140,7 @@ func TestGetQuestions_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := httptest.NewRequest(http.MethodGet, "/questions", nil)
 	w := httptest.NewRecorder()
@@ -137,6 +177,189 @@ func TestGetQuestions_Success(t *testing.T) {
 	}
 }
 
+func TestGetQuestions_Page(t *testing.T) {
+	mockQService := &mockQuestionService{
+		getPage: func(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+			var page []entity.Question
+			for id := afterID + 1; id <= 5 && len(page) < limit; id++ {
+				page = append(page, entity.Question{ID: id, Text: "Q"})
+			}
+			return page, nil
+		},
+	}
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/?after=1&limit=2", nil)
+	w := httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+
+	var response QuestionsMinimalListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Questions) != 2 || response.Questions[0].ID != 2 {
+		t.Errorf("expected questions 2 and 3, got %+v", response.Questions)
+	}
+	if response.NextAfter == nil || *response.NextAfter != 3 {
+		t.Errorf("expected next_after 3, got %v", response.NextAfter)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/questions/?after=3&limit=2", nil)
+	w = httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	response = QuestionsMinimalListResponse{}
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Questions) != 2 || response.NextAfter != nil {
+		t.Errorf("expected last page without next_after, got %d questions and %v", len(response.Questions), response.NextAfter)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/questions/?limit=1000", nil)
+	w = httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetQuestions_ByIDs(t *testing.T) {
+	var requested []int
+	mockQService := &mockQuestionService{
+		getByIDs: func(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+			requested = ids
+			return []entity.Question{{ID: 3, Text: "Q3"}, {ID: 1, Text: "Q1"}}, []int{2}, nil
+		},
+	}
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions?ids=3,2,1", nil)
+	w := httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var response QuestionsBatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(requested) != 3 || requested[0] != 3 || requested[2] != 1 {
+		t.Errorf("expected ids [3 2 1], got %v", requested)
+	}
+	if len(response.Questions) != 2 || response.Questions[0].ID != 3 || response.Questions[1].ID != 1 {
+		t.Errorf("expected questions 3 and 1, got %+v", response.Questions)
+	}
+	if len(response.Missing) != 1 || response.Missing[0] != 2 {
+		t.Errorf("expected missing [2], got %v", response.Missing)
+	}
+
+	for _, query := range []string{"ids=", "ids=1,abc", "ids=0"} {
+		req = httptest.NewRequest(http.MethodGet, "/questions?"+query, nil)
+		w = httptest.NewRecorder()
+		handler.GetQuestions(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for %q, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
+
+func TestGetQuestions_Include(t *testing.T) {
+	latest := &entity.Answer{ID: 7, QuestionID: 1, UserID: "alice", Text: "A", Version: 1}
+	stats := map[int]entity.AnswerStats{1: {Count: 2, Latest: latest}}
+	var statsCalls int
+	mockQService := &mockQuestionService{
+		getAll: func(ctx context.Context) ([]entity.Question, error) {
+			return []entity.Question{{ID: 1, Text: "Q1"}, {ID: 2, Text: "Q2"}}, nil
+		},
+	}
+	mockAService := &mockAnswerService{
+		getStats: func(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error) {
+			statsCalls++
+			return stats, nil
+		},
+	}
+	handler := NewHandler(mockQService, mockAService, nil, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/?include=answer_count,latest_answer", nil)
+	w := httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+
+	var response QuestionsMinimalListResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if statsCalls != 1 {
+		t.Errorf("expected one stats query, got %d", statsCalls)
+	}
+	first, second := response.Questions[0], response.Questions[1]
+	if first.AnswerCount == nil || *first.AnswerCount != 2 || first.LatestAnswer == nil || first.LatestAnswer.ID != 7 {
+		t.Errorf("expected 2 answers with latest 7, got %+v", first)
+	}
+	if second.AnswerCount == nil || *second.AnswerCount != 0 || second.LatestAnswer != nil {
+		t.Errorf("expected zero answers without latest, got %+v", second)
+	}
+
+	// новый ответ меняет ETag списка с include, хотя сами вопросы не изменились
+	etag := w.Header().Get("ETag")
+	stats[1] = entity.AnswerStats{Count: 3, Latest: &entity.Answer{ID: 8, QuestionID: 1, Version: 1}}
+	req = httptest.NewRequest(http.MethodGet, "/questions/?include=answer_count", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d after a new answer, got %d", http.StatusOK, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/questions/?include=answers", nil)
+	w = httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for unknown include, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestBatchGetAnswers(t *testing.T) {
+	mockAService := &mockAnswerService{
+		getByIDs: func(ctx context.Context, ids []int) ([]entity.Answer, []int, error) {
+			if len(ids) > 100 {
+				return nil, nil, entity.ErrTooManyIDs
+			}
+			return []entity.Answer{{ID: 5, QuestionID: 1, UserID: "bob", Text: "A"}}, []int{6}, nil
+		},
+	}
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	req := createTestRequest(http.MethodPost, "/answers/batch-get", []byte(`{"ids": [5, 6]}`))
+	w := httptest.NewRecorder()
+	handler.BatchGetAnswers(w, req)
+
+	var response AnswersBatchResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Answers) != 1 || response.Answers[0].UserID != "bob" || response.Answers[0].QuestionID != 1 {
+		t.Errorf("expected answer 5 by bob, got %+v", response.Answers)
+	}
+	if len(response.Missing) != 1 || response.Missing[0] != 6 {
+		t.Errorf("expected missing [6], got %v", response.Missing)
+	}
+
+	tooMany := make([]int, 101)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	for _, ids := range [][]int{{}, tooMany} {
+		body, _ := json.Marshal(BatchGetAnswersRequest{IDs: ids})
+		req = createTestRequest(http.MethodPost, "/answers/batch-get", body)
+		w = httptest.NewRecorder()
+		handler.BatchGetAnswers(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("expected status %d for %d ids, got %d", http.StatusBadRequest, len(ids), w.Code)
+		}
+	}
+}
+
 func TestGetQuestions_EmptyList(t *testing.T) {
 	mockQService := &mockQuestionService{
 		getAll: func(ctx context.Context) ([]entity.Question, error) {
@@ -144,7 +367,7 @@ func TestGetQuestions_EmptyList(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := httptest.NewRequest(http.MethodGet, "/questions", nil)
 	w := httptest.NewRecorder()
@@ -166,6 +389,37 @@ func TestGetQuestions_EmptyList(t *testing.T) {
 	}
 }
 
+func TestGetQuestions_DeleteIgnoresIfModifiedSince(t *testing.T) {
+	updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	questions := []entity.Question{
+		{ID: 1, Text: "Q1", Version: 1, UpdatedAt: updated},
+		{ID: 2, Text: "Q2", Version: 1, UpdatedAt: updated.Add(-time.Hour)},
+	}
+	mockQService := &mockQuestionService{
+		getAll: func(ctx context.Context) ([]entity.Question, error) {
+			return questions, nil
+		},
+	}
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/questions", nil)
+	w := httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	if lastModified := w.Header().Get("Last-Modified"); lastModified != "" {
+		t.Errorf("expected no Last-Modified on the list, got %s", lastModified)
+	}
+
+	// удаление старого вопроса не сдвигает дату изменения, но клиент всё равно должен получить новый список
+	questions = questions[:1]
+	req = httptest.NewRequest(http.MethodGet, "/questions", nil)
+	req.Header.Set("If-Modified-Since", updated.Format(http.TimeFormat))
+	w = httptest.NewRecorder()
+	handler.GetQuestions(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d after a delete, got %d", http.StatusOK, w.Code)
+	}
+}
+
 func TestGetQuestions_ServiceError(t *testing.T) {
 	mockQService := &mockQuestionService{
 		getAll: func(ctx context.Context) ([]entity.Question, error) {
@@ -173,7 +427,7 @@ func TestGetQuestions_ServiceError(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := httptest.NewRequest(http.MethodGet, "/questions", nil)
 	w := httptest.NewRecorder()
@@ -207,7 +461,7 @@ func TestCreateQuestion_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	body := CreateQuestionRequest{Text: "What is Go?"}
 	bodyBytes, _ := json.Marshal(body)
@@ -242,7 +496,7 @@ func TestCreateQuestion_EmptyText(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	body := CreateQuestionRequest{Text: ""}
 	bodyBytes, _ := json.Marshal(body)
@@ -273,7 +527,7 @@ func TestCreateQuestion_WhitespaceText(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	body := CreateQuestionRequest{Text: "   "}
 	bodyBytes, _ := json.Marshal(body)
@@ -294,7 +548,7 @@ func TestCreateQuestion_DatabaseError(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	body := CreateQuestionRequest{Text: "What is Go?"}
 	bodyBytes, _ := json.Marshal(body)
@@ -347,7 +601,7 @@ func TestGetQuestion_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, mockAService, 5)
+	handler := NewHandler(mockQService, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/questions/1", nil)
 	req.SetPathValue("id", "1")
@@ -405,7 +659,7 @@ func TestGetQuestion_NoAnswers(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, mockAService, 5)
+	handler := NewHandler(mockQService, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/questions/2", nil)
 	req.SetPathValue("id", "2")
@@ -441,7 +695,7 @@ func TestGetQuestion_NotFound(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/questions/999", nil)
 	req.SetPathValue("id", "999")
@@ -465,7 +719,7 @@ func TestGetQuestion_NotFound(t *testing.T) {
 }
 
 func TestGetQuestion_InvalidID(t *testing.T) {
-	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, 5)
+	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/questions/invalid", nil)
 	req.SetPathValue("id", "invalid")
@@ -480,7 +734,7 @@ func TestGetQuestion_InvalidID(t *testing.T) {
 
 func TestDeleteQuestion_Success(t *testing.T) {
 	mockQService := &mockQuestionService{
-		deleteQuestion: func(ctx context.Context, id int) error {
+		deleteQuestion: func(ctx context.Context, id, version int) error {
 			if id == 1 {
 				return nil
 			}
@@ -488,7 +742,7 @@ func TestDeleteQuestion_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/questions/1", nil)
 	req.SetPathValue("id", "1")
@@ -503,12 +757,12 @@ func TestDeleteQuestion_Success(t *testing.T) {
 
 func TestDeleteQuestion_NotFound(t *testing.T) {
 	mockQService := &mockQuestionService{
-		deleteQuestion: func(ctx context.Context, id int) error {
+		deleteQuestion: func(ctx context.Context, id, version int) error {
 			return entity.ErrQuestionNotFound
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/questions/999", nil)
 	req.SetPathValue("id", "999")
@@ -532,7 +786,7 @@ func TestDeleteQuestion_NotFound(t *testing.T) {
 }
 
 func TestDeleteQuestion_InvalidID(t *testing.T) {
-	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, 5)
+	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/questions/invalid", nil)
 	req.SetPathValue("id", "invalid")
@@ -547,12 +801,12 @@ func TestDeleteQuestion_InvalidID(t *testing.T) {
 
 func TestDeleteQuestion_DatabaseError(t *testing.T) {
 	mockQService := &mockQuestionService{
-		deleteQuestion: func(ctx context.Context, id int) error {
+		deleteQuestion: func(ctx context.Context, id, version int) error {
 			return entity.ErrDatabaseQuery
 		},
 	}
 
-	handler := NewHandler(mockQService, &mockAnswerService{}, 5)
+	handler := NewHandler(mockQService, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/questions/1", nil)
 	req.SetPathValue("id", "1")
@@ -582,7 +836,7 @@ func TestGetAnswer_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(&mockQuestionService{}, mockAService, 5)
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/answers/1", nil)
 	req.SetPathValue("id", "1")
@@ -617,6 +871,83 @@ func TestGetAnswer_Success(t *testing.T) {
 	}
 }
 
+func TestGetAnswer_RenderHTML(t *testing.T) {
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			return &entity.Answer{ID: id, QuestionID: 1, UserID: "user1", Text: "```go\nfmt.Println(\"<b>\")\n```"}, nil
+		},
+	}
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	tests := []struct {
+		query  string
+		status int
+		html   string
+	}{
+		{"", http.StatusOK, ""},
+		{"?render=html", http.StatusOK, "<pre><code class=\"language-go\">fmt.Println(\"&lt;b&gt;\")\n</code></pre>\n"},
+		{"?render=pdf", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		req := createTestRequest(http.MethodGet, "/answers/1"+tt.query, nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		handler.GetAnswer(w, req)
+
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected status %d, got %d", tt.query, tt.status, w.Code)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		html, ok := response["html"]
+		if tt.html == "" && ok || tt.html != "" && html != tt.html {
+			t.Errorf("%s: expected html %q, got %v", tt.query, tt.html, html)
+		}
+	}
+}
+
+func TestGetAnswer_RenderHTMLCachedByVersion(t *testing.T) {
+	answer := entity.Answer{ID: 1, QuestionID: 1, UserID: "user1", Text: "*один*", Version: 1}
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			a := answer
+			return &a, nil
+		},
+	}
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	renderedHTML := func() interface{} {
+		req := createTestRequest(http.MethodGet, "/answers/1?render=html", nil)
+		req.SetPathValue("id", "1")
+		w := httptest.NewRecorder()
+		handler.GetAnswer(w, req)
+		var response map[string]interface{}
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response["html"]
+	}
+
+	if html := renderedHTML(); html != "<p><em>один</em></p>\n" {
+		t.Fatalf("expected rendered text, got %v", html)
+	}
+	// та же версия берётся из кэша, новая рендерится заново
+	answer.Text = "*два*"
+	if html := renderedHTML(); html != "<p><em>один</em></p>\n" {
+		t.Errorf("expected cached html for the same version, got %v", html)
+	}
+	answer.Version = 2
+	if html := renderedHTML(); html != "<p><em>два</em></p>\n" {
+		t.Errorf("expected html of the new version, got %v", html)
+	}
+}
+
 func TestGetAnswer_NotFound(t *testing.T) {
 	mockAService := &mockAnswerService{
 		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
@@ -624,7 +955,7 @@ func TestGetAnswer_NotFound(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(&mockQuestionService{}, mockAService, 5)
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/answers/999", nil)
 	req.SetPathValue("id", "999")
@@ -648,7 +979,7 @@ func TestGetAnswer_NotFound(t *testing.T) {
 }
 
 func TestGetAnswer_InvalidID(t *testing.T) {
-	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, 5)
+	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodGet, "/answers/invalid", nil)
 	req.SetPathValue("id", "invalid")
@@ -663,7 +994,7 @@ func TestGetAnswer_InvalidID(t *testing.T) {
 
 func TestDeleteAnswer_Success(t *testing.T) {
 	mockAService := &mockAnswerService{
-		deleteAnswer: func(ctx context.Context, id int) error {
+		deleteAnswer: func(ctx context.Context, id, version int) error {
 			if id == 1 {
 				return nil
 			}
@@ -671,7 +1002,7 @@ func TestDeleteAnswer_Success(t *testing.T) {
 		},
 	}
 
-	handler := NewHandler(&mockQuestionService{}, mockAService, 5)
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/answers/1", nil)
 	req.SetPathValue("id", "1")
@@ -686,12 +1017,12 @@ func TestDeleteAnswer_Success(t *testing.T) {
 
 func TestDeleteAnswer_NotFound(t *testing.T) {
 	mockAService := &mockAnswerService{
-		deleteAnswer: func(ctx context.Context, id int) error {
+		deleteAnswer: func(ctx context.Context, id, version int) error {
 			return entity.ErrAnswerNotFound
 		},
 	}
 
-	handler := NewHandler(&mockQuestionService{}, mockAService, 5)
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/answers/999", nil)
 	req.SetPathValue("id", "999")
@@ -715,7 +1046,7 @@ func TestDeleteAnswer_NotFound(t *testing.T) {
 }
 
 func TestDeleteAnswer_InvalidID(t *testing.T) {
-	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, 5)
+	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/answers/invalid", nil)
 	req.SetPathValue("id", "invalid")
@@ -730,12 +1061,12 @@ func TestDeleteAnswer_InvalidID(t *testing.T) {
 
 func TestDeleteAnswer_DatabaseError(t *testing.T) {
 	mockAService := &mockAnswerService{
-		deleteAnswer: func(ctx context.Context, id int) error {
+		deleteAnswer: func(ctx context.Context, id, version int) error {
 			return entity.ErrDatabaseQuery
 		},
 	}
 
-	handler := NewHandler(&mockQuestionService{}, mockAService, 5)
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
 
 	req := createTestRequest(http.MethodDelete, "/answers/1", nil)
 	req.SetPathValue("id", "1")
@@ -747,3 +1078,125 @@ func TestDeleteAnswer_DatabaseError(t *testing.T) {
 		t.Errorf("expected status %d, got %d", http.StatusInternalServerError, w.Code)
 	}
 }
+
+func TestCreateAnswer_RequiresUser(t *testing.T) {
+	var author string
+	mockAService := &mockAnswerService{
+		createAnswer: func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+			author = userID
+			return &entity.Answer{ID: 1, QuestionID: questionID, UserID: "alice", Text: text}, nil
+		},
+	}
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	req := createTestRequest(http.MethodPost, "/questions/1/answers/", []byte(`{"user_id": "alice", "text": "Ответ"}`))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	handler.CreateAnswer(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d without token, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req = createTestRequest(http.MethodPost, "/questions/1/answers/", []byte(`{"text": "Ответ"}`))
+	req.SetPathValue("id", "1")
+	req = req.WithContext(WithUserID(req.Context(), "alice"))
+	w = httptest.NewRecorder()
+	handler.CreateAnswer(w, req)
+	if w.Code != http.StatusCreated || author != "" {
+		t.Errorf("expected created answer with user_id left to service, got %d and %q", w.Code, author)
+	}
+}
+
+func TestGetAnswer_NotModified(t *testing.T) {
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			return &entity.Answer{ID: id, QuestionID: 1, UserID: "user1", Text: "Go is a language", Version: 2}, nil
+		},
+	}
+
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	req := createTestRequest(http.MethodGet, "/answers/1", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.GetAnswer(w, req)
+
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatalf("expected ETag header")
+	}
+
+	req = createTestRequest(http.MethodGet, "/answers/1", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+
+	handler.GetAnswer(w, req)
+
+	if w.Code != http.StatusNotModified {
+		t.Errorf("expected status %d, got %d", http.StatusNotModified, w.Code)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("expected empty body, got %s", w.Body.String())
+	}
+}
+
+func TestDeleteAnswer_PreconditionFailed(t *testing.T) {
+	deleted := false
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			return &entity.Answer{ID: id, Version: 3}, nil
+		},
+		deleteAnswer: func(ctx context.Context, id, version int) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	req := createTestRequest(http.MethodDelete, "/answers/1", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("If-Match", `"a1-v2"`)
+	w := httptest.NewRecorder()
+
+	handler.DeleteAnswer(w, req)
+
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, w.Code)
+	}
+	if deleted {
+		t.Errorf("expected answer not to be deleted")
+	}
+}
+
+func TestDeleteAnswer_PassesCheckedVersion(t *testing.T) {
+	var gotVersion int
+	mockAService := &mockAnswerService{
+		getAnswer: func(ctx context.Context, id int) (*entity.Answer, error) {
+			return &entity.Answer{ID: id, Version: 3}, nil
+		},
+		// ответ изменили между проверкой If-Match и удалением
+		deleteAnswer: func(ctx context.Context, id, version int) error {
+			gotVersion = version
+			return entity.ErrPreconditionFailed
+		},
+	}
+
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+
+	req := createTestRequest(http.MethodDelete, "/answers/1", nil)
+	req.SetPathValue("id", "1")
+	req.Header.Set("If-Match", `"a1-v3"`)
+	w := httptest.NewRecorder()
+
+	handler.DeleteAnswer(w, req)
+
+	if gotVersion != 3 {
+		t.Errorf("expected checked version 3 to be passed to the service, got %d", gotVersion)
+	}
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("expected status %d, got %d", http.StatusPreconditionFailed, w.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Liveness отвечает 200, пока процесс жив и обрабатывает запросы
+func (h *HealthHandler) Liveness(w http.ResponseWriter, r *http.Request) {
+	sendHealthReport(w, h.healthService.Liveness(r.Context()))
+}
+
+// Readiness проверяет БД, миграции и состояние остановки сервера
+func (h *HealthHandler) Readiness(w http.ResponseWriter, r *http.Request) {
+	sendHealthReport(w, h.healthService.Readiness(r.Context()))
+}
+
+func sendHealthReport(w http.ResponseWriter, report entity.HealthReport) {
+	components := make(map[string]ComponentHealthResponse, len(report.Components))
+	for name, c := range report.Components {
+		components[name] = ComponentHealthResponse{Status: c.Status, Error: c.Error}
+	}
+
+	statusCode := http.StatusOK
+	if !report.IsUp() {
+		statusCode = http.StatusServiceUnavailable
+	}
+
+	sendJSON(w, statusCode, HealthResponse{
+		Status:     report.Status,
+		Components: components,
+	})
+}
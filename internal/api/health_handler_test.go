@@ -0,0 +1,102 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type mockHealthService struct {
+	readiness func(ctx context.Context) entity.HealthReport
+}
+
+func (m *mockHealthService) Liveness(ctx context.Context) entity.HealthReport {
+	return entity.HealthReport{
+		Status:     entity.HealthStatusUp,
+		Components: map[string]entity.ComponentHealth{"process": {Status: entity.HealthStatusUp}},
+	}
+}
+
+func (m *mockHealthService) Readiness(ctx context.Context) entity.HealthReport {
+	if m.readiness != nil {
+		return m.readiness(ctx)
+	}
+	return m.Liveness(ctx)
+}
+
+func (m *mockHealthService) StartDraining() {}
+
+func TestReadiness_DatabaseDown(t *testing.T) {
+	mockHService := &mockHealthService{
+		readiness: func(ctx context.Context) entity.HealthReport {
+			return entity.HealthReport{
+				Status: entity.HealthStatusDown,
+				Components: map[string]entity.ComponentHealth{
+					"database": {Status: entity.HealthStatusDown, Error: "connection refused"},
+				},
+			}
+		},
+	}
+
+	handler := NewHealthHandler(mockHService)
+
+	req := createTestRequest(http.MethodGet, "/readyz", nil)
+	w := httptest.NewRecorder()
+
+	handler.Readiness(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+
+	var response HealthResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("failed to decode response: %v", err)
+	}
+
+	if response.Components["database"].Status != entity.HealthStatusDown {
+		t.Errorf("expected database status 'down', got '%s'", response.Components["database"].Status)
+	}
+}
+
+func TestLiveness_Success(t *testing.T) {
+	handler := NewHealthHandler(&mockHealthService{})
+
+	req := createTestRequest(http.MethodGet, "/healthz", nil)
+	w := httptest.NewRecorder()
+
+	handler.Liveness(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestRouter_UnknownPath(t *testing.T) {
+	handler := NewHandler(&mockQuestionService{}, &mockAnswerService{}, nil, 5)
+	mux := NewRouter(Handlers{API: handler, Health: NewHealthHandler(&mockHealthService{})}).Setup()
+
+	req := createTestRequest(http.MethodGet, "/unknown", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+
+	var response ErrorResponse
+	err := json.NewDecoder(w.Body).Decode(&response)
+	if err != nil {
+		t.Errorf("failed to decode response: %v", err)
+	}
+
+	if response.Error != "Ресурс не найден" {
+		t.Errorf("expected error 'Ресурс не найден', got '%s'", response.Error)
+	}
+}
@@ -23,7 +23,7 @@ func sendError(w http.ResponseWriter, statusCode int, errorMessage string) {
 func sendCustomError(w http.ResponseWriter, err error, logMessage string) {
 	if customErr, ok := err.(entity.CustomError); ok {
 		log.Printf("%s: %v", logMessage, err)
-		sendError(w, customErr.Code, customErr.Message)
+		sendJSON(w, customErr.Code, entity.ErrorResponse{Error: customErr.Message, Field: customErr.Field})
 		return
 	}
 
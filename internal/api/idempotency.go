@@ -0,0 +1,150 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+	maxIdempotentRequestBody = 1 << 20
+	idempotencyFinishTimeout = 5 * time.Second
+)
+
+// replayedHeaders заголовки исходного ответа, которые повторяются вместе с телом
+var replayedHeaders = []string{"Content-Type", "ETag", "Location"}
+
+type Idempotency struct {
+	service service.IdempotencyService
+}
+
+func NewIdempotency(idempotencyService service.IdempotencyService) *Idempotency {
+	return &Idempotency{service: idempotencyService}
+}
+
+// recordingResponseWriter копирует ответ, чтобы сохранить его для повторов
+type recordingResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rw *recordingResponseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingResponseWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *recordingResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Wrap включает поддержку заголовка Idempotency-Key для обработчика.
+// Запросы без заголовка обрабатываются как обычно
+func (i *Idempotency) Wrap(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxIdempotentRequestBody))
+		if err != nil {
+			log.Printf("Ошибка чтения тела запроса: %v", err)
+			sendError(w, http.StatusBadRequest, "Некорректное тело запроса")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(r)
+		fingerprint := requestFingerprint(r.Method, r.URL.Path, body)
+
+		record, err := i.service.Begin(r.Context(), scope, key, fingerprint)
+		if err != nil {
+			sendCustomError(w, err, "Ошибка проверки Idempotency-Key")
+			return
+		}
+		if record != nil {
+			w.Header().Set("Content-Type", "application/json")
+			for name, value := range record.ResponseHeaders {
+				w.Header().Set(name, value)
+			}
+			w.Header().Set(idempotentReplayedHeader, "true")
+			w.WriteHeader(record.ResponseStatus)
+			if _, err := w.Write(record.ResponseBody); err != nil {
+				log.Printf("Ошибка при отправке ответа: %v", err)
+			}
+			return
+		}
+
+		rw := &recordingResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		completed := false
+		defer func() {
+			// клиент мог отключиться, но результат всё равно нужно зафиксировать
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), idempotencyFinishTimeout)
+			defer cancel()
+
+			// ошибки сервера и паники не кэшируем: повтор запроса должен выполниться заново
+			if completed && rw.statusCode < http.StatusInternalServerError {
+				err = i.service.Complete(ctx, scope, key, rw.statusCode, responseHeaders(rw.Header()), rw.body.Bytes())
+				if err == nil {
+					return
+				}
+				log.Printf("Ошибка сохранения результата Idempotency-Key: %v", err)
+				// иначе ключ остался бы занят до истечения TTL и каждый повтор получал бы 409
+			}
+			if err := i.service.Abort(ctx, scope, key); err != nil {
+				log.Printf("Ошибка освобождения Idempotency-Key: %v", err)
+			}
+		}()
+
+		next(rw, r)
+		completed = true
+	})
+}
+
+// idempotencyScope область ключа: метод, путь и клиент — пользователь из токена, а без токена IP.
+// Иначе два клиента с одинаковым ключом получали бы ответы друг друга
+func idempotencyScope(r *http.Request) string {
+	client := "anonymous"
+	if userID, ok := UserIDFromContext(r.Context()); ok {
+		client = "user:" + userID
+	} else if ip := service.AuditMetaFromContext(r.Context()).IP; ip != "" {
+		client = "ip:" + ip
+	}
+	return client + " " + r.Method + " " + r.URL.Path
+}
+
+func responseHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(replayedHeaders))
+	for _, name := range replayedHeaders {
+		if value := header.Get(name); value != "" {
+			headers[name] = value
+		}
+	}
+	return headers
+}
+
+func requestFingerprint(method, path string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(path))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
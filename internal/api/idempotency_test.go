@@ -0,0 +1,137 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type mockIdempotencyService struct {
+	records     map[string]*entity.IdempotencyRecord
+	completeErr error
+}
+
+func (m *mockIdempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*entity.IdempotencyRecord, error) {
+	record, ok := m.records[scope+key]
+	if !ok {
+		m.records[scope+key] = &entity.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      entity.IdempotencyStatusInProgress,
+		}
+		return nil, nil
+	}
+	if record.Fingerprint != fingerprint {
+		return nil, entity.ErrIdempotencyKeyMismatch
+	}
+	if record.Status != entity.IdempotencyStatusCompleted {
+		return nil, entity.ErrIdempotencyRequestInProgress
+	}
+	return record, nil
+}
+
+func (m *mockIdempotencyService) Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte) error {
+	if m.completeErr != nil {
+		return m.completeErr
+	}
+	record := m.records[scope+key]
+	record.Status = entity.IdempotencyStatusCompleted
+	record.ResponseStatus = status
+	record.ResponseHeaders = headers
+	record.ResponseBody = body
+	return nil
+}
+
+func (m *mockIdempotencyService) Abort(ctx context.Context, scope, key string) error {
+	delete(m.records, scope+key)
+	return nil
+}
+
+func (m *mockIdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency_ReplaysAndRejectsDifferentBody(t *testing.T) {
+	calls := 0
+	mockAService := &mockAnswerService{
+		createAnswer: func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+			calls++
+			return &entity.Answer{ID: calls, QuestionID: questionID, UserID: userID, Text: text, CreatedAt: time.Now()}, nil
+		},
+	}
+
+	handler := NewHandler(&mockQuestionService{}, mockAService, nil, 5)
+	idempotency := NewIdempotency(&mockIdempotencyService{records: map[string]*entity.IdempotencyRecord{}})
+	wrapped := idempotency.Wrap(handler.CreateAnswer)
+
+	sendAs := func(userID, body string) *httptest.ResponseRecorder {
+		req := createTestRequest(http.MethodPost, "/questions/1/answers/", []byte(body))
+		req.SetPathValue("id", "1")
+		req = req.WithContext(WithUserID(req.Context(), userID))
+		req.Header.Set(IdempotencyKeyHeader, "retry-1")
+		w := httptest.NewRecorder()
+		wrapped.ServeHTTP(w, req)
+		return w
+	}
+	send := func(body string) *httptest.ResponseRecorder {
+		return sendAs("user1", body)
+	}
+
+	first := send(`{"user_id": "user1", "text": "Go is a language"}`)
+	if first.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, first.Code)
+	}
+
+	second := send(`{"user_id": "user1", "text": "Go is a language"}`)
+	if second.Code != http.StatusCreated {
+		t.Errorf("expected replayed status %d, got %d", http.StatusCreated, second.Code)
+	}
+	if second.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("expected Idempotent-Replayed header")
+	}
+	if second.Body.String() != first.Body.String() {
+		t.Errorf("expected replayed body %s, got %s", first.Body.String(), second.Body.String())
+	}
+	if etag := second.Header().Get("ETag"); etag == "" || etag != first.Header().Get("ETag") {
+		t.Errorf("expected replayed ETag %q, got %q", first.Header().Get("ETag"), etag)
+	}
+	if calls != 1 {
+		t.Errorf("expected service to be called once, got %d", calls)
+	}
+
+	third := send(`{"user_id": "user1", "text": "Another text"}`)
+	if third.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d", http.StatusUnprocessableEntity, third.Code)
+	}
+
+	// тот же ключ у другого пользователя — другой запрос
+	other := sendAs("user2", `{"text": "Go is a language"}`)
+	if other.Code != http.StatusCreated || other.Header().Get("Idempotent-Replayed") != "" || calls != 2 {
+		t.Errorf("expected request of another user to run, got status %d and %d calls", other.Code, calls)
+	}
+}
+
+func TestIdempotency_ReleasesKeyWhenCompleteFails(t *testing.T) {
+	mockAService := &mockAnswerService{
+		createAnswer: func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+			return &entity.Answer{ID: 1, QuestionID: questionID, UserID: userID, Text: text}, nil
+		},
+	}
+	idempotencyService := &mockIdempotencyService{records: map[string]*entity.IdempotencyRecord{}, completeErr: entity.ErrDatabaseQuery}
+	wrapped := NewIdempotency(idempotencyService).Wrap(NewHandler(&mockQuestionService{}, mockAService, nil, 5).CreateAnswer)
+
+	req := createTestRequest(http.MethodPost, "/questions/1/answers/", []byte(`{"text": "Go is a language"}`))
+	req.SetPathValue("id", "1")
+	req = req.WithContext(WithUserID(req.Context(), "user1"))
+	req.Header.Set(IdempotencyKeyHeader, "retry-1")
+	wrapped.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(idempotencyService.records) != 0 {
+		t.Errorf("expected key to be released after failed Complete, got %+v", idempotencyService.records)
+	}
+}
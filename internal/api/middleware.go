@@ -1,9 +1,21 @@
 package api
 
 import (
+	"bufio"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Middleware func(http.Handler) http.Handler
@@ -12,6 +24,10 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
+				// ErrAbortHandler — штатный способ оборвать ответ, который уже начал отправляться
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
 				log.Printf("Паника восстановлена: %v", err)
 				sendError(w, http.StatusInternalServerError, "Внутренняя ошибка сервера")
 			}
@@ -20,6 +36,36 @@ func RecoverMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// RequestIDHeader заголовок с ID запроса для журнала аудита
+const RequestIDHeader = "X-Request-ID"
+
+// AuditMiddleware кладёт в контекст данные для журнала аудита: пользователя, ID запроса и IP клиента.
+// ID запроса берётся из X-Request-ID или генерируется и возвращается в том же заголовке.
+// Должен стоять после AuthMiddleware
+func AuditMiddleware(trustedProxies []string) (Middleware, error) {
+	resolver, err := newClientIPResolver(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if !service.ValidRequestID(requestID) {
+				requestID = service.NewRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+
+			userID, _ := UserIDFromContext(r.Context())
+			ctx := service.WithAuditMeta(r.Context(), entity.AuditMeta{
+				Actor:     userID,
+				RequestID: requestID,
+				IP:        resolver.clientIP(r),
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}, nil
+}
+
 // responseWriter обёртка для отслеживания статус кода ответа
 type responseWriter struct {
 	http.ResponseWriter
@@ -31,6 +77,17 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap даёт http.ResponseController доступ к Flush и дедлайнам исходного writer
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Hijack нужен для WebSocket: библиотеки проверяют http.Hijacker напрямую, без Unwrap
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rw.statusCode = http.StatusSwitchingProtocols
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
+
 func LogMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -43,11 +100,79 @@ func LogMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// tracingResponseWriter отмечает в спане момент отправки заголовков,
+// чтобы отделить время обработки от времени кодирования тела ответа
+type tracingResponseWriter struct {
+	http.ResponseWriter
+	span        trace.Span
+	statusCode  int
+	wroteHeader bool
+}
+
+func (rw *tracingResponseWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.statusCode = code
+		rw.span.AddEvent("http.response.headers_sent")
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *tracingResponseWriter) Write(b []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+	return rw.ResponseWriter.Write(b)
+}
+
+func (rw *tracingResponseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *tracingResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rw.statusCode = http.StatusSwitchingProtocols
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
+
+// TracingMiddleware создаёт спан на каждый HTTP запрос, продолжая трассировку из заголовка traceparent
+func TracingMiddleware(next http.Handler) http.Handler {
+	tracer := otel.Tracer("github.com/andrey-samosuk/answer-questions/internal/api")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.request.method", r.Method),
+				attribute.String("url.path", r.URL.Path),
+				attribute.String("client.address", r.RemoteAddr),
+			),
+		)
+		defer span.End()
+
+		rw := &tracingResponseWriter{ResponseWriter: w, span: span, statusCode: http.StatusOK}
+		req := r.WithContext(ctx)
+
+		next.ServeHTTP(rw, req)
+
+		// Pattern заполняется ServeMux уже после выбора обработчика
+		if req.Pattern != "" {
+			span.SetName(req.Pattern)
+			span.SetAttributes(attribute.String("http.route", req.Pattern))
+		}
+		span.SetAttributes(attribute.Int("http.response.status_code", rw.statusCode))
+		if rw.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, fmt.Sprintf("HTTP %d", rw.statusCode))
+		}
+	})
+}
+
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, traceparent, tracestate, Idempotency-Key, If-Match, If-None-Match, If-Modified-Since, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "ETag, Last-Modified, Location, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, X-Request-ID")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
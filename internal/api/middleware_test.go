@@ -0,0 +1,153 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/ratelimit"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+func TestTracingMiddleware_PropagatesTraceparent(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var gotTraceID string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTraceID = trace.SpanContextFromContext(r.Context()).TraceID().String()
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	TracingMiddleware(next).ServeHTTP(w, req)
+
+	if gotTraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("expected trace id from traceparent, got '%s'", gotTraceID)
+	}
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestCORSMiddleware_PreflightBeforeAuth(t *testing.T) {
+	called := false
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	})
+	handler := Chain(next, CORSMiddleware, AuthMiddleware(service.NewStaticTokenAuthService(nil)))
+
+	req := httptest.NewRequest(http.MethodOptions, "/comments/3", nil)
+	req.Header.Set("Origin", "https://app.example")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPatch)
+	req.Header.Set("Access-Control-Request-Headers", "authorization, content-type")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || called {
+		t.Errorf("expected preflight to be answered by CORS middleware, got %d (handler called: %v)", w.Code, called)
+	}
+	if methods := w.Header().Get("Access-Control-Allow-Methods"); !strings.Contains(methods, http.MethodPatch) {
+		t.Errorf("expected PATCH to be allowed, got %s", methods)
+	}
+	if headers := w.Header().Get("Access-Control-Allow-Headers"); !strings.Contains(headers, "Authorization") {
+		t.Errorf("expected Authorization header to be allowed, got %s", headers)
+	}
+}
+
+func TestRateLimiter_RejectsWritesOverLimit(t *testing.T) {
+	limiter, err := NewRateLimiter(ratelimit.NewMemoryStore(), map[string]ratelimit.Limit{
+		RouteClassWrite: {Requests: 1, Period: time.Minute, Burst: 1},
+	}, []string{"10.0.0.1"})
+	if err != nil {
+		t.Fatalf("failed to create rate limiter: %v", err)
+	}
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := limiter.Middleware(next)
+
+	newRequest := func(clientIP string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/questions/", nil)
+		req.RemoteAddr = "10.0.0.1:5000"
+		req.Header.Set("X-Forwarded-For", clientIP)
+		return req
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("203.0.113.7"))
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if w.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("expected RateLimit-Remaining 0, got '%s'", w.Header().Get("RateLimit-Remaining"))
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("203.0.113.7"))
+	if w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Errorf("expected Retry-After header")
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("203.0.113.8"))
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected other client to be allowed, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/questions/", nil)
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected reads without limit to pass, got %d", w.Code)
+	}
+}
+
+func TestAuditMiddleware_SetsMeta(t *testing.T) {
+	middleware, err := AuditMiddleware([]string{"10.0.0.0/8"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var meta entity.AuditMeta
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		meta = service.AuditMetaFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/questions/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.Header.Set(RequestIDHeader, "req-42")
+	req = req.WithContext(WithUserID(req.Context(), "alice"))
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if meta.Actor != "alice" || meta.RequestID != "req-42" || meta.IP != "203.0.113.7" {
+		t.Errorf("unexpected audit meta: %+v", meta)
+	}
+	if got := w.Header().Get(RequestIDHeader); got != "req-42" {
+		t.Errorf("expected request ID echoed, got %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/questions/", nil)
+	req.Header.Set(RequestIDHeader, "bad id\nwith newline")
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if meta.RequestID == "" || meta.RequestID == req.Header.Get(RequestIDHeader) || w.Header().Get(RequestIDHeader) != meta.RequestID {
+		t.Errorf("expected generated request ID, got %q", meta.RequestID)
+	}
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// ModerationHandler очередь ручной проверки /admin/moderation; доступна только администраторам из ADMIN_USERS
+type ModerationHandler struct {
+	moderationService service.ModerationService
+	admins            map[string]bool
+	requestTimeout    int
+}
+
+func NewModerationHandler(moderationService service.ModerationService, admins []string, requestTimeout int) *ModerationHandler {
+	return &ModerationHandler{
+		moderationService: moderationService,
+		admins:            newUserSet(admins),
+		requestTimeout:    requestTimeout,
+	}
+}
+
+// ListPending отдаёт ожидающие проверки вопросы (?type=question) или ответы (?type=answer)
+// от старых к новым; следующая страница — ?after=next_after
+func (h *ModerationHandler) ListPending(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, after, ok := parsePage(w, query.Get("limit"), query.Get("after"))
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	items, err := h.moderationService.ListPending(ctx, query.Get("type"), after, limit+1)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении очереди модерации")
+		return
+	}
+
+	response := ModerationQueueResponse{Items: items}
+	if len(items) > limit {
+		response.Items = items[:limit]
+		response.NextAfter = &items[limit-1].ID
+	}
+	sendJSON(w, http.StatusOK, response)
+}
+
+func (h *ModerationHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.moderationService.Approve)
+}
+
+func (h *ModerationHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.moderationService.Reject)
+}
+
+// decide общая часть Approve и Reject: тип и ID из пути, причина из тела
+func (h *ModerationHandler) decide(w http.ResponseWriter, r *http.Request, decision func(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error)) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	// тело необязательно: одобрить можно без причины
+	var req ModerationDecisionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			log.Printf("Ошибка парсинга JSON: %v", err)
+			sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	item, err := decision(ctx, r.PathValue("type"), id, req.Reason)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при модерации")
+		return
+	}
+	sendJSON(w, http.StatusOK, item)
+}
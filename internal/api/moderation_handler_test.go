@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockModerationService struct {
+	service.ModerationService
+	reason string
+}
+
+func (m *mockModerationService) ListPending(ctx context.Context, itemType string, afterID, limit int) ([]entity.ModerationItem, error) {
+	if err := service.ValidateModerationType(itemType); err != nil {
+		return nil, err
+	}
+	var items []entity.ModerationItem
+	for id := afterID + 1; id <= 3 && len(items) < limit; id++ {
+		items = append(items, entity.ModerationItem{Type: itemType, ID: id, Status: entity.ModerationPending})
+	}
+	return items, nil
+}
+
+func (m *mockModerationService) Reject(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error) {
+	m.reason = reason
+	if err := service.ValidateModerationReason(entity.ModerationRejected, reason); err != nil {
+		return nil, err
+	}
+	return &entity.ModerationItem{Type: itemType, ID: id, Status: entity.ModerationRejected, Reason: reason}, nil
+}
+
+func TestModerationHandler_ListPending(t *testing.T) {
+	handler := NewModerationHandler(&mockModerationService{}, []string{"admin"}, 5)
+
+	tests := []struct {
+		name   string
+		userID string
+		query  string
+		status int
+	}{
+		{"not an admin", "alice", "?type=answer", http.StatusForbidden},
+		{"unknown type", "admin", "?type=comment", http.StatusBadRequest},
+		{"first page", "admin", "?type=answer&limit=2", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/moderation"+tt.query, nil)
+			req = req.WithContext(WithUserID(req.Context(), tt.userID))
+			w := httptest.NewRecorder()
+			handler.ListPending(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status != http.StatusOK {
+				return
+			}
+			var response ModerationQueueResponse
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(response.Items) != 2 || response.NextAfter == nil || *response.NextAfter != 2 {
+				t.Errorf("expected 2 items and next_after 2, got %+v", response)
+			}
+		})
+	}
+}
+
+func TestModerationHandler_Reject(t *testing.T) {
+	mock := &mockModerationService{}
+	handler := NewModerationHandler(mock, []string{"admin"}, 5)
+
+	reject := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/moderation/answer/4/reject", strings.NewReader(body))
+		req.SetPathValue("type", "answer")
+		req.SetPathValue("id", "4")
+		req = req.WithContext(WithUserID(req.Context(), "admin"))
+		w := httptest.NewRecorder()
+		handler.Reject(w, req)
+		return w
+	}
+
+	w := reject("")
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"field":"reason"`) {
+		t.Errorf("expected 400 for reject without reason, got %d: %s", w.Code, w.Body.String())
+	}
+
+	w = reject(`{"reason":"Реклама"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var item entity.ModerationItem
+	if err := json.NewDecoder(w.Body).Decode(&item); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if item.ID != 4 || item.Status != entity.ModerationRejected || mock.reason != "Реклама" {
+		t.Errorf("expected answer 4 rejected with reason, got %+v", item)
+	}
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// NotificationHandler уведомления текущего пользователя
+type NotificationHandler struct {
+	notificationService service.NotificationService
+	requestTimeout      int
+}
+
+func NewNotificationHandler(notificationService service.NotificationService, requestTimeout int) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		requestTimeout:      requestTimeout,
+	}
+}
+
+// List отдаёт уведомления пользователя из токена, новые первыми; следующая страница — ?before=next_before
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	limit, _, ok := parsePage(w, query.Get("limit"), "")
+	if !ok {
+		return
+	}
+	var before int64
+	if value := query.Get("before"); value != "" {
+		var err error
+		before, err = strconv.ParseInt(value, 10, 64)
+		if err != nil || before < 1 {
+			sendError(w, http.StatusBadRequest, "Параметр before должен быть положительным числом")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	notifications, err := h.notificationService.List(ctx, userID, before, limit+1)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении уведомлений")
+		return
+	}
+
+	response := NotificationsResponse{Notifications: notifications}
+	if len(notifications) > limit {
+		response.Notifications = notifications[:limit]
+		response.NextBefore = &notifications[limit-1].ID
+	}
+	sendJSON(w, http.StatusOK, response)
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// PrivacyHandler выгрузка и удаление данных пользователя; доступны самому пользователю
+// и администраторам из ADMIN_USERS
+type PrivacyHandler struct {
+	privacyService service.PrivacyService
+	admins         map[string]bool
+	requestTimeout int
+}
+
+func NewPrivacyHandler(privacyService service.PrivacyService, admins []string, requestTimeout int) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+		admins:         newUserSet(admins),
+		requestTimeout: requestTimeout,
+	}
+}
+
+// ExportUserData отдаёт JSON архив данных пользователя потоком
+func (h *PrivacyHandler) ExportUserData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireSubject(w, r)
+	if !ok {
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(transferTimeout)); err != nil {
+		log.Printf("Ошибка установки дедлайна записи выгрузки: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), transferTimeout)
+	defer cancel()
+
+	out := &exportWriter{ResponseWriter: w, contentType: "application/json", filename: "user-data.json"}
+	if err := h.privacyService.ExportUserData(ctx, userID, out); err != nil {
+		if !out.started {
+			sendCustomError(w, err, "Ошибка выгрузки данных пользователя")
+			return
+		}
+		log.Printf("Ошибка выгрузки данных пользователя после начала ответа: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// EraseUserData обезличивает или удаляет ответы пользователя, убирает его из авторов вопросов
+// и удаляет его вебхуки и уведомления
+func (h *PrivacyHandler) EraseUserData(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.requireSubject(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	var req EraseUserDataRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	result, err := h.privacyService.EraseUserData(ctx, userID, req.Mode)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при удалении данных пользователя")
+		return
+	}
+	sendJSON(w, http.StatusOK, EraseUserDataResponse{
+		UserID:        result.UserID,
+		Mode:          result.Mode,
+		Answers:       result.Answers,
+		Questions:     result.Questions,
+		Webhooks:      result.Webhooks,
+		Notifications: result.Notifications,
+		Flags:         result.Flags,
+		Comments:      result.Comments,
+	})
+}
+
+// requireSubject возвращает ID пользователя из пути; 401 без токена, 403, если запрос
+// не от этого пользователя и не от администратора
+func (h *PrivacyHandler) requireSubject(w http.ResponseWriter, r *http.Request) (string, bool) {
+	requester, ok := requireUser(w, r)
+	if !ok {
+		return "", false
+	}
+	userID := r.PathValue("id")
+	if requester != userID && !h.admins[requester] {
+		sendError(w, entity.ErrForbidden.Code, entity.ErrForbidden.Message)
+		return "", false
+	}
+	return userID, true
+}
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockPrivacyService struct {
+	service.PrivacyService
+	erased string
+}
+
+func (m *mockPrivacyService) ExportUserData(ctx context.Context, userID string, w io.Writer) error {
+	_, err := io.WriteString(w, `{"user_id":"`+userID+`"}`)
+	return err
+}
+
+func (m *mockPrivacyService) EraseUserData(ctx context.Context, userID, mode string) (*entity.ErasureResult, error) {
+	if err := service.ValidateErasureMode(mode); err != nil {
+		return nil, err
+	}
+	m.erased = userID
+	return &entity.ErasureResult{UserID: userID, Mode: mode, Answers: 2}, nil
+}
+
+func TestPrivacyHandler_EraseUserData(t *testing.T) {
+	tests := []struct {
+		name      string
+		requester string
+		body      string
+		status    int
+	}{
+		{"anonymous", "", `{"mode":"delete"}`, http.StatusUnauthorized},
+		{"another user", "bob", `{"mode":"delete"}`, http.StatusForbidden},
+		{"unknown mode", "alice", `{"mode":"forget"}`, http.StatusBadRequest},
+		{"self", "alice", `{"mode":"anonymize"}`, http.StatusOK},
+		{"admin", "admin", `{"mode":"delete"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockPrivacyService{}
+			handler := NewPrivacyHandler(mock, []string{"admin"}, 5)
+
+			req := httptest.NewRequest(http.MethodPost, "/users/alice/erase", strings.NewReader(tt.body))
+			req.SetPathValue("id", "alice")
+			if tt.requester != "" {
+				req = req.WithContext(WithUserID(req.Context(), tt.requester))
+			}
+			w := httptest.NewRecorder()
+			handler.EraseUserData(w, req)
+			if w.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.status == http.StatusOK && mock.erased != "alice" {
+				t.Errorf("expected data of alice to be erased, got %q", mock.erased)
+			}
+		})
+	}
+}
+
+func TestPrivacyHandler_ExportUserData(t *testing.T) {
+	handler := NewPrivacyHandler(&mockPrivacyService{}, nil, 5)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/alice/data-export", nil)
+	req.SetPathValue("id", "alice")
+	req = req.WithContext(WithUserID(req.Context(), "alice"))
+	w := httptest.NewRecorder()
+	handler.ExportUserData(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if w.Header().Get("Content-Type") != "application/json" || !strings.Contains(w.Header().Get("Content-Disposition"), "user-data.json") {
+		t.Errorf("unexpected headers: %v", w.Header())
+	}
+	if w.Body.String() != `{"user_id":"alice"}` {
+		t.Errorf("unexpected body: %s", w.Body.String())
+	}
+}
@@ -0,0 +1,162 @@
+package api
+
+import (
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/ratelimit"
+)
+
+const (
+	RouteClassRead  = "read"
+	RouteClassWrite = "write"
+)
+
+type RateLimiter struct {
+	*clientIPResolver
+	store  ratelimit.Store
+	limits map[string]ratelimit.Limit
+}
+
+// NewRateLimiter создаёт лимитер с отдельными лимитами на класс маршрута (read/write).
+// trustedProxies — CIDR прокси, которым можно доверять заголовок X-Forwarded-For
+func NewRateLimiter(store ratelimit.Store, limits map[string]ratelimit.Limit, trustedProxies []string) (*RateLimiter, error) {
+	resolver, err := newClientIPResolver(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
+	return &RateLimiter{
+		clientIPResolver: resolver,
+		store:            store,
+		limits:           limits,
+	}, nil
+}
+
+// clientIPResolver определяет IP клиента; X-Forwarded-For учитывается только от доверенных прокси
+type clientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+func newClientIPResolver(trustedProxies []string) (*clientIPResolver, error) {
+	nets := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, cidr := range trustedProxies {
+		if !strings.Contains(cidr, "/") {
+			if strings.Contains(cidr, ":") {
+				cidr += "/128"
+			} else {
+				cidr += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ipNet)
+	}
+	return &clientIPResolver{trustedProxies: nets}, nil
+}
+
+func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := routeClass(r)
+		limit, ok := rl.limits[class]
+		if !ok || limit.Requests <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		result, err := rl.store.Take(r.Context(), class+":"+rl.clientKey(r), limit)
+		if err != nil {
+			// при недоступном хранилище лимитов не блокируем запросы
+			log.Printf("Ошибка rate limit хранилища: %v", err)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			sendError(w, entity.ErrTooManyRequests.Code, entity.ErrTooManyRequests.Message)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// clientKey возвращает ключ лимита: пользователь, если он аутентифицирован, иначе IP клиента
+func (rl *RateLimiter) clientKey(r *http.Request) string {
+	if userID, ok := UserIDFromContext(r.Context()); ok {
+		return "user:" + userID
+	}
+	return "ip:" + rl.clientIP(r)
+}
+
+// clientIP берёт IP из X-Forwarded-For только если запрос пришёл от доверенного прокси.
+// Цепочка просматривается справа налево до первого недоверенного адреса
+func (c *clientIPResolver) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if !c.isTrusted(host) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(forwarded[i])
+		if ip == "" {
+			continue
+		}
+		if !c.isTrusted(ip) {
+			return ip
+		}
+		host = ip
+	}
+	return host
+}
+
+func (c *clientIPResolver) isTrusted(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, ipNet := range c.trustedProxies {
+		if ipNet.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+// routeClass относит запрос к классу лимитов; пробы здоровья не лимитируются.
+// POST /answers/batch-get только читает, поэтому считается чтением
+func routeClass(r *http.Request) string {
+	if r.URL.Path == "/healthz" || r.URL.Path == "/readyz" {
+		return ""
+	}
+	if r.URL.Path == "/answers/batch-get" {
+		return RouteClassRead
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return RouteClassRead
+	default:
+		return RouteClassWrite
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
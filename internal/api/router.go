@@ -4,29 +4,124 @@ import (
 	"net/http"
 )
 
+// Handlers набор обработчиков для Router. API и Health обязательны,
+// остальные маршруты регистрируются, только если обработчик задан
+type Handlers struct {
+	API           *Handler
+	Health        *HealthHandler
+	Idempotency   *Idempotency
+	Events        *EventsHandler
+	WebSocket     *WebSocketHandler
+	Webhooks      *WebhookHandler
+	Transfer      *TransferHandler
+	GraphQL       *GraphQLHandler
+	Admin         *AdminHandler
+	Privacy       *PrivacyHandler
+	Moderation    *ModerationHandler
+	Notifications *NotificationHandler
+	Flags         *FlagHandler
+	Comments      *CommentHandler
+}
+
 type Router struct {
-	mux     *http.ServeMux
-	handler *Handler
+	mux      *http.ServeMux
+	handlers Handlers
 }
 
-func NewRouter(handler *Handler) *Router {
+func NewRouter(handlers Handlers) *Router {
 	return &Router{
-		mux:     http.NewServeMux(),
-		handler: handler,
+		mux:      http.NewServeMux(),
+		handlers: handlers,
 	}
 }
 
 func (router *Router) Setup() *http.ServeMux {
-	router.mux.HandleFunc("GET /", router.handler.HealthCheck)
+	h := router.handlers
+
+	router.mux.HandleFunc("/", h.API.NotFound)
+
+	router.mux.HandleFunc("GET /healthz", h.Health.Liveness)
+	router.mux.HandleFunc("GET /readyz", h.Health.Readiness)
+
+	router.mux.HandleFunc("GET /questions", h.API.GetQuestions)
+	router.mux.HandleFunc("GET /questions/", h.API.GetQuestions)
+	router.mux.Handle("POST /questions/", router.idempotent(h.API.CreateQuestion))
+	router.mux.HandleFunc("GET /questions/{id}", h.API.GetQuestion)
+	router.mux.HandleFunc("DELETE /questions/{id}", h.API.DeleteQuestion)
+	if h.Events != nil {
+		router.mux.HandleFunc("GET /questions/{id}/events", h.Events.QuestionEvents)
+	}
+
+	router.mux.Handle("POST /questions/{id}/answers/", router.idempotent(h.API.CreateAnswer))
+	router.mux.HandleFunc("POST /answers/batch-get", h.API.BatchGetAnswers)
+	router.mux.HandleFunc("GET /answers/{id}", h.API.GetAnswer)
+	router.mux.HandleFunc("DELETE /answers/{id}", h.API.DeleteAnswer)
+
+	if h.WebSocket != nil {
+		router.mux.HandleFunc("GET /ws", h.WebSocket.Feed)
+	}
+
+	if h.Webhooks != nil {
+		router.mux.HandleFunc("POST /webhooks", h.Webhooks.CreateWebhook)
+		router.mux.HandleFunc("GET /webhooks", h.Webhooks.GetWebhooks)
+		router.mux.HandleFunc("GET /webhooks/{id}", h.Webhooks.GetWebhook)
+		router.mux.HandleFunc("DELETE /webhooks/{id}", h.Webhooks.DeleteWebhook)
+		router.mux.HandleFunc("GET /webhooks/{id}/deliveries", h.Webhooks.GetDeliveries)
+		router.mux.HandleFunc("POST /webhooks/{id}/deliveries/{deliveryID}/retry", h.Webhooks.RetryDelivery)
+	}
+
+	if h.Transfer != nil {
+		router.mux.HandleFunc("GET /export", h.Transfer.Export)
+		router.mux.HandleFunc("POST /import", h.Transfer.Import)
+	}
+
+	if h.GraphQL != nil {
+		router.mux.HandleFunc("POST /graphql", h.GraphQL.Query)
+	}
+
+	if h.Admin != nil {
+		router.mux.HandleFunc("POST /admin/answers/bulk-delete", h.Admin.BulkDeleteAnswers)
+		router.mux.HandleFunc("GET /admin/jobs/{id}", h.Admin.GetJob)
+		router.mux.HandleFunc("GET /admin/audit", h.Admin.ListAudit)
+	}
+
+	if h.Privacy != nil {
+		router.mux.HandleFunc("GET /users/{id}/data-export", h.Privacy.ExportUserData)
+		router.mux.HandleFunc("POST /users/{id}/erase", h.Privacy.EraseUserData)
+	}
+
+	if h.Moderation != nil {
+		router.mux.HandleFunc("GET /admin/moderation", h.Moderation.ListPending)
+		router.mux.HandleFunc("POST /admin/moderation/{type}/{id}/approve", h.Moderation.Approve)
+		router.mux.HandleFunc("POST /admin/moderation/{type}/{id}/reject", h.Moderation.Reject)
+	}
 
-	router.mux.HandleFunc("GET /questions/", router.handler.GetQuestions)
-	router.mux.HandleFunc("POST /questions/", router.handler.CreateQuestion)
-	router.mux.HandleFunc("GET /questions/{id}", router.handler.GetQuestion)
-	router.mux.HandleFunc("DELETE /questions/{id}", router.handler.DeleteQuestion)
+	if h.Notifications != nil {
+		router.mux.HandleFunc("GET /notifications", h.Notifications.List)
+	}
+
+	if h.Flags != nil {
+		router.mux.HandleFunc("POST /questions/{id}/flags", h.Flags.FlagQuestion)
+		router.mux.HandleFunc("POST /answers/{id}/flags", h.Flags.FlagAnswer)
+		router.mux.HandleFunc("GET /admin/flags", h.Flags.ListFlagged)
+		router.mux.HandleFunc("POST /admin/flags/{type}/{id}/resolve", h.Flags.Resolve)
+	}
 
-	router.mux.HandleFunc("POST /questions/{id}/answers/", router.handler.CreateAnswer)
-	router.mux.HandleFunc("GET /answers/{id}", router.handler.GetAnswer)
-	router.mux.HandleFunc("DELETE /answers/{id}", router.handler.DeleteAnswer)
+	if h.Comments != nil {
+		router.mux.Handle("POST /answers/{id}/comments", router.idempotent(h.Comments.CreateAnswerComment))
+		router.mux.HandleFunc("GET /answers/{id}/comments", h.Comments.GetAnswerComments)
+		router.mux.Handle("POST /questions/{id}/comments", router.idempotent(h.Comments.CreateQuestionComment))
+		router.mux.HandleFunc("GET /questions/{id}/comments", h.Comments.GetQuestionComments)
+		router.mux.HandleFunc("PATCH /comments/{id}", h.Comments.Update)
+		router.mux.HandleFunc("DELETE /comments/{id}", h.Comments.Delete)
+	}
 
 	return router.mux
 }
+
+func (router *Router) idempotent(handler http.HandlerFunc) http.Handler {
+	if router.handlers.Idempotency == nil {
+		return handler
+	}
+	return router.handlers.Idempotency.Wrap(handler)
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// transferTimeout ограничивает выгрузку и загрузку, которые дольше обычных таймаутов сервера
+const transferTimeout = 10 * time.Minute
+
+type TransferHandler struct {
+	transferService service.TransferService
+	admins          map[string]bool
+	maxImportBytes  int64
+}
+
+func NewTransferHandler(transferService service.TransferService, admins []string, maxImportBytes int64) *TransferHandler {
+	return &TransferHandler{
+		transferService: transferService,
+		admins:          newUserSet(admins),
+		maxImportBytes:  maxImportBytes,
+	}
+}
+
+// Export отдаёт опубликованные вопросы с ответами потоком: ?format=jsonl (по умолчанию) или csv
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = service.TransferFormatJSONL
+	}
+	if err := service.ValidateTransferFormat(format); err != nil {
+		sendCustomError(w, err, "Ошибка выгрузки")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(transferTimeout)); err != nil {
+		log.Printf("Ошибка установки дедлайна записи выгрузки: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), transferTimeout)
+	defer cancel()
+
+	contentType, filename := "application/x-ndjson", "questions.jsonl"
+	if format == service.TransferFormatCSV {
+		contentType, filename = "text/csv; charset=utf-8", "questions.csv"
+	}
+	out := &exportWriter{ResponseWriter: w, contentType: contentType, filename: filename}
+	if err := h.transferService.Export(ctx, format, out); err != nil {
+		if !out.started {
+			sendCustomError(w, err, "Ошибка выгрузки")
+			return
+		}
+		// статус уже отправлен: обрываем соединение, чтобы клиент не принял неполную выгрузку за полную
+		log.Printf("Ошибка выгрузки после начала ответа: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// exportWriter выставляет заголовки при первой записи, чтобы ошибку до начала выгрузки можно было отдать как JSON
+type exportWriter struct {
+	http.ResponseWriter
+	contentType string
+	filename    string
+	started     bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.Header().Set("Content-Type", w.contentType)
+		w.Header().Set("Content-Disposition", `attachment; filename="`+w.filename+`"`)
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Import загружает вопросы с ответами из тела запроса; доступен только администраторам, так как ответы
+// сохраняются с user_id из файла. Параметры: format=jsonl|csv, dry_run=true — проверить без сохранения,
+// mode=upsert — сопоставлять вопросы по тексту
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	if _, ok := requireAdmin(w, r, h.admins); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = service.TransferFormatJSONL
+	}
+	if err := service.ValidateTransferFormat(format); err != nil {
+		sendCustomError(w, err, "Ошибка импорта")
+		return
+	}
+
+	opts := service.ImportOptions{Upsert: query.Get("mode") == "upsert"}
+	if value := query.Get("dry_run"); value != "" {
+		dryRun, err := strconv.ParseBool(value)
+		if err != nil {
+			sendError(w, http.StatusBadRequest, "Некорректное значение dry_run")
+			return
+		}
+		opts.DryRun = dryRun
+	}
+	if mode := query.Get("mode"); mode != "" && mode != "upsert" && mode != "insert" {
+		sendError(w, http.StatusBadRequest, "Некорректное значение mode: допустимы insert и upsert")
+		return
+	}
+
+	rc := http.NewResponseController(w)
+	deadline := time.Now().Add(transferTimeout)
+	if err := rc.SetReadDeadline(deadline); err != nil {
+		log.Printf("Ошибка установки дедлайна чтения импорта: %v", err)
+	}
+	if err := rc.SetWriteDeadline(deadline); err != nil {
+		log.Printf("Ошибка установки дедлайна записи импорта: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), transferTimeout)
+	defer cancel()
+
+	body := http.MaxBytesReader(w, r.Body, h.maxImportBytes)
+	report, err := h.transferService.Import(ctx, format, body, opts)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			sendError(w, http.StatusRequestEntityTooLarge, "Размер файла импорта превышает "+strconv.FormatInt(maxBytesErr.Limit, 10)+" байт")
+			return
+		}
+		sendCustomError(w, err, "Ошибка импорта")
+		return
+	}
+
+	sendJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockTransferService struct {
+	importOpts service.ImportOptions
+	imported   string
+}
+
+func (m *mockTransferService) Export(ctx context.Context, format string, w io.Writer) error {
+	_, err := io.WriteString(w, `{"text":"What is Go?","answers":[]}`+"\n")
+	return err
+}
+
+func (m *mockTransferService) Import(ctx context.Context, format string, r io.Reader, opts service.ImportOptions) (*service.ImportReport, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	m.imported = string(data)
+	m.importOpts = opts
+	return &service.ImportReport{DryRun: opts.DryRun, Records: 1}, nil
+}
+
+func TestTransferHandler_Export(t *testing.T) {
+	handler := NewTransferHandler(&mockTransferService{}, []string{"admin"}, 1024)
+
+	w := httptest.NewRecorder()
+	handler.Export(w, httptest.NewRequest(http.MethodGet, "/export?format=xml", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.Export(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+		t.Errorf("expected Content-Type application/x-ndjson, got %s", ct)
+	}
+}
+
+func TestTransferHandler_Import(t *testing.T) {
+	mock := &mockTransferService{}
+	handler := NewTransferHandler(mock, []string{"admin"}, 16)
+
+	w := httptest.NewRecorder()
+	handler.Import(w, httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("{}")))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/import", strings.NewReader("{}"))
+	req = req.WithContext(WithUserID(req.Context(), "alice"))
+	w = httptest.NewRecorder()
+	handler.Import(w, req)
+	if w.Code != http.StatusForbidden || mock.imported != "" {
+		t.Errorf("expected status %d without import, got %d", http.StatusForbidden, w.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import?format=csv&dry_run=true&mode=upsert", strings.NewReader("question_text\n"))
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w = httptest.NewRecorder()
+	handler.Import(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if !mock.importOpts.DryRun || !mock.importOpts.Upsert {
+		t.Errorf("expected dry-run upsert options, got %+v", mock.importOpts)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/import", strings.NewReader(strings.Repeat("x", 32)))
+	req = req.WithContext(WithUserID(req.Context(), "admin"))
+	w = httptest.NewRecorder()
+	handler.Import(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected status %d, got %d", http.StatusRequestEntityTooLarge, w.Code)
+	}
+}
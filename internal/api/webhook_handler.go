@@ -0,0 +1,228 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// WebhookHandler управляет подписками на вебхуки. Все маршруты требуют авторизации,
+// пользователь видит только свои вебхуки
+type WebhookHandler struct {
+	webhookService service.WebhookService
+	requestTimeout int
+}
+
+func NewWebhookHandler(webhookService service.WebhookService, requestTimeout int) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+		requestTimeout: requestTimeout,
+	}
+}
+
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Ошибка парсинга JSON: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат JSON")
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(ctx, ownerID, req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при создании вебхука")
+		return
+	}
+
+	// секрет показывается один раз, при создании
+	response := toWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	sendJSON(w, http.StatusCreated, response)
+}
+
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	webhooks, err := h.webhookService.GetWebhooks(ctx, ownerID)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении вебхуков")
+		return
+	}
+
+	responses := make([]WebhookResponse, len(webhooks))
+	for i := range webhooks {
+		responses[i] = toWebhookResponse(&webhooks[i])
+	}
+	sendJSON(w, http.StatusOK, WebhooksListResponse{Webhooks: responses})
+}
+
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	webhook, err := h.webhookService.GetWebhook(ctx, ownerID, id)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении вебхука")
+		return
+	}
+	sendJSON(w, http.StatusOK, toWebhookResponse(webhook))
+}
+
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	if err := h.webhookService.DeleteWebhook(ctx, ownerID, id); err != nil {
+		sendCustomError(w, err, "Ошибка при удалении вебхука")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetDeliveries отдаёт журнал доставок; ?status=dead — dead-letter список
+func (h *WebhookHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", entity.WebhookDeliveryPending, entity.WebhookDeliverySucceeded, entity.WebhookDeliveryDead:
+	default:
+		sendError(w, http.StatusBadRequest, "Некорректный статус доставки")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	deliveries, err := h.webhookService.GetDeliveries(ctx, ownerID, id, status)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при получении доставок вебхука")
+		return
+	}
+
+	responses := make([]WebhookDeliveryResponse, len(deliveries))
+	for i := range deliveries {
+		responses[i] = toWebhookDeliveryResponse(&deliveries[i])
+	}
+	sendJSON(w, http.StatusOK, WebhookDeliveriesListResponse{Deliveries: responses})
+}
+
+// RetryDelivery возвращает доставку в очередь, например после исправления получателя
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	ownerID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, err := strconv.ParseInt(r.PathValue("deliveryID"), 10, 64)
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+
+	delivery, err := h.webhookService.RetryDelivery(ctx, ownerID, id, deliveryID)
+	if err != nil {
+		sendCustomError(w, err, "Ошибка при повторе доставки вебхука")
+		return
+	}
+	sendJSON(w, http.StatusAccepted, toWebhookDeliveryResponse(delivery))
+}
+
+func toWebhookResponse(webhook *entity.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:         webhook.ID,
+		URL:        webhook.URL,
+		EventTypes: webhook.EventTypes,
+		Active:     webhook.Active,
+		CreatedAt:  webhook.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(delivery *entity.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		EventID:        delivery.EventID,
+		EventType:      delivery.EventType,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		LastAttemptAt:  delivery.LastAttemptAt,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+	}
+	if delivery.Status == entity.WebhookDeliveryPending {
+		response.NextAttemptAt = &delivery.NextAttemptAt
+	}
+	return response
+}
+
+// requireUser отвечает 401, если запрос без токена
+func requireUser(w http.ResponseWriter, r *http.Request) (string, bool) {
+	userID, ok := UserIDFromContext(r.Context())
+	if !ok {
+		sendError(w, entity.ErrUnauthorized.Code, entity.ErrUnauthorized.Message)
+	}
+	return userID, ok
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		log.Printf("Ошибка парсинга ID: %v", err)
+		sendError(w, http.StatusBadRequest, "Некорректный формат ID")
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,282 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	wsTopicQuestions      = "questions"
+	wsTopicQuestionPrefix = "question:"
+	wsMaxTopics           = 50
+	wsMaxMessageSize      = 4096
+	wsWriteTimeout        = 10 * time.Second
+	wsPongTimeout         = 60 * time.Second
+	wsPingInterval        = 50 * time.Second
+	wsSubscriptionBuffer  = 256
+)
+
+// WSClientMessage команда клиента: {"type": "subscribe", "topic": "question:42"}.
+// Топик "questions" — лента новых вопросов, "question:{id}" — ответы на вопрос
+type WSClientMessage struct {
+	Type  string `json:"type"`
+	Topic string `json:"topic"`
+}
+
+type WSServerMessage struct {
+	Type  string        `json:"type"`
+	Topic string        `json:"topic,omitempty"`
+	Event *events.Event `json:"event,omitempty"`
+	Error string        `json:"error,omitempty"`
+}
+
+type WebSocketHandler struct {
+	broker          *events.Broker
+	questionService service.QuestionService
+	upgrader        websocket.Upgrader
+	requestTimeout  int
+	shutdown        chan struct{}
+
+	// mu упорядочивает регистрацию соединений и Close: после Close новые не добавляются в conns,
+	// иначе Add мог бы выполниться параллельно с Wait
+	mu     sync.Mutex
+	closed bool
+	conns  sync.WaitGroup
+}
+
+// NewWebSocketHandler создаёт обработчик ленты событий. allowedOrigins пустой —
+// разрешены только запросы с того же origin
+func NewWebSocketHandler(broker *events.Broker, questionService service.QuestionService, allowedOrigins []string, requestTimeout int) *WebSocketHandler {
+	h := &WebSocketHandler{
+		broker:          broker,
+		questionService: questionService,
+		requestTimeout:  requestTimeout,
+		shutdown:        make(chan struct{}),
+	}
+	h.upgrader = websocket.Upgrader{ReadBufferSize: 1024, WriteBufferSize: 1024}
+	if len(allowedOrigins) > 0 {
+		h.upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			for _, allowed := range allowedOrigins {
+				if allowed == "*" || strings.EqualFold(origin, allowed) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+	return h
+}
+
+// Close закрывает все соединения с кодом 1001 и ждёт их завершения.
+// Соединения после Upgrade не отслеживаются server.Shutdown
+func (h *WebSocketHandler) Close() {
+	h.mu.Lock()
+	if !h.closed {
+		h.closed = true
+		close(h.shutdown)
+	}
+	h.mu.Unlock()
+	h.conns.Wait()
+}
+
+// track регистрирует соединение; false, если обработчик уже закрыт
+func (h *WebSocketHandler) track() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return false
+	}
+	h.conns.Add(1)
+	return true
+}
+
+// wsConnection хранит набор топиков, на которые подписан клиент
+type wsConnection struct {
+	mu     sync.RWMutex
+	topics map[string]struct{}
+}
+
+func (c *wsConnection) matches(e events.Event) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if e.Type == events.TypeQuestionCreated {
+		_, ok := c.topics[wsTopicQuestions]
+		return ok
+	}
+	_, ok := c.topics[wsTopicQuestionPrefix+strconv.Itoa(e.QuestionID)]
+	return ok
+}
+
+func (h *WebSocketHandler) Feed(w http.ResponseWriter, r *http.Request) {
+	userID, ok := requireUser(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.track() {
+		sendError(w, http.StatusServiceUnavailable, "Сервер останавливается")
+		return
+	}
+	defer h.conns.Done()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade сам отвечает клиенту ошибкой
+		log.Printf("Ошибка WebSocket handshake: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	state := &wsConnection{topics: make(map[string]struct{})}
+	sub, _, _ := h.broker.Subscribe(state.matches, wsSubscriptionBuffer, "")
+	defer sub.Close()
+
+	replies := make(chan WSServerMessage, 16)
+	readerDone := make(chan struct{})
+	writerDone := make(chan struct{})
+	defer close(writerDone)
+	go func() {
+		defer close(readerDone)
+		h.readLoop(r.Context(), conn, state, replies, writerDone)
+	}()
+
+	log.Printf("WebSocket подключение пользователя %s", userID)
+	closeCode, closeText := h.writeLoop(conn, sub, replies, readerDone)
+
+	deadline := time.Now().Add(wsWriteTimeout)
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(closeCode, closeText), deadline)
+	log.Printf("WebSocket отключение пользователя %s: %s", userID, closeText)
+}
+
+func (h *WebSocketHandler) writeLoop(conn *websocket.Conn, sub *events.Subscription, replies <-chan WSServerMessage, readerDone <-chan struct{}) (int, string) {
+	ping := time.NewTicker(wsPingInterval)
+	defer ping.Stop()
+
+	write := func(msg WSServerMessage) bool {
+		_ = conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+		return conn.WriteJSON(msg) == nil
+	}
+
+	for {
+		select {
+		case <-h.shutdown:
+			return websocket.CloseGoingAway, "сервер останавливается"
+		case <-readerDone:
+			return websocket.CloseNormalClosure, "соединение закрыто клиентом"
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				return websocket.CloseAbnormalClosure, "клиент не отвечает"
+			}
+		case reply := <-replies:
+			if !write(reply) {
+				return websocket.CloseAbnormalClosure, "ошибка записи"
+			}
+		case event, ok := <-sub.C:
+			if !ok {
+				return websocket.CloseTryAgainLater, "клиент не успевает читать события"
+			}
+			topic := wsTopicQuestionPrefix + strconv.Itoa(event.QuestionID)
+			if event.Type == events.TypeQuestionCreated {
+				topic = wsTopicQuestions
+			}
+			if !write(WSServerMessage{Type: "event", Topic: topic, Event: &event}) {
+				return websocket.CloseAbnormalClosure, "ошибка записи"
+			}
+		}
+	}
+}
+
+func (h *WebSocketHandler) readLoop(ctx context.Context, conn *websocket.Conn, state *wsConnection, replies chan<- WSServerMessage, writerDone <-chan struct{}) {
+	conn.SetReadLimit(wsMaxMessageSize)
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+
+		var reply WSServerMessage
+		var msg WSClientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			reply = WSServerMessage{Type: "error", Error: "Некорректный формат JSON"}
+		} else {
+			reply = h.handleCommand(ctx, state, msg)
+		}
+
+		select {
+		case replies <- reply:
+		case <-writerDone:
+			return
+		}
+	}
+}
+
+func (h *WebSocketHandler) handleCommand(ctx context.Context, state *wsConnection, msg WSClientMessage) WSServerMessage {
+	switch msg.Type {
+	case "subscribe":
+		if errMsg := h.validateTopic(ctx, msg.Topic); errMsg != "" {
+			return WSServerMessage{Type: "error", Topic: msg.Topic, Error: errMsg}
+		}
+
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		if len(state.topics) >= wsMaxTopics {
+			return WSServerMessage{Type: "error", Topic: msg.Topic, Error: fmt.Sprintf("Нельзя подписаться больше чем на %d топиков", wsMaxTopics)}
+		}
+		state.topics[msg.Topic] = struct{}{}
+		return WSServerMessage{Type: "subscribed", Topic: msg.Topic}
+	case "unsubscribe":
+		state.mu.Lock()
+		defer state.mu.Unlock()
+		delete(state.topics, msg.Topic)
+		return WSServerMessage{Type: "unsubscribed", Topic: msg.Topic}
+	default:
+		return WSServerMessage{Type: "error", Topic: msg.Topic, Error: "Неизвестный тип команды"}
+	}
+}
+
+// validateTopic возвращает текст ошибки для клиента или пустую строку
+func (h *WebSocketHandler) validateTopic(ctx context.Context, topic string) string {
+	if topic == wsTopicQuestions {
+		return ""
+	}
+
+	idStr, found := strings.CutPrefix(topic, wsTopicQuestionPrefix)
+	if !found {
+		return "Неизвестный топик"
+	}
+	questionID, err := strconv.Atoi(idStr)
+	if err != nil {
+		return "Некорректный формат ID"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(h.requestTimeout)*time.Second)
+	defer cancel()
+	if _, err := h.questionService.GetQuestion(ctx, questionID); err != nil {
+		if customErr, ok := err.(entity.CustomError); ok {
+			return customErr.Message
+		}
+		return entity.ErrDatabaseQuery.Message
+	}
+	return ""
+}
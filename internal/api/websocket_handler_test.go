@@ -0,0 +1,118 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+func TestWebSocket_SubscribeAndReceiveEvents(t *testing.T) {
+	broker := events.NewBroker(10)
+	mockQService := &mockQuestionService{
+		getQuestion: func(ctx context.Context, id int) (*entity.Question, error) {
+			if id == 1 {
+				return &entity.Question{ID: 1, Text: "What is Go?"}, nil
+			}
+			return nil, entity.ErrQuestionNotFound
+		},
+	}
+
+	wsHandler := NewWebSocketHandler(broker, mockQService, nil, 5)
+	authService := service.NewStaticTokenAuthService(map[string]string{"secret": "user1"})
+	server := httptest.NewServer(Chain(http.HandlerFunc(wsHandler.Feed), AuthMiddleware(authService)))
+	defer server.Close()
+	defer wsHandler.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http")
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatalf("expected handshake without token to fail")
+	}
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, resp.StatusCode)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(url+"?access_token=secret", nil)
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	if err := conn.WriteJSON(WSClientMessage{Type: "subscribe", Topic: "question:2"}); err != nil {
+		t.Fatalf("failed to send subscribe: %v", err)
+	}
+	var reply WSServerMessage
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("failed to read reply: %v", err)
+	}
+	if reply.Type != "error" || reply.Error != "Вопрос не найден" {
+		t.Errorf("expected 'Вопрос не найден' error, got %+v", reply)
+	}
+
+	if err := conn.WriteJSON(WSClientMessage{Type: "subscribe", Topic: "question:1"}); err != nil {
+		t.Fatalf("failed to send subscribe: %v", err)
+	}
+	if err := conn.ReadJSON(&reply); err != nil || reply.Type != "subscribed" {
+		t.Fatalf("expected subscribed reply, got %+v (err: %v)", reply, err)
+	}
+
+	broker.Dispatch(events.Event{ID: "1", Type: events.TypeAnswerCreated, QuestionID: 2})
+	broker.Dispatch(events.Event{ID: "2", Type: events.TypeAnswerCreated, QuestionID: 1})
+
+	if err := conn.ReadJSON(&reply); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if reply.Type != "event" || reply.Topic != "question:1" || reply.Event == nil || reply.Event.ID != "2" {
+		t.Errorf("expected event '2' for question:1, got %+v", reply)
+	}
+}
+
+func TestWebSocket_CloseRacesWithNewConnections(t *testing.T) {
+	wsHandler := NewWebSocketHandler(events.NewBroker(10), &mockQuestionService{}, nil, 5)
+	authService := service.NewStaticTokenAuthService(map[string]string{"secret": "user1"})
+	server := httptest.NewServer(Chain(http.HandlerFunc(wsHandler.Feed), AuthMiddleware(authService)))
+	defer server.Close()
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "?access_token=secret"
+
+	// соединения открываются, пока идёт Close: Close должен дождаться успевших и не пропустить новых (go test -race)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}()
+	}
+	wsHandler.Close()
+	wg.Wait()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil || resp == nil {
+		t.Fatalf("expected handshake after Close to fail, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d after Close, got %d", http.StatusServiceUnavailable, resp.StatusCode)
+	}
+}
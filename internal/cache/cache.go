@@ -0,0 +1,16 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Cache хранит сериализованные значения. LRU работает внутри процесса,
+// для нескольких реплик можно подключить реализацию поверх общего хранилища (Redis, Memcached)
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	Delete(ctx context.Context, keys ...string) error
+}
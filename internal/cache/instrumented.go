@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
+
+// Instrumented считает попадания и промахи кэша и публикует их как OpenTelemetry метрики
+type Instrumented struct {
+	Cache
+	hits      atomic.Int64
+	misses    atomic.Int64
+	hitsCtr   metric.Int64Counter
+	missesCtr metric.Int64Counter
+	attrs     metric.MeasurementOption
+}
+
+func NewInstrumented(c Cache, name string) *Instrumented {
+	meter := otel.Meter("github.com/andrey-samosuk/answer-questions/internal/cache")
+	hitsCtr, _ := meter.Int64Counter("cache.hits", metric.WithDescription("Количество попаданий в кэш"))
+	missesCtr, _ := meter.Int64Counter("cache.misses", metric.WithDescription("Количество промахов кэша"))
+
+	return &Instrumented{
+		Cache:     c,
+		hitsCtr:   hitsCtr,
+		missesCtr: missesCtr,
+		attrs:     metric.WithAttributes(attribute.String("cache.name", name)),
+	}
+}
+
+func (c *Instrumented) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	if ok {
+		c.hits.Add(1)
+		c.hitsCtr.Add(ctx, 1, c.attrs)
+	} else {
+		c.misses.Add(1)
+		c.missesCtr.Add(ctx, 1, c.attrs)
+	}
+	return value, ok, err
+}
+
+func (c *Instrumented) Stats() Stats {
+	return Stats{Hits: c.hits.Load(), Misses: c.misses.Load()}
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLRU_TTLExpiry(t *testing.T) {
+	c := NewLRU(10)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if err := c.Set(ctx, "q:1", []byte("a"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if value, ok, _ := c.Get(ctx, "q:1"); !ok || string(value) != "a" {
+		t.Errorf("expected value before TTL, got %q, %v", value, ok)
+	}
+
+	// повторная запись продлевает TTL
+	if err := c.Set(ctx, "q:1", []byte("b"), time.Minute); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(59 * time.Second)
+	if value, ok, _ := c.Get(ctx, "q:1"); !ok || string(value) != "b" {
+		t.Errorf("expected overwritten value with extended TTL, got %q, %v", value, ok)
+	}
+
+	now = now.Add(time.Second)
+	if _, ok, _ := c.Get(ctx, "q:1"); ok {
+		t.Errorf("expected value to expire at TTL")
+	}
+	if c.Len() != 0 {
+		t.Errorf("expected expired entry to be removed, got %d entries", c.Len())
+	}
+}
+
+func TestLRU_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRU(3)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(ctx, key, []byte(key), time.Minute)
+	}
+	// чтение и перезапись поднимают ключ, вытесняется самый давний
+	c.Get(ctx, "a")
+	c.Set(ctx, "b", []byte("b2"), time.Minute)
+	c.Set(ctx, "d", []byte("d"), time.Minute)
+	c.Set(ctx, "e", []byte("e"), time.Minute)
+
+	for key, want := range map[string]bool{"a": false, "b": true, "c": false, "d": true, "e": true} {
+		if _, ok, _ := c.Get(ctx, key); ok != want {
+			t.Errorf("expected %s present=%v, got %v", key, want, ok)
+		}
+	}
+	if c.Len() != 3 {
+		t.Errorf("expected 3 entries, got %d", c.Len())
+	}
+
+	c.Delete(ctx, "b", "missing")
+	if _, ok, _ := c.Get(ctx, "b"); ok || c.Len() != 2 {
+		t.Errorf("expected b to be deleted, got %d entries", c.Len())
+	}
+}
+
+func TestLRU_ConcurrentAccess(t *testing.T) {
+	c := NewLRU(16)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 500; j++ {
+				key := fmt.Sprintf("k%d", (i*j)%32)
+				c.Set(ctx, key, []byte(key), time.Minute)
+				if value, ok, _ := c.Get(ctx, key); ok && string(value) != key {
+					t.Errorf("expected %s, got %s", key, value)
+				}
+				c.Delete(ctx, key)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if c.Len() > 16 {
+		t.Errorf("expected at most 16 entries, got %d", c.Len())
+	}
+}
@@ -5,11 +5,25 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
-	Database DatabaseConfig
-	Server   ServerConfig
+	Database    DatabaseConfig
+	Health      HealthConfig
+	Server      ServerConfig
+	Tracing     TracingConfig
+	RateLimit   RateLimitConfig
+	Idempotency IdempotencyConfig
+	Cache       CacheConfig
+	Events      EventsConfig
+	Auth        AuthConfig
+	Webhooks    WebhooksConfig
+	Outbox      OutboxConfig
+	Transfer    TransferConfig
+	GraphQL     GraphQLConfig
+	Admin       AdminConfig
+	Moderation  ModerationConfig
 }
 
 type DatabaseConfig struct {
@@ -20,13 +34,114 @@ type DatabaseConfig struct {
 	Name     string
 }
 
+type HealthConfig struct {
+	CheckTimeout  int
+	MigrationsDir string
+}
+
+// ServerConfig настройки HTTP и gRPC серверов; пустой GRPCPort отключает gRPC
 type ServerConfig struct {
 	HTTPPort        string
+	GRPCPort        string
 	ReadTimeout     int
 	WriteTimeout    int
 	IdleTimeout     int
 	ShutdownTimeout int
 	RequestTimeout  int
+	DrainDelay      int
+}
+
+// TracingConfig настройки OpenTelemetry. Exporter: none, otlp, stdout или file
+type TracingConfig struct {
+	Exporter     string
+	ServiceName  string
+	OTLPEndpoint string
+	OTLPInsecure bool
+	FilePath     string
+	SampleRatio  float64
+}
+
+// RateLimitConfig лимиты в запросах в минуту на клиента; 0 отключает лимит для класса
+type RateLimitConfig struct {
+	ReadPerMinute  int
+	ReadBurst      int
+	WritePerMinute int
+	WriteBurst     int
+	TrustedProxies []string
+}
+
+type IdempotencyConfig struct {
+	TTLHours             int
+	CleanupIntervalHours int
+}
+
+type CacheConfig struct {
+	Enabled    bool
+	Size       int
+	TTLSeconds int
+}
+
+// EventsConfig Backend: postgres (LISTEN/NOTIFY между репликами) или memory (одна реплика)
+type EventsConfig struct {
+	Backend          string
+	ReplayBufferSize int
+	HeartbeatSeconds int
+}
+
+// AuthConfig Tokens — пары token:user_id из AUTH_TOKENS
+type AuthConfig struct {
+	Tokens           map[string]string
+	WSAllowedOrigins []string
+}
+
+// WebhooksConfig повторы доставки: задержка удваивается от BackoffBaseSeconds до BackoffMaxSeconds,
+// после MaxAttempts неудач доставка попадает в dead-letter
+type WebhooksConfig struct {
+	MaxAttempts         int
+	BackoffBaseSeconds  int
+	BackoffMaxSeconds   int
+	TimeoutSeconds      int
+	PollIntervalSeconds int
+	BatchSize           int
+}
+
+// OutboxConfig FilePath — дополнительный sink событий в файл JSON Lines, пустой отключает его.
+// После MaxAttempts неудач событие откладывается (parked) и не задерживает следующие
+type OutboxConfig struct {
+	PollIntervalMs int
+	BatchSize      int
+	MaxAttempts    int
+	RetentionHours int
+	FilePath       string
+}
+
+type TransferConfig struct {
+	MaxImportMB int
+}
+
+// GraphQLConfig ограничения запросов к /graphql; 0 отключает ограничение
+type GraphQLConfig struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// AdminConfig Users — пользователи с доступом к /admin; массовые операции над выборкой
+// больше BulkSyncLimit выполняются фоновой задачей пачками по BulkBatchSize
+type AdminConfig struct {
+	Users                   []string
+	BulkSyncLimit           int
+	BulkBatchSize           int
+	JobsPollIntervalSeconds int
+}
+
+// ModerationConfig BannedWords — запрещённые слова, находятся в любой форме; текст с числом ссылок
+// больше MaxLinks считается спамом; повтор одного символа длиннее MaxRepeatedChars отклоняется;
+// вопрос или ответ с FlagHideThreshold открытыми жалобами скрывается до проверки (0 — не скрывать)
+type ModerationConfig struct {
+	BannedWords       []string
+	MaxLinks          int
+	MaxRepeatedChars  int
+	FlagHideThreshold int
 }
 
 func Load() *Config {
@@ -38,13 +153,86 @@ func Load() *Config {
 			Password: getEnv("DB_PASSWORD", "password"),
 			Name:     getEnv("DB_NAME", "questions_db"),
 		},
+		Health: HealthConfig{
+			CheckTimeout:  getEnvInt("HEALTH_CHECK_TIMEOUT", 2),
+			MigrationsDir: getEnv("MIGRATIONS_DIR", "migrations"),
+		},
 		Server: ServerConfig{
 			HTTPPort:        getEnv("HTTP_PORT", "8080"),
+			GRPCPort:        getEnv("GRPC_PORT", ""),
 			ReadTimeout:     getEnvInt("HTTP_READ_TIMEOUT", 15),
 			WriteTimeout:    getEnvInt("HTTP_WRITE_TIMEOUT", 15),
 			IdleTimeout:     getEnvInt("HTTP_IDLE_TIMEOUT", 60),
 			ShutdownTimeout: getEnvInt("HTTP_SHUTDOWN_TIMEOUT", 30),
 			RequestTimeout:  getEnvInt("HTTP_REQUEST_TIMEOUT", 5),
+			DrainDelay:      getEnvInt("HTTP_DRAIN_DELAY", 0),
+		},
+		Tracing: TracingConfig{
+			Exporter:     getEnv("TRACING_EXPORTER", "none"),
+			ServiceName:  getEnv("TRACING_SERVICE_NAME", "answer-questions"),
+			OTLPEndpoint: getEnv("TRACING_OTLP_ENDPOINT", "localhost:4318"),
+			OTLPInsecure: getEnvBool("TRACING_OTLP_INSECURE", true),
+			FilePath:     getEnv("TRACING_FILE_PATH", "traces.json"),
+			SampleRatio:  getEnvFloat("TRACING_SAMPLE_RATIO", 1.0),
+		},
+		RateLimit: RateLimitConfig{
+			ReadPerMinute:  getEnvInt("RATE_LIMIT_READ_PER_MINUTE", 600),
+			ReadBurst:      getEnvInt("RATE_LIMIT_READ_BURST", 100),
+			WritePerMinute: getEnvInt("RATE_LIMIT_WRITE_PER_MINUTE", 30),
+			WriteBurst:     getEnvInt("RATE_LIMIT_WRITE_BURST", 10),
+			TrustedProxies: getEnvList("RATE_LIMIT_TRUSTED_PROXIES"),
+		},
+		Idempotency: IdempotencyConfig{
+			TTLHours:             getEnvInt("IDEMPOTENCY_TTL_HOURS", 24),
+			CleanupIntervalHours: getEnvInt("IDEMPOTENCY_CLEANUP_INTERVAL_HOURS", 1),
+		},
+		Cache: CacheConfig{
+			Enabled:    getEnvBool("CACHE_ENABLED", true),
+			Size:       getEnvInt("CACHE_SIZE", 10000),
+			TTLSeconds: getEnvInt("CACHE_TTL_SECONDS", 30),
+		},
+		Events: EventsConfig{
+			Backend:          getEnv("EVENTS_BACKEND", "postgres"),
+			ReplayBufferSize: getEnvInt("EVENTS_REPLAY_BUFFER_SIZE", 1000),
+			HeartbeatSeconds: getEnvInt("EVENTS_HEARTBEAT_SECONDS", 15),
+		},
+		Auth: AuthConfig{
+			Tokens:           getEnvMap("AUTH_TOKENS"),
+			WSAllowedOrigins: getEnvList("WS_ALLOWED_ORIGINS"),
+		},
+		Webhooks: WebhooksConfig{
+			MaxAttempts:         getEnvInt("WEBHOOK_MAX_ATTEMPTS", 8),
+			BackoffBaseSeconds:  getEnvInt("WEBHOOK_BACKOFF_BASE_SECONDS", 30),
+			BackoffMaxSeconds:   getEnvInt("WEBHOOK_BACKOFF_MAX_SECONDS", 3600),
+			TimeoutSeconds:      getEnvInt("WEBHOOK_TIMEOUT_SECONDS", 10),
+			PollIntervalSeconds: getEnvInt("WEBHOOK_POLL_INTERVAL_SECONDS", 5),
+			BatchSize:           getEnvInt("WEBHOOK_BATCH_SIZE", 50),
+		},
+		Outbox: OutboxConfig{
+			PollIntervalMs: getEnvInt("OUTBOX_POLL_INTERVAL_MS", 200),
+			BatchSize:      getEnvInt("OUTBOX_BATCH_SIZE", 100),
+			MaxAttempts:    getEnvInt("OUTBOX_MAX_ATTEMPTS", 10),
+			RetentionHours: getEnvInt("OUTBOX_RETENTION_HOURS", 24),
+			FilePath:       getEnv("OUTBOX_FILE_PATH", ""),
+		},
+		Transfer: TransferConfig{
+			MaxImportMB: getEnvInt("IMPORT_MAX_BODY_MB", 100),
+		},
+		GraphQL: GraphQLConfig{
+			MaxDepth:      getEnvInt("GRAPHQL_MAX_DEPTH", 6),
+			MaxComplexity: getEnvInt("GRAPHQL_MAX_COMPLEXITY", 1000),
+		},
+		Admin: AdminConfig{
+			Users:                   getEnvList("ADMIN_USERS"),
+			BulkSyncLimit:           getEnvInt("ADMIN_BULK_SYNC_LIMIT", 1000),
+			BulkBatchSize:           getEnvInt("ADMIN_BULK_BATCH_SIZE", 500),
+			JobsPollIntervalSeconds: getEnvInt("ADMIN_JOBS_POLL_INTERVAL_SECONDS", 2),
+		},
+		Moderation: ModerationConfig{
+			BannedWords:       getEnvList("MODERATION_BANNED_WORDS"),
+			MaxLinks:          getEnvInt("MODERATION_MAX_LINKS", 3),
+			MaxRepeatedChars:  getEnvInt("MODERATION_MAX_REPEATED_CHARS", 10),
+			FlagHideThreshold: getEnvInt("MODERATION_FLAG_HIDE_THRESHOLD", 3),
 		},
 	}
 }
@@ -74,3 +262,57 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return intVal
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	boolVal, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Ошибка преобразования %s в bool: %v, используем значение по умолчанию %t", key, err, defaultValue)
+		return defaultValue
+	}
+	return boolVal
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	floatVal, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		log.Printf("Ошибка преобразования %s в число: %v, используем значение по умолчанию %g", key, err, defaultValue)
+		return defaultValue
+	}
+	return floatVal
+}
+
+func getEnvList(key string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return nil
+	}
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvMap разбирает список вида key1:value1,key2:value2
+func getEnvMap(key string) map[string]string {
+	result := make(map[string]string)
+	for _, item := range getEnvList(key) {
+		k, v, found := strings.Cut(item, ":")
+		if !found || k == "" || v == "" {
+			log.Printf("Пропущен некорректный элемент %s: %q", key, item)
+			continue
+		}
+		result[k] = v
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package entity
+
+import "time"
+
+// Migration миграция из каталога goose и её состояние в БД
+type Migration struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	Applied   bool       `json:"applied"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// QuestionSummary вопрос с числом ответов для административных команд
+type QuestionSummary struct {
+	Question
+	Answers int `json:"answers"`
+}
+
+// Stats сводка по данным сервиса
+type Stats struct {
+	Questions                int64 `json:"questions"`
+	Answers                  int64 `json:"answers"`
+	Users                    int64 `json:"users"`
+	OutboxPending            int64 `json:"outbox_pending"`
+	OutboxParked             int64 `json:"outbox_parked"`
+	WebhooksActive           int64 `json:"webhooks_active"`
+	WebhookDeliveriesPending int64 `json:"webhook_deliveries_pending"`
+	WebhookDeliveriesDead    int64 `json:"webhook_deliveries_dead"`
+}
+
+// AnswerFilter условия массовой операции над ответами; заданные условия объединяются через AND
+type AnswerFilter struct {
+	IDs         []int      `json:"ids,omitempty"`
+	UserID      string     `json:"user_id,omitempty"`
+	QuestionID  int        `json:"question_id,omitempty"`
+	CreatedFrom *time.Time `json:"created_from,omitempty"`
+	CreatedTo   *time.Time `json:"created_to,omitempty"`
+}
+
+// IsEmpty true, если не задано ни одно условие: такой фильтр выбрал бы все ответы
+func (f AnswerFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.UserID == "" && f.QuestionID == 0 && f.CreatedFrom == nil && f.CreatedTo == nil
+}
+
+// BulkDeleteResult итог массового удаления. Для фоновой задачи Deleted равен 0, а прогресс
+// отдаёт задача JobID
+type BulkDeleteResult struct {
+	Matched int64
+	Deleted int
+	DryRun  bool
+	JobID   int64
+}
@@ -3,12 +3,22 @@ package entity
 import "time"
 
 type Answer struct {
-	ID         int       `gorm:"primaryKey" json:"id"`
-	QuestionID int       `json:"question_id"`
-	UserID     string    `json:"user_id"`
-	Text       string    `json:"text"`
-	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
-	Question   *Question `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE" json:"-"`
+	ID               int       `gorm:"primaryKey" json:"id"`
+	QuestionID       int       `json:"question_id"`
+	UserID           string    `json:"user_id"`
+	Text             string    `json:"text"`
+	ModerationStatus string    `gorm:"default:approved" json:"moderation_status"`
+	ModerationReason string    `json:"moderation_reason,omitempty"`
+	Version          int       `gorm:"default:1" json:"version"`
+	CreatedAt        time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	Question         *Question `gorm:"foreignKey:QuestionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// AnswerStats число ответов на вопрос и самый новый из них
+type AnswerStats struct {
+	Count  int
+	Latest *Answer
 }
 
 func (Answer) TableName() string {
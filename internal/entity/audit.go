@@ -0,0 +1,62 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+	// AuditActionErase удаление или обезличивание всех данных пользователя по его запросу
+	AuditActionErase = "erase"
+	// AuditActionApprove и AuditActionReject решения модератора по вопросу или ответу из очереди
+	AuditActionApprove = "approve"
+	AuditActionReject  = "reject"
+	// AuditActionHide автоматическое скрытие вопроса или ответа, набравшего порог жалоб
+	AuditActionHide = "hide"
+	// AuditActionResolveFlags разбор жалоб модератором: before — открытые жалобы, after — итог
+	AuditActionResolveFlags = "resolve_flags"
+
+	AuditEntityQuestion = "question"
+	AuditEntityAnswer   = "answer"
+	AuditEntityComment  = "comment"
+	AuditEntityUser     = "user"
+)
+
+// AuditEvent запись журнала аудита. Before и After — снимки сущности до и после изменения:
+// у создания нет Before, у удаления нет After. Пустой Actor — анонимный запрос
+type AuditEvent struct {
+	ID         int64           `gorm:"primaryKey" json:"id"`
+	Actor      string          `json:"actor"`
+	Action     string          `json:"action"`
+	EntityType string          `json:"entity_type"`
+	EntityID   int             `json:"entity_id"`
+	Before     json.RawMessage `gorm:"type:jsonb" json:"before,omitempty"`
+	After      json.RawMessage `gorm:"type:jsonb" json:"after,omitempty"`
+	RequestID  string          `json:"request_id"`
+	IP         string          `gorm:"column:ip" json:"ip"`
+	CreatedAt  time.Time       `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditMeta кто и откуда выполняет операцию; транспорт кладёт её в контекст на входе запроса
+type AuditMeta struct {
+	Actor     string
+	RequestID string
+	IP        string
+}
+
+// AuditFilter условия выборки журнала; пустые поля не ограничивают выборку
+type AuditFilter struct {
+	Actor      string
+	Action     string
+	EntityType string
+	EntityID   int
+	From       *time.Time
+	To         *time.Time
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Comment уточнение к ответу или вопросу, которое не является ответом. Задан ровно один из AnswerID и QuestionID.
+// ParentID — комментарий верхнего уровня к той же записи, на который это ответ; отвечать на ответы нельзя
+type Comment struct {
+	ID         int       `gorm:"primaryKey" json:"id"`
+	AnswerID   *int      `json:"answer_id,omitempty"`
+	QuestionID *int      `json:"question_id,omitempty"`
+	ParentID   *int      `json:"parent_id,omitempty"`
+	UserID     string    `json:"user_id"`
+	Text       string    `json:"text"`
+	Version    int       `gorm:"default:1" json:"version"`
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+func (Comment) TableName() string {
+	return "comments"
+}
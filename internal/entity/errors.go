@@ -2,11 +2,14 @@ package entity
 
 type ErrorResponse struct {
 	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
 }
 
+// CustomError ошибка с HTTP кодом; Field — поле запроса, к которому относится ошибка валидации
 type CustomError struct {
 	Code    int
 	Message string
+	Field   string
 }
 
 func (e CustomError) Error() string {
@@ -21,6 +24,7 @@ var (
 	ErrInvalidQuestionText = CustomError{
 		Code:    400,
 		Message: "Текст вопроса не может быть пустым",
+		Field:   "text",
 	}
 	ErrQuestionAlreadyExists = CustomError{
 		Code:    409,
@@ -34,10 +38,12 @@ var (
 	ErrInvalidAnswerText = CustomError{
 		Code:    400,
 		Message: "Текст ответа не может быть пустым",
+		Field:   "text",
 	}
 	ErrInvalidUserID = CustomError{
 		Code:    400,
 		Message: "ID пользователя не может быть пустым",
+		Field:   "user_id",
 	}
 
 	ErrDatabaseConnection = CustomError{
@@ -49,8 +55,202 @@ var (
 		Message: "Ошибка при выполнении запроса к базе данных",
 	}
 
+	ErrTooManyIDs = CustomError{
+		Code:    400,
+		Message: "Слишком много ID в одном запросе, допускается не больше 100",
+	}
+
 	ErrValidationFailed = CustomError{
 		Code:    400,
 		Message: "Ошибка валидации данных",
 	}
+
+	ErrUnauthorized = CustomError{
+		Code:    401,
+		Message: "Требуется авторизация",
+	}
+	ErrForbidden = CustomError{
+		Code:    403,
+		Message: "Недостаточно прав",
+	}
+	ErrUserIDMismatch = CustomError{
+		Code:    403,
+		Message: "user_id не совпадает с авторизованным пользователем",
+	}
+	ErrRouteNotFound = CustomError{
+		Code:    404,
+		Message: "Ресурс не найден",
+	}
+	ErrIdempotencyKeyMismatch = CustomError{
+		Code:    422,
+		Message: "Idempotency-Key уже использован с другим телом запроса",
+	}
+	ErrIdempotencyRequestInProgress = CustomError{
+		Code:    409,
+		Message: "Запрос с этим Idempotency-Key ещё обрабатывается",
+	}
+	ErrInvalidIdempotencyKey = CustomError{
+		Code:    400,
+		Message: "Некорректный Idempotency-Key",
+	}
+	ErrPreconditionFailed = CustomError{
+		Code:    412,
+		Message: "Ресурс был изменён, обновите данные и повторите запрос",
+	}
+	ErrTooManyRequests = CustomError{
+		Code:    429,
+		Message: "Слишком много запросов, попробуйте позже",
+	}
+
+	ErrWebhookNotFound = CustomError{
+		Code:    404,
+		Message: "Вебхук не найден",
+	}
+	ErrWebhookDeliveryNotFound = CustomError{
+		Code:    404,
+		Message: "Доставка вебхука не найдена",
+	}
+	ErrInvalidWebhookURL = CustomError{
+		Code:    400,
+		Message: "URL вебхука должен быть абсолютным http или https адресом",
+	}
+	ErrForbiddenWebhookURL = CustomError{
+		Code:    400,
+		Message: "URL вебхука не может указывать на внутренний или локальный адрес",
+	}
+	ErrInvalidWebhookEventTypes = CustomError{
+		Code:    400,
+		Message: "Укажите хотя бы один известный тип события",
+	}
+	ErrInvalidWebhookSecret = CustomError{
+		Code:    400,
+		Message: "Секрет вебхука должен быть не короче 16 символов",
+	}
+	ErrInvalidTransferFormat = CustomError{
+		Code:    400,
+		Message: "Формат должен быть jsonl или csv",
+	}
+	ErrInvalidCSVHeader = CustomError{
+		Code:    400,
+		Message: "В заголовке CSV нет колонки question_text",
+	}
+	ErrImportQuestionNotPublished = CustomError{
+		Code:    409,
+		Message: "Вопрос на модерации или отклонён, ответы к нему не добавляются",
+	}
+
+	ErrEmptyAnswerFilter = CustomError{
+		Code:    400,
+		Message: "Укажите ID ответов или хотя бы один фильтр",
+	}
+	ErrTooManyBulkIDs = CustomError{
+		Code:    400,
+		Message: "Слишком много ID, допускается не больше 1000; для больших выборок используйте фильтры",
+	}
+	ErrInvalidTimeRange = CustomError{
+		Code:    400,
+		Message: "Начало интервала должно быть раньше его конца",
+	}
+	ErrJobNotFound = CustomError{
+		Code:    404,
+		Message: "Задача не найдена",
+	}
+	ErrInvalidErasureMode = CustomError{
+		Code:    400,
+		Message: "Режим удаления данных должен быть anonymize или delete",
+	}
+	ErrReservedUserID = CustomError{
+		Code:    400,
+		Message: "Этот ID пользователя зарезервирован",
+		Field:   "user_id",
+	}
+
+	ErrTextTooLong = CustomError{
+		Code:    400,
+		Message: "Текст не может быть длиннее 10000 символов",
+		Field:   "text",
+	}
+	ErrUserIDTooLong = CustomError{
+		Code:    400,
+		Message: "ID пользователя не может быть длиннее 255 символов",
+		Field:   "user_id",
+	}
+	ErrBannedWords = CustomError{
+		Code:    422,
+		Message: "Текст содержит недопустимые слова",
+		Field:   "text",
+	}
+	ErrCharacterFlood = CustomError{
+		Code:    422,
+		Message: "Текст содержит слишком длинные повторы символов",
+		Field:   "text",
+	}
+	ErrSuspectedSpam = CustomError{
+		Code:    422,
+		Message: "Текст похож на спам",
+		Field:   "text",
+	}
+
+	ErrInvalidModerationType = CustomError{
+		Code:    400,
+		Message: "Тип должен быть question или answer",
+	}
+	ErrAlreadyModerated = CustomError{
+		Code:    409,
+		Message: "Содержимое уже проверено модератором",
+	}
+	ErrEmptyModerationReason = CustomError{
+		Code:    400,
+		Message: "Укажите причину отклонения",
+		Field:   "reason",
+	}
+	ErrModerationReasonTooLong = CustomError{
+		Code:    400,
+		Message: "Причина не может быть длиннее 1000 символов",
+		Field:   "reason",
+	}
+
+	ErrInvalidFlagReason = CustomError{
+		Code:    400,
+		Message: "Причина жалобы должна быть spam, offensive, off_topic или other",
+		Field:   "reason",
+	}
+	ErrFlagCommentTooLong = CustomError{
+		Code:    400,
+		Message: "Комментарий не может быть длиннее 1000 символов",
+		Field:   "comment",
+	}
+	ErrFlagAlreadyExists = CustomError{
+		Code:    409,
+		Message: "Вы уже пожаловались на это содержимое",
+	}
+	ErrInvalidFlagAction = CustomError{
+		Code:    400,
+		Message: "Действие должно быть dismiss или uphold",
+		Field:   "action",
+	}
+	ErrNoOpenFlags = CustomError{
+		Code:    404,
+		Message: "Открытых жалоб нет",
+	}
+
+	ErrCommentNotFound = CustomError{
+		Code:    404,
+		Message: "Комментарий не найден",
+	}
+	ErrInvalidCommentText = CustomError{
+		Code:    400,
+		Message: "Текст комментария не может быть пустым",
+		Field:   "text",
+	}
+	ErrCommentTooLong = CustomError{
+		Code:    400,
+		Message: "Комментарий не может быть длиннее 1000 символов",
+		Field:   "text",
+	}
+	ErrInvalidCommentParent = CustomError{
+		Code:    400,
+		Message: "Ответить можно только на комментарий верхнего уровня к той же записи",
+		Field:   "parent_id",
+	}
 )
@@ -0,0 +1,62 @@
+package entity
+
+import "time"
+
+const (
+	FlagReasonSpam      = "spam"
+	FlagReasonOffensive = "offensive"
+	FlagReasonOffTopic  = "off_topic"
+	FlagReasonOther     = "other"
+
+	FlagOpen      = "open"
+	FlagDismissed = "dismissed"
+	FlagUpheld    = "upheld"
+
+	// FlagActionDismiss жалобы необоснованны: скрытый по жалобам вопрос или ответ снова публикуется
+	FlagActionDismiss = "dismiss"
+	// FlagActionUphold жалобы обоснованны: вопрос или ответ отклоняется, автор получает уведомление
+	FlagActionUphold = "uphold"
+
+	// FlagsHiddenReason причина модерации у вопросов и ответов, скрытых автоматически по числу жалоб
+	FlagsHiddenReason = "Скрыто по жалобам пользователей"
+)
+
+// FlagReasons допустимые причины жалобы
+var FlagReasons = []string{FlagReasonSpam, FlagReasonOffensive, FlagReasonOffTopic, FlagReasonOther}
+
+// Flag жалоба пользователя на вопрос или ответ; ItemType — ModerationTypeQuestion или ModerationTypeAnswer
+type Flag struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	ItemType   string     `json:"item_type"`
+	ItemID     int        `json:"item_id"`
+	UserID     string     `json:"user_id"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment,omitempty"`
+	Status     string     `gorm:"default:open" json:"status"`
+	ResolvedBy string     `json:"resolved_by,omitempty"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (Flag) TableName() string {
+	return "flags"
+}
+
+// FlagSummary открытые жалобы на один вопрос или ответ для модератора: число жалоб по причинам
+// и сами жалобы с комментариями, от старых к новым
+type FlagSummary struct {
+	Item    ModerationItem `json:"item"`
+	Count   int            `json:"count"`
+	Reasons map[string]int `json:"reasons"`
+	Flags   []Flag         `json:"flags"`
+}
+
+// FlagResolution итог разбора жалоб: сколько жалоб закрыто и статус модерации вопроса или ответа после разбора
+type FlagResolution struct {
+	ItemType         string `json:"item_type"`
+	ItemID           int    `json:"item_id"`
+	Action           string `json:"action"`
+	Comment          string `json:"comment,omitempty"`
+	Resolved         int64  `json:"resolved"`
+	ModerationStatus string `json:"moderation_status"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+const (
+	HealthStatusUp   = "up"
+	HealthStatusDown = "down"
+)
+
+type ComponentHealth struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// IsUp возвращает true, если все компоненты в рабочем состоянии
+func (r HealthReport) IsUp() bool {
+	return r.Status == HealthStatusUp
+}
@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+	IdempotencyStatusInProgress = "in_progress"
+	IdempotencyStatusCompleted  = "completed"
+)
+
+type IdempotencyRecord struct {
+	Scope          string `gorm:"primaryKey" json:"scope"`
+	Key            string `gorm:"primaryKey" json:"key"`
+	Fingerprint    string `json:"fingerprint"`
+	Status         string `json:"status"`
+	ResponseStatus int    `json:"response_status"`
+	// ResponseHeaders заголовки ответа, которые нужны при повторе: ETag, Location, Content-Type
+	ResponseHeaders map[string]string `gorm:"serializer:json" json:"response_headers,omitempty"`
+	ResponseBody    []byte            `json:"response_body"`
+	CreatedAt       time.Time         `gorm:"autoCreateTime:milli" json:"created_at"`
+	ExpiresAt       time.Time         `json:"expires_at"`
+}
+
+func (IdempotencyRecord) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+
+	JobTypeAnswersBulkDelete = "answers.bulk_delete"
+)
+
+// Job фоновая административная задача. Params хранит параметры задачи в JSON,
+// LeaseUntil — до какого момента задачу держит реплика, которая её выполняет
+type Job struct {
+	ID         int64      `gorm:"primaryKey" json:"id"`
+	Type       string     `json:"type"`
+	Status     string     `json:"status"`
+	Params     []byte     `gorm:"type:jsonb" json:"-"`
+	Total      int64      `json:"total"`
+	Processed  int64      `json:"processed"`
+	Error      string     `json:"error,omitempty"`
+	CreatedBy  string     `json:"created_by"`
+	LeaseUntil *time.Time `json:"-"`
+	// LeaseToken выдаётся при каждом захвате задачи; обновить задачу может только её текущий владелец
+	LeaseToken string     `json:"-"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"autoUpdateTime:milli" json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+func (Job) TableName() string {
+	return "admin_jobs"
+}
@@ -0,0 +1,30 @@
+package entity
+
+import "time"
+
+const (
+	ModerationApproved = "approved"
+	ModerationPending  = "pending"
+	ModerationRejected = "rejected"
+
+	ModerationTypeQuestion = "question"
+	ModerationTypeAnswer   = "answer"
+)
+
+// Published видно ли содержимое всем. Пустой статус у записей, созданных в обход модерации
+// (тестовые хранилища в памяти), считается одобренным
+func Published(moderationStatus string) bool {
+	return moderationStatus != ModerationPending && moderationStatus != ModerationRejected
+}
+
+// ModerationItem вопрос или ответ в очереди модерации; QuestionID заполнен у ответов
+type ModerationItem struct {
+	Type       string    `json:"type"`
+	ID         int       `json:"id"`
+	QuestionID int       `json:"question_id,omitempty"`
+	AuthorID   string    `json:"author_id"`
+	Text       string    `json:"text"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationModerationApproved = "moderation.approved"
+	NotificationModerationRejected = "moderation.rejected"
+)
+
+// Notification уведомление пользователя; Data зависит от типа
+type Notification struct {
+	ID        int64           `gorm:"primaryKey" json:"id"`
+	UserID    string          `json:"user_id"`
+	Type      string          `json:"type"`
+	Data      json.RawMessage `gorm:"type:jsonb" json:"data"`
+	CreatedAt time.Time       `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
@@ -0,0 +1,28 @@
+package entity
+
+import "time"
+
+const AggregateQuestion = "question"
+
+// OutboxMessage доменное событие, записанное в одной транзакции с изменением данных.
+// Порядок публикации сохраняется в пределах агрегата (вопрос вместе с его ответами).
+// DeliveredSinks — sink, уже получившие событие: повтор после сбоя одного sink не дублирует его в остальных.
+// Событие с ParkedAt исчерпало попытки и больше не публикуется, чтобы не задерживать следующие
+type OutboxMessage struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	AggregateType  string     `json:"aggregate_type"`
+	AggregateID    int        `json:"aggregate_id"`
+	Payload        []byte     `gorm:"type:jsonb" json:"payload"`
+	Attempts       int        `json:"attempts"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredSinks []string   `gorm:"serializer:json" json:"delivered_sinks"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+	PublishedAt    *time.Time `json:"published_at,omitempty"`
+	ParkedAt       *time.Time `json:"parked_at,omitempty"`
+}
+
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
@@ -0,0 +1,24 @@
+package entity
+
+const (
+	// ErasureAnonymize заменяет автора ответов и комментариев на ErasedUserID, текст остаётся
+	ErasureAnonymize = "anonymize"
+	// ErasureDelete удаляет ответы и комментарии пользователя целиком
+	ErasureDelete = "delete"
+
+	// ErasedUserID автор обезличенных ответов и комментариев; создавать их от его имени нельзя
+	ErasedUserID = "[deleted]"
+)
+
+// ErasureResult итог удаления данных пользователя: сколько ответов и комментариев обезличено или удалено,
+// у скольких вопросов убран автор и сколько вебхуков, уведомлений и жалоб удалено. Повторный запрос возвращает нули
+type ErasureResult struct {
+	UserID        string `json:"user_id"`
+	Mode          string `json:"mode"`
+	Answers       int64  `json:"answers"`
+	Questions     int64  `json:"questions"`
+	Webhooks      int64  `json:"webhooks"`
+	Notifications int64  `json:"notifications"`
+	Flags         int64  `json:"flags"`
+	Comments      int64  `json:"comments"`
+}
@@ -2,10 +2,17 @@ package entity
 
 import "time"
 
+// Question AuthorID — пользователь из токена, создавший вопрос; пустой у анонимных вопросов
+// и вопросов, созданных до модерации
 type Question struct {
-	ID        int       `gorm:"primaryKey" json:"id"`
-	Text      string    `json:"text"`
-	CreatedAt time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	ID               int       `gorm:"primaryKey" json:"id"`
+	Text             string    `json:"text"`
+	AuthorID         string    `json:"author_id,omitempty"`
+	ModerationStatus string    `gorm:"default:approved" json:"moderation_status"`
+	ModerationReason string    `json:"moderation_reason,omitempty"`
+	Version          int       `gorm:"default:1" json:"version"`
+	CreatedAt        time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime:milli" json:"updated_at"`
 }
 
 func (Question) TableName() string {
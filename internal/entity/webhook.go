@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryDead      = "dead"
+)
+
+type Webhook struct {
+	ID         int       `gorm:"primaryKey" json:"id"`
+	OwnerID    string    `json:"owner_id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `gorm:"serializer:json" json:"event_types"`
+	Secret     string    `json:"-"`
+	Active     bool      `gorm:"default:true" json:"active"`
+	CreatedAt  time.Time `gorm:"autoCreateTime:milli" json:"created_at"`
+}
+
+func (Webhook) TableName() string {
+	return "webhooks"
+}
+
+// Subscribed проверяет, подписан ли вебхук на тип события
+func (w *Webhook) Subscribed(eventType string) bool {
+	for _, t := range w.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery одна доставка события на вебхук. Статус dead — доставка
+// исчерпала попытки и попала в dead-letter список
+type WebhookDelivery struct {
+	ID             int64      `gorm:"primaryKey" json:"id"`
+	WebhookID      int        `json:"webhook_id"`
+	EventID        string     `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Payload        []byte     `gorm:"type:jsonb" json:"-"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time `json:"last_attempt_at,omitempty"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime:milli" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime:milli" json:"updated_at"`
+}
+
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
@@ -0,0 +1,124 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Subscription получает события через буферизованный канал. Если подписчик не успевает
+// читать и буфер переполнен, канал закрывается: клиент переподключается с Last-Event-ID
+// и догоняет пропущенное из буфера повтора
+type Subscription struct {
+	C      <-chan Event
+	ch     chan Event
+	filter func(Event) bool
+	broker *Broker
+	closed bool
+}
+
+func (s *Subscription) Close() {
+	s.broker.unsubscribe(s)
+}
+
+// Broker раздаёт события подписчикам внутри процесса и хранит ограниченный буфер
+// последних событий для возобновления потока
+type Broker struct {
+	mu         sync.Mutex
+	subs       map[*Subscription]struct{}
+	replay     []Event
+	replaySize int
+	next       int
+	full       bool
+}
+
+func NewBroker(replaySize int) *Broker {
+	return &Broker{
+		subs:       make(map[*Subscription]struct{}),
+		replay:     make([]Event, replaySize),
+		replaySize: replaySize,
+	}
+}
+
+// Publish доставляет событие локальным подписчикам. Используется как Publisher
+// для одной реплики; при нескольких репликах события приходят через PGNotifier
+func (b *Broker) Publish(ctx context.Context, event Event) error {
+	b.Dispatch(event)
+	return nil
+}
+
+func (b *Broker) Dispatch(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.replaySize > 0 {
+		b.replay[b.next] = event
+		b.next = (b.next + 1) % b.replaySize
+		if b.next == 0 {
+			b.full = true
+		}
+	}
+
+	for sub := range b.subs {
+		if !sub.filter(event) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			b.closeLocked(sub)
+		}
+	}
+}
+
+// Subscribe подписывает на события, прошедшие filter. Если передан lastEventID,
+// возвращает пропущенные после него события из буфера повтора; resumed=false означает,
+// что событие уже вытеснено из буфера и клиенту нужно перечитать состояние целиком
+func (b *Broker) Subscribe(filter func(Event) bool, bufferSize int, lastEventID string) (sub *Subscription, missed []Event, resumed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, bufferSize)
+	sub = &Subscription{C: ch, ch: ch, filter: filter, broker: b}
+	b.subs[sub] = struct{}{}
+
+	if lastEventID == "" {
+		return sub, nil, true
+	}
+
+	found := false
+	for _, event := range b.bufferedLocked() {
+		if found && filter(event) {
+			missed = append(missed, event)
+		}
+		if event.ID == lastEventID {
+			found = true
+		}
+	}
+	if !found {
+		return sub, nil, false
+	}
+	return sub, missed, true
+}
+
+func (b *Broker) unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closeLocked(sub)
+}
+
+func (b *Broker) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	close(sub.ch)
+}
+
+// bufferedLocked возвращает события буфера повтора от старых к новым
+func (b *Broker) bufferedLocked() []Event {
+	if !b.full {
+		return append([]Event(nil), b.replay[:b.next]...)
+	}
+	return append(append([]Event(nil), b.replay[b.next:]...), b.replay[:b.next]...)
+}
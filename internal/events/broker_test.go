@@ -0,0 +1,50 @@
+package events
+
+import (
+	"testing"
+)
+
+func questionFilter(questionID int) func(Event) bool {
+	return func(e Event) bool { return e.QuestionID == questionID }
+}
+
+func TestBroker_ResumeFromLastEventID(t *testing.T) {
+	broker := NewBroker(3)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		broker.Dispatch(Event{ID: id, Type: TypeAnswerCreated, QuestionID: 1})
+	}
+	broker.Dispatch(Event{ID: "5", Type: TypeAnswerCreated, QuestionID: 2})
+
+	sub, missed, resumed := broker.Subscribe(questionFilter(1), 1, "3")
+	defer sub.Close()
+
+	if !resumed {
+		t.Fatalf("expected resume from buffered event")
+	}
+	if len(missed) != 1 || missed[0].ID != "4" {
+		t.Errorf("expected missed event '4', got %v", missed)
+	}
+
+	evicted, _, resumed := broker.Subscribe(questionFilter(1), 1, "1")
+	defer evicted.Close()
+	if resumed {
+		t.Errorf("expected evicted Last-Event-ID to require resync")
+	}
+}
+
+func TestBroker_SlowSubscriberIsDisconnected(t *testing.T) {
+	broker := NewBroker(10)
+	sub, _, _ := broker.Subscribe(questionFilter(1), 1, "")
+
+	broker.Dispatch(Event{ID: "1", QuestionID: 1})
+	broker.Dispatch(Event{ID: "2", QuestionID: 1})
+
+	if event, ok := <-sub.C; !ok || event.ID != "1" {
+		t.Fatalf("expected first event to be delivered, got %v (ok=%v)", event, ok)
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("expected subscription to be closed after buffer overflow")
+	}
+
+	sub.Close()
+}
@@ -0,0 +1,76 @@
+package events
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	TypeQuestionCreated = "question.created"
+	TypeQuestionDeleted = "question.deleted"
+	TypeAnswerCreated   = "answer.created"
+	TypeAnswerDeleted   = "answer.deleted"
+
+	// TypeQuestionHidden и TypeAnswerHidden запись снята с публикации (жалобы, решение модератора),
+	// но не удалена: если её вернут, придёт question.created или answer.created
+	TypeQuestionHidden = "question.hidden"
+	TypeAnswerHidden   = "answer.hidden"
+
+	// TypeAnswerUpdated ответ изменён без смены видимости: автор обезличен при удалении его данных
+	TypeAnswerUpdated = "answer.updated"
+
+	// TypeCommentCreated и TypeCommentDeleted комментарий к опубликованному вопросу или ответу на него;
+	// ответы на удалённый комментарий удаляются вместе с ним без отдельных событий
+	TypeCommentCreated = "comment.created"
+	TypeCommentDeleted = "comment.deleted"
+)
+
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	QuestionID int             `json:"question_id"`
+	Data       json.RawMessage `json:"data"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Publisher публикует доменные события для подписчиков во всех репликах
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// IsKnownType проверяет, что тип события публикуется сервисом
+func IsKnownType(eventType string) bool {
+	switch eventType {
+	case TypeQuestionCreated, TypeQuestionDeleted, TypeAnswerCreated, TypeAnswerDeleted, TypeQuestionHidden, TypeAnswerHidden, TypeAnswerUpdated,
+		TypeCommentCreated, TypeCommentDeleted:
+		return true
+	}
+	return false
+}
+
+// NewEvent создаёт событие с уникальным ID. ID назначает публикующая реплика,
+// поэтому он одинаков у всех реплик и пригоден для Last-Event-ID
+func NewEvent(eventType string, questionID int, data any) (Event, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	now := time.Now().UTC()
+	return Event{
+		ID:         newEventID(now),
+		Type:       eventType,
+		QuestionID: questionID,
+		Data:       payload,
+		OccurredAt: now,
+	}, nil
+}
+
+func newEventID(now time.Time) string {
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return strconv.FormatInt(now.UnixNano(), 10) + "-" + hex.EncodeToString(suffix)
+}
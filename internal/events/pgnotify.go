@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"gorm.io/gorm"
+)
+
+const (
+	NotifyChannel     = "qa_events"
+	listenRetryPeriod = 2 * time.Second
+
+	// maxNotifyPayload запас до предела pg_notify в 8000 байт
+	maxNotifyPayload = 7900
+)
+
+// EventLoader загружает событие целиком по ID, когда уведомление пришло без данных
+type EventLoader func(ctx context.Context, eventID string) (Event, error)
+
+// PGNotifier рассылает события всем репликам через Postgres LISTEN/NOTIFY.
+// Публикующая реплика получает своё же уведомление, поэтому локальная доставка
+// идёт только из Listen, без дублей. Событие, которое не помещается в уведомление
+// (текст до 10000 символов), отправляется без данных, и получатели загружают его через load
+type PGNotifier struct {
+	db     *gorm.DB
+	dsn    string
+	broker *Broker
+	load   EventLoader
+}
+
+func NewPGNotifier(db *gorm.DB, dsn string, broker *Broker, load EventLoader) *PGNotifier {
+	return &PGNotifier{db: db, dsn: dsn, broker: broker, load: load}
+}
+
+func (n *PGNotifier) Publish(ctx context.Context, event Event) error {
+	payload, err := notifyPayload(event)
+	if err != nil {
+		return err
+	}
+	return n.db.WithContext(ctx).Exec("SELECT pg_notify(?, ?)", NotifyChannel, payload).Error
+}
+
+// notifyPayload событие в JSON; если оно длиннее maxNotifyPayload, остаются ID, тип и вопрос
+func notifyPayload(event Event) (string, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	if len(payload) <= maxNotifyPayload {
+		return string(payload), nil
+	}
+	event.Data = nil
+	payload, err = json.Marshal(event)
+	if err != nil {
+		return "", err
+	}
+	return string(payload), nil
+}
+
+// stripped уведомление пришло без данных события
+func stripped(event Event) bool {
+	return len(event.Data) == 0 || string(event.Data) == "null"
+}
+
+// Listen слушает канал до отмены ctx, переподключаясь при обрыве соединения
+func (n *PGNotifier) Listen(ctx context.Context) {
+	for {
+		if err := n.listenOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Ошибка LISTEN %s: %v, переподключение через %s", NotifyChannel, err, listenRetryPeriod)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryPeriod):
+		}
+	}
+}
+
+func (n *PGNotifier) listenOnce(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, n.dsn)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = conn.Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+NotifyChannel); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("Ошибка декодирования события: %v", err)
+			continue
+		}
+		if stripped(event) && n.load != nil {
+			loaded, err := n.load(ctx, event.ID)
+			if err != nil {
+				log.Printf("Ошибка загрузки события %s: %v", event.ID, err)
+				continue
+			}
+			event = loaded
+		}
+		n.broker.Dispatch(event)
+	}
+}
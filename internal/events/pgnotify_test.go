@@ -0,0 +1,45 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNotifyPayload(t *testing.T) {
+	small, err := NewEvent(TypeAnswerCreated, 1, map[string]string{"text": "Короткий ответ"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, err := notifyPayload(small)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var decoded Event
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if stripped(decoded) {
+		t.Errorf("expected small event to keep data, got %s", payload)
+	}
+
+	// текст из 10000 символов кириллицей занимает 20000 байт и не помещается в pg_notify
+	large, err := NewEvent(TypeAnswerCreated, 1, map[string]string{"text": strings.Repeat("я", 10000)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	payload, err = notifyPayload(large)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(payload) > maxNotifyPayload {
+		t.Fatalf("expected payload under %d bytes, got %d", maxNotifyPayload, len(payload))
+	}
+	decoded = Event{}
+	if err := json.Unmarshal([]byte(payload), &decoded); err != nil {
+		t.Fatalf("failed to decode payload: %v", err)
+	}
+	if !stripped(decoded) || decoded.ID != large.ID || decoded.Type != large.Type || decoded.QuestionID != 1 {
+		t.Errorf("expected event without data but with ID and type, got %+v", decoded)
+	}
+}
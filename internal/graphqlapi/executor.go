@@ -0,0 +1,119 @@
+package graphqlapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+// Request тело запроса к POST /graphql
+type Request struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// Executor выполняет GraphQL запросы поверх сервисов вопросов и ответов
+type Executor struct {
+	schema          graphql.Schema
+	questionService service.QuestionService
+	answerService   service.AnswerService
+	limits          Limits
+}
+
+func NewExecutor(questionService service.QuestionService, answerService service.AnswerService, limits Limits) (*Executor, error) {
+	schema, err := newSchema(questionService, answerService)
+	if err != nil {
+		return nil, err
+	}
+	return &Executor{
+		schema:          schema,
+		questionService: questionService,
+		answerService:   answerService,
+		limits:          limits,
+	}, nil
+}
+
+// Execute разбирает запрос, проверяет ограничения глубины и сложности до выполнения
+// и выполняет его. Ошибки возвращаются в Result.Errors, как требует спецификация GraphQL
+func (e *Executor) Execute(ctx context.Context, req Request) *graphql.Result {
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if err := checkLimits(doc, req.OperationName, req.Variables, e.limits); err != nil {
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{formatError(gqlerrors.FormatError(err))}}
+	}
+	if validation := graphql.ValidateDocument(&e.schema, doc, nil); !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}
+	}
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        e.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       withLoaders(ctx, e.questionService, e.answerService),
+	})
+	for i := range result.Errors {
+		result.Errors[i] = formatError(result.Errors[i])
+	}
+	return result
+}
+
+// formatError добавляет к ошибке сервиса extensions с кодом и HTTP статусом entity.CustomError.
+// Прочие ошибки резолверов скрываются за общим сообщением, как в sendCustomError REST API
+func formatError(formatted gqlerrors.FormattedError) gqlerrors.FormattedError {
+	err := originalError(formatted)
+	if err == nil {
+		return formatted
+	}
+
+	var customErr entity.CustomError
+	if !errors.As(err, &customErr) {
+		log.Printf("Ошибка GraphQL резолвера: %v", err)
+		customErr = entity.ErrDatabaseQuery
+	}
+	formatted.Message = customErr.Message
+	formatted.Extensions = map[string]interface{}{
+		"code":   strings.ToUpper(strings.ReplaceAll(http.StatusText(customErr.Code), " ", "_")),
+		"status": customErr.Code,
+	}
+	if customErr.Field != "" {
+		formatted.Extensions["field"] = customErr.Field
+	}
+	return formatted
+}
+
+// originalError достаёт ошибку резолвера из обёрток graphql-go; nil означает ошибку
+// самой библиотеки (например, null в non-null поле), её сообщение возвращается как есть
+func originalError(err error) error {
+	for {
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			if wrapped.OriginalError() == nil {
+				return nil
+			}
+			err = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			if wrapped.OriginalError == nil {
+				return nil
+			}
+			err = wrapped.OriginalError
+		default:
+			return err
+		}
+	}
+}
@@ -0,0 +1,384 @@
+package graphqlapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type mockQuestionService struct {
+	service.QuestionService
+	getByIDs       func(ctx context.Context, ids []int) ([]entity.Question, []int, error)
+	getPage        func(ctx context.Context, afterID, limit int) ([]entity.Question, error)
+	createQuestion func(ctx context.Context, text string) (*entity.Question, error)
+}
+
+func (m *mockQuestionService) GetQuestionsByIDs(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+	return m.getByIDs(ctx, ids)
+}
+
+func (m *mockQuestionService) GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	return m.getPage(ctx, afterID, limit)
+}
+
+func (m *mockQuestionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
+	return m.createQuestion(ctx, text)
+}
+
+type mockAnswerService struct {
+	service.AnswerService
+	getAnswersByIDs func(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error)
+	deleteAnswer    func(ctx context.Context, id, version int) error
+	createAnswer    func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error)
+}
+
+func (m *mockAnswerService) GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+	return m.getAnswersByIDs(ctx, questionIDs)
+}
+
+func (m *mockAnswerService) DeleteAnswer(ctx context.Context, id, version int) error {
+	return m.deleteAnswer(ctx, id, version)
+}
+
+func (m *mockAnswerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+	return m.createAnswer(ctx, questionID, userID, text)
+}
+
+func newTestExecutor(t *testing.T, questionService service.QuestionService, answerService service.AnswerService, limits Limits) *Executor {
+	t.Helper()
+	executor, err := NewExecutor(questionService, answerService, limits)
+	if err != nil {
+		t.Fatalf("failed to build schema: %v", err)
+	}
+	return executor
+}
+
+// decode переводит data результата в структуру через JSON, как её увидит клиент
+func decode(t *testing.T, result *graphql.Result, target any) {
+	t.Helper()
+	if len(result.Errors) > 0 {
+		t.Fatalf("unexpected errors: %v", result.Errors)
+	}
+	body, err := json.Marshal(result.Data)
+	if err != nil {
+		t.Fatalf("failed to marshal data: %v", err)
+	}
+	if err := json.Unmarshal(body, target); err != nil {
+		t.Fatalf("failed to unmarshal data: %v", err)
+	}
+}
+
+func TestExecute_NestedAnswersAreBatched(t *testing.T) {
+	questionService := &mockQuestionService{
+		getPage: func(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+			return []entity.Question{{ID: 1, Text: "Q1"}, {ID: 2, Text: "Q2"}, {ID: 3, Text: "Q3"}}, nil
+		},
+	}
+	var batches [][]int
+	answerService := &mockAnswerService{
+		getAnswersByIDs: func(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+			batches = append(batches, slices.Clone(questionIDs))
+			return map[int][]entity.Answer{
+				1: {{ID: 11, QuestionID: 1, UserID: "alice"}, {ID: 10, QuestionID: 1, UserID: "bob"}},
+				3: {{ID: 30, QuestionID: 3, UserID: "carol"}},
+			}, nil
+		},
+	}
+	executor := newTestExecutor(t, questionService, answerService, Limits{})
+
+	result := executor.Execute(context.Background(), Request{
+		Query: `{ questions { id answerCount answers(limit: 1) { id author { id } } } }`,
+	})
+
+	var data struct {
+		Questions []struct {
+			ID          int
+			AnswerCount int
+			Answers     []struct {
+				ID     int
+				Author struct{ ID string }
+			}
+		}
+	}
+	decode(t, result, &data)
+
+	if len(batches) != 1 || !slices.Equal(batches[0], []int{1, 2, 3}) {
+		t.Fatalf("expected one batch for questions [1 2 3], got %v", batches)
+	}
+	if len(data.Questions) != 3 {
+		t.Fatalf("expected 3 questions, got %d", len(data.Questions))
+	}
+	first := data.Questions[0]
+	if first.AnswerCount != 2 || len(first.Answers) != 1 || first.Answers[0].Author.ID != "alice" {
+		t.Errorf("expected 2 answers with the newest by alice, got %+v", first)
+	}
+	if data.Questions[1].AnswerCount != 0 || len(data.Questions[1].Answers) != 0 {
+		t.Errorf("expected no answers for question 2, got %+v", data.Questions[1])
+	}
+}
+
+func TestExecute_NestedQuestionsAreBatched(t *testing.T) {
+	var batches [][]int
+	questionService := &mockQuestionService{
+		getByIDs: func(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+			batches = append(batches, slices.Clone(ids))
+			return []entity.Question{{ID: 1, Text: "Q1"}}, []int{2}, nil
+		},
+		getPage: func(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+			return []entity.Question{{ID: 1}, {ID: 2}}, nil
+		},
+	}
+	answerService := &mockAnswerService{
+		getAnswersByIDs: func(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+			return map[int][]entity.Answer{
+				1: {{ID: 10, QuestionID: 1}, {ID: 11, QuestionID: 1}},
+				2: {{ID: 20, QuestionID: 2}},
+			}, nil
+		},
+	}
+	executor := newTestExecutor(t, questionService, answerService, Limits{})
+
+	result := executor.Execute(context.Background(), Request{
+		Query: `{ questions { answers { question { text } } } }`,
+	})
+
+	var data struct {
+		Questions []struct {
+			Answers []struct {
+				Question *struct{ Text string }
+			}
+		}
+	}
+	decode(t, result, &data)
+
+	if len(batches) != 1 || !slices.Equal(batches[0], []int{1, 2}) {
+		t.Fatalf("expected one batch for questions [1 2], got %v", batches)
+	}
+	if data.Questions[0].Answers[1].Question == nil || data.Questions[0].Answers[1].Question.Text != "Q1" {
+		t.Errorf("expected nested question Q1, got %+v", data.Questions[0].Answers)
+	}
+	if data.Questions[1].Answers[0].Question != nil {
+		t.Errorf("expected null for a missing question, got %+v", data.Questions[1].Answers[0].Question)
+	}
+}
+
+func TestExecute_Limits(t *testing.T) {
+	executor := newTestExecutor(t, &mockQuestionService{}, &mockAnswerService{}, Limits{MaxDepth: 3, MaxComplexity: 100})
+
+	tests := []struct {
+		name    string
+		query   string
+		vars    map[string]interface{}
+		message string
+	}{
+		{
+			name:    "depth",
+			query:   `{ question(id: 1) { answers { question { answers { id } } } } }`,
+			message: "Глубина запроса 5 превышает допустимую 3",
+		},
+		{
+			name:    "depth through fragment",
+			query:   `{ question(id: 1) { ...deep } } fragment deep on Question { answers { question { id } } }`,
+			message: "Глубина запроса 4 превышает допустимую 3",
+		},
+		{
+			name:    "default list size",
+			query:   `{ questions { id text answerCount createdAt answers { id } } }`,
+			message: "Сложность запроса 301 превышает допустимую 100",
+		},
+		{
+			name:    "limit from variable",
+			query:   `query($n: Int) { questions(limit: $n) { id text } }`,
+			vars:    map[string]interface{}{"n": float64(60)},
+			message: "Сложность запроса 121 превышает допустимую 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := executor.Execute(context.Background(), Request{Query: tt.query, Variables: tt.vars})
+			if len(result.Errors) != 1 || result.Errors[0].Message != tt.message {
+				t.Fatalf("expected %q, got %v", tt.message, result.Errors)
+			}
+			if result.Errors[0].Extensions["code"] != "BAD_REQUEST" {
+				t.Errorf("expected code BAD_REQUEST, got %v", result.Errors[0].Extensions)
+			}
+		})
+	}
+
+	// интроспекция не учитывается в ограничениях
+	result := executor.Execute(context.Background(), Request{Query: `{ __schema { types { name fields { name type { name } } } } }`})
+	if len(result.Errors) != 0 {
+		t.Errorf("expected introspection to pass limits, got %v", result.Errors)
+	}
+}
+
+func TestExecute_LimitsFragmentChain(t *testing.T) {
+	executor := newTestExecutor(t, &mockQuestionService{}, &mockAnswerService{}, Limits{MaxComplexity: 100})
+
+	// каждый фрагмент включает следующий дважды: без кэша обход растёт как 2^40
+	var query strings.Builder
+	query.WriteString(`{ question(id: 1) { ...f0 } }`)
+	for i := 0; i < 40; i++ {
+		fmt.Fprintf(&query, " fragment f%d on Question { ...f%d ...f%d }", i, i+1, i+1)
+	}
+	query.WriteString(" fragment f40 on Question { id }")
+
+	done := make(chan *graphql.Result, 1)
+	go func() { done <- executor.Execute(context.Background(), Request{Query: query.String()}) }()
+	select {
+	case result := <-done:
+		if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0].Message, "Сложность запроса") {
+			t.Errorf("expected complexity error, got %v", result.Errors)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected fragment chain to be rejected quickly")
+	}
+
+	// большой limit насыщает оценку, а не переполняет int
+	result := executor.Execute(context.Background(), Request{
+		Query: `{ questions(limit: 2147483647) { answers(limit: 2147483647) { question { answers(limit: 2147483647) { id } } } } }`,
+	})
+	if len(result.Errors) != 1 || !strings.HasPrefix(result.Errors[0].Message, "Сложность запроса") {
+		t.Errorf("expected complexity error, got %v", result.Errors)
+	}
+}
+
+func TestExecute_Mutations(t *testing.T) {
+	questionService := &mockQuestionService{
+		createQuestion: func(ctx context.Context, text string) (*entity.Question, error) {
+			return &entity.Question{ID: 5, Text: text}, nil
+		},
+	}
+	var deleted int
+	answerService := &mockAnswerService{
+		deleteAnswer: func(ctx context.Context, id, version int) error {
+			deleted = id
+			return nil
+		},
+	}
+	executor := newTestExecutor(t, questionService, answerService, Limits{})
+
+	result := executor.Execute(context.Background(), Request{
+		Query:     `mutation($text: String!) { createQuestion(text: $text) { id text } deleteAnswer(id: 7) }`,
+		Variables: map[string]interface{}{"text": "Что такое Go?"},
+	})
+
+	var data struct {
+		CreateQuestion struct {
+			ID   int
+			Text string
+		}
+		DeleteAnswer bool
+	}
+	decode(t, result, &data)
+	if data.CreateQuestion.ID != 5 || data.CreateQuestion.Text != "Что такое Go?" {
+		t.Errorf("expected created question 5, got %+v", data.CreateQuestion)
+	}
+	if !data.DeleteAnswer || deleted != 7 {
+		t.Errorf("expected answer 7 to be deleted, got %v and %d", data.DeleteAnswer, deleted)
+	}
+}
+
+func TestExecute_CreateAnswerRequiresToken(t *testing.T) {
+	answerService := &mockAnswerService{
+		createAnswer: func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+			return &entity.Answer{ID: 9, QuestionID: questionID, UserID: service.AuditMetaFromContext(ctx).Actor, Text: text}, nil
+		},
+	}
+	executor := newTestExecutor(t, &mockQuestionService{}, answerService, Limits{})
+	query := `mutation { createAnswer(questionId: 1, text: "Начните с Tour of Go") { id author { id } } }`
+
+	result := executor.Execute(context.Background(), Request{Query: query})
+	if len(result.Errors) != 1 || result.Errors[0].Extensions["status"] != 401 {
+		t.Fatalf("expected 401 without token, got %v", result.Errors)
+	}
+
+	ctx := service.WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "alice"})
+	var data struct {
+		CreateAnswer struct {
+			ID     int
+			Author struct{ ID string }
+		}
+	}
+	decode(t, executor.Execute(ctx, Request{Query: query}), &data)
+	if data.CreateAnswer.ID != 9 || data.CreateAnswer.Author.ID != "alice" {
+		t.Errorf("expected answer 9 by alice, got %+v", data.CreateAnswer)
+	}
+}
+
+func TestExecute_ErrorExtensions(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		message string
+		code    string
+		status  int
+	}{
+		{"custom error", entity.ErrQuestionAlreadyExists, entity.ErrQuestionAlreadyExists.Message, "CONFLICT", 409},
+		{"unexpected error", errors.New("connection refused"), entity.ErrDatabaseQuery.Message, "INTERNAL_SERVER_ERROR", 500},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionService := &mockQuestionService{
+				createQuestion: func(ctx context.Context, text string) (*entity.Question, error) {
+					return nil, tt.err
+				},
+			}
+			executor := newTestExecutor(t, questionService, &mockAnswerService{}, Limits{})
+
+			result := executor.Execute(context.Background(), Request{Query: `mutation { createQuestion(text: "Q") { id } }`})
+			if len(result.Errors) != 1 {
+				t.Fatalf("expected one error, got %v", result.Errors)
+			}
+			got := result.Errors[0]
+			if got.Message != tt.message || got.Extensions["code"] != tt.code || got.Extensions["status"] != tt.status {
+				t.Errorf("expected %q with %s/%d, got %q with %v", tt.message, tt.code, tt.status, got.Message, got.Extensions)
+			}
+			if len(got.Path) != 1 || got.Path[0] != "createQuestion" {
+				t.Errorf("expected path [createQuestion], got %v", got.Path)
+			}
+		})
+	}
+}
+
+func TestExecute_NotFoundIsNull(t *testing.T) {
+	questionService := &mockQuestionService{
+		getByIDs: func(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+			return nil, ids, nil
+		},
+	}
+	executor := newTestExecutor(t, questionService, &mockAnswerService{}, Limits{})
+
+	result := executor.Execute(context.Background(), Request{Query: `{ question(id: 42) { id } }`})
+	var data struct{ Question *struct{ ID int } }
+	decode(t, result, &data)
+	if data.Question != nil {
+		t.Errorf("expected null question, got %+v", data.Question)
+	}
+}
+
+func TestExecute_InvalidQuery(t *testing.T) {
+	executor := newTestExecutor(t, &mockQuestionService{}, &mockAnswerService{}, Limits{})
+
+	for _, query := range []string{`{ questions { id `, `{ questions { unknown } }`} {
+		result := executor.Execute(context.Background(), Request{Query: query})
+		if len(result.Errors) == 0 || result.Data != nil {
+			t.Errorf("expected errors without data for %q, got %v", query, result)
+		}
+		if len(result.Errors) > 0 && strings.Contains(result.Errors[0].Message, entity.ErrDatabaseQuery.Message) {
+			t.Errorf("expected a syntax or validation message for %q, got %q", query, result.Errors[0].Message)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package graphqlapi
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// assumedListSize оценка длины списка без ограничения по умолчанию (ответы вопроса) при расчёте сложности
+const assumedListSize = 10
+
+// Limits ограничения запроса; 0 отключает ограничение
+type Limits struct {
+	// MaxDepth максимальная вложенность полей
+	MaxDepth int
+	// MaxComplexity максимальная оценка числа разрешаемых полей: каждое поле стоит 1,
+	// поля-списки умножают стоимость вложенных полей на limit или размер списка по умолчанию
+	MaxComplexity int
+}
+
+// maxCost предел оценки сложности: суммы и произведения насыщаются на нём, чтобы большой limit
+// не переполнил int
+const maxCost = math.MaxInt32
+
+// queryCost глубина и сложность операции. Поля интроспекции (__schema, __type) не считаются,
+// чтобы инструменты вроде GraphiQL работали при любых ограничениях
+type queryCost struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	// costs глубина и сложность уже посчитанных фрагментов: каждый фрагмент обходится один раз,
+	// сколько бы раз его ни включали, иначе цепочка фрагментов, включающих следующий дважды, растёт экспоненциально
+	costs map[string]fragmentCost
+	// visiting фрагменты на текущем пути обхода; защищает от циклов, которые отклонит валидация,
+	// но до неё обход не должен зациклиться
+	visiting map[string]bool
+	// budget MaxComplexity; обход прекращается, как только сложность его превысила (exceeded)
+	budget   int
+	exceeded bool
+}
+
+type fragmentCost struct {
+	depth, complexity int
+}
+
+func checkLimits(doc *ast.Document, operationName string, variables map[string]interface{}, limits Limits) error {
+	var operations []*ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operations = append(operations, definition)
+			}
+		}
+	}
+
+	for _, operation := range operations {
+		cost := &queryCost{
+			fragments: fragments,
+			variables: variables,
+			costs:     map[string]fragmentCost{},
+			visiting:  map[string]bool{},
+			budget:    limits.MaxComplexity,
+		}
+		depth, complexity := cost.selectionSet(operation.SelectionSet)
+		if limits.MaxDepth > 0 && depth > limits.MaxDepth {
+			return entity.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Глубина запроса %d превышает допустимую %d", depth, limits.MaxDepth),
+			}
+		}
+		if limits.MaxComplexity > 0 && (cost.exceeded || complexity > limits.MaxComplexity) {
+			return entity.CustomError{
+				Code:    http.StatusBadRequest,
+				Message: fmt.Sprintf("Сложность запроса %d превышает допустимую %d", complexity, limits.MaxComplexity),
+			}
+		}
+	}
+	return nil
+}
+
+// selectionSet возвращает глубину и сложность набора полей. После превышения бюджета сложности
+// возвращает то, что успел насчитать: запрос всё равно будет отклонён
+func (c *queryCost) selectionSet(set *ast.SelectionSet) (depth, complexity int) {
+	if set == nil {
+		return 0, 0
+	}
+	for _, selection := range set.Selections {
+		if c.exceeded {
+			return depth, complexity
+		}
+		var d, cx int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name.Value, "__") {
+				continue
+			}
+			childDepth, childComplexity := c.selectionSet(selection.SelectionSet)
+			d = childDepth + 1
+			cx = addCost(1, mulCost(c.multiplier(selection), childComplexity))
+		case *ast.InlineFragment:
+			d, cx = c.selectionSet(selection.SelectionSet)
+		case *ast.FragmentSpread:
+			d, cx = c.fragment(selection.Name.Value)
+		}
+		depth = max(depth, d)
+		complexity = addCost(complexity, cx)
+		if c.budget > 0 && complexity > c.budget {
+			c.exceeded = true
+		}
+	}
+	return depth, complexity
+}
+
+// fragment глубина и сложность фрагмента из кэша; неизвестный фрагмент и цикл ничего не стоят
+func (c *queryCost) fragment(name string) (depth, complexity int) {
+	if cost, ok := c.costs[name]; ok {
+		return cost.depth, cost.complexity
+	}
+	fragment, ok := c.fragments[name]
+	if !ok || c.visiting[name] {
+		return 0, 0
+	}
+	c.visiting[name] = true
+	depth, complexity = c.selectionSet(fragment.SelectionSet)
+	delete(c.visiting, name)
+	c.costs[name] = fragmentCost{depth: depth, complexity: complexity}
+	return depth, complexity
+}
+
+func addCost(a, b int) int {
+	if a > maxCost-b {
+		return maxCost
+	}
+	return a + b
+}
+
+func mulCost(a, b int) int {
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+	return a * b
+}
+
+// multiplier число элементов, которое может вернуть поле: значение limit, если оно известно,
+// иначе размер списка по умолчанию из listDefaults; 1 для полей, не являющихся списками
+func (c *queryCost) multiplier(field *ast.Field) int {
+	if field.SelectionSet == nil {
+		return 1
+	}
+	size, isList := listDefaults[field.Name.Value]
+	if !isList {
+		return 1
+	}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return min(n, maxCost)
+			}
+		case *ast.Variable:
+			switch n := c.variables[value.Name.Value].(type) {
+			case int:
+				return min(max(n, 1), maxCost)
+			case float64:
+				return int(min(max(n, 1), maxCost))
+			}
+		}
+	}
+	return size
+}
@@ -0,0 +1,98 @@
+package graphqlapi
+
+import (
+	"context"
+	"sync"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+type loadersContextKey struct{}
+
+// loaders живут один запрос: кэшируют загруженное и собирают ключи в пачки
+type loaders struct {
+	answers   *batchLoader[[]entity.Answer]
+	questions *batchLoader[*entity.Question]
+}
+
+func withLoaders(ctx context.Context, questionService service.QuestionService, answerService service.AnswerService) context.Context {
+	return context.WithValue(ctx, loadersContextKey{}, &loaders{
+		answers: newBatchLoader(answerService.GetAnswersByQuestionIDs),
+		questions: newBatchLoader(func(ctx context.Context, ids []int) (map[int]*entity.Question, error) {
+			questions, _, err := questionService.GetQuestionsByIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int]*entity.Question, len(questions))
+			for i := range questions {
+				byID[questions[i].ID] = &questions[i]
+			}
+			return byID, nil
+		}),
+	})
+}
+
+func loadersFromContext(ctx context.Context) *loaders {
+	return ctx.Value(loadersContextKey{}).(*loaders)
+}
+
+// batchLoader загружает значения по ID пачкой. Load только запоминает ID и возвращает thunk;
+// исполнитель graphql-go вызывает thunks после обхода всего уровня запроса, поэтому
+// первый вызванный thunk загружает все ID уровня одним вызовом fetch
+type batchLoader[V any] struct {
+	fetch func(ctx context.Context, ids []int) (map[int]V, error)
+
+	mu      sync.Mutex
+	pending []int
+	queued  map[int]bool
+	done    map[int]bool
+	values  map[int]V
+	errs    map[int]error
+}
+
+func newBatchLoader[V any](fetch func(ctx context.Context, ids []int) (map[int]V, error)) *batchLoader[V] {
+	return &batchLoader[V]{
+		fetch:  fetch,
+		queued: map[int]bool{},
+		done:   map[int]bool{},
+		values: map[int]V{},
+		errs:   map[int]error{},
+	}
+}
+
+// Load возвращает thunk со значением для id; для ID, которого нет в результате fetch, — нулевое значение
+func (l *batchLoader[V]) Load(ctx context.Context, id int) func() (V, error) {
+	l.mu.Lock()
+	if !l.done[id] && !l.queued[id] {
+		l.pending = append(l.pending, id)
+		l.queued[id] = true
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.done[id] {
+			l.flush(ctx)
+		}
+		return l.values[id], l.errs[id]
+	}
+}
+
+// flush загружает накопленные ID; ошибка достаётся всем thunks пачки, а не только первому
+func (l *batchLoader[V]) flush(ctx context.Context) {
+	batch := l.pending
+	l.pending, l.queued = nil, map[int]bool{}
+
+	values, err := l.fetch(ctx, batch)
+	for _, id := range batch {
+		l.done[id] = true
+		if err != nil {
+			l.errs[id] = err
+			continue
+		}
+		l.values[id] = values[id]
+	}
+}
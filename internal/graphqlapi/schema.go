@@ -0,0 +1,266 @@
+package graphqlapi
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	defaultQuestionsLimit = 20
+	maxQuestionsLimit     = 100
+)
+
+// listDefaults число элементов, которое поле-список возвращает без аргумента limit;
+// используется при расчёте сложности запроса
+var listDefaults = map[string]int{
+	"questions": defaultQuestionsLimit,
+	"answers":   assumedListSize,
+}
+
+var errInvalidLimit = entity.CustomError{
+	Code:    http.StatusBadRequest,
+	Message: "Аргумент limit должен быть числом от 1 до " + strconv.Itoa(maxQuestionsLimit),
+}
+
+// newSchema собирает схему: вопросы, ответы и их авторов. Вложенные ответы и вопросы
+// разрешаются через loaders из контекста, поэтому Execute должен положить их туда
+func newSchema(questionService service.QuestionService, answerService service.AnswerService) (graphql.Schema, error) {
+	userType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "User",
+		Description: "Автор ответа",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	questionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Question",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Question).ID, nil },
+			},
+			"text": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Question).Text, nil },
+			},
+			"createdAt": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Question).CreatedAt, nil },
+			},
+		},
+	})
+
+	answerType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Answer",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Answer).ID, nil },
+			},
+			"questionId": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Int),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Answer).QuestionID, nil },
+			},
+			"text": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Answer).Text, nil },
+			},
+			"createdAt": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.DateTime),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) { return p.Source.(*entity.Answer).CreatedAt, nil },
+			},
+			"author": &graphql.Field{
+				Type: graphql.NewNonNull(userType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return map[string]interface{}{"id": p.Source.(*entity.Answer).UserID}, nil
+				},
+			},
+		},
+	})
+
+	// Question и Answer ссылаются друг на друга, поэтому эти поля добавляются после создания типов
+	questionType.AddFieldConfig("answers", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(answerType))),
+		Description: "Ответы, новые первыми",
+		Args: graphql.FieldConfigArgument{
+			"limit": &graphql.ArgumentConfig{Type: graphql.Int},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			limit, hasLimit := p.Args["limit"].(int)
+			if hasLimit && limit < 1 {
+				return nil, errInvalidLimit
+			}
+			load := loadersFromContext(p.Context).answers.Load(p.Context, p.Source.(*entity.Question).ID)
+			return func() (interface{}, error) {
+				answers, err := load()
+				if err != nil {
+					return nil, err
+				}
+				if answers == nil {
+					answers = []entity.Answer{}
+				}
+				if hasLimit && len(answers) > limit {
+					answers = answers[:limit]
+				}
+				return answerPointers(answers), nil
+			}, nil
+		},
+	})
+	questionType.AddFieldConfig("answerCount", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.Int),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			load := loadersFromContext(p.Context).answers.Load(p.Context, p.Source.(*entity.Question).ID)
+			return func() (interface{}, error) {
+				answers, err := load()
+				if err != nil {
+					return nil, err
+				}
+				return len(answers), nil
+			}, nil
+		},
+	})
+	answerType.AddFieldConfig("question", &graphql.Field{
+		Type: questionType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			load := loadersFromContext(p.Context).questions.Load(p.Context, p.Source.(*entity.Answer).QuestionID)
+			return func() (interface{}, error) {
+				question, err := load()
+				if err != nil || question == nil {
+					return nil, err
+				}
+				return question, nil
+			}, nil
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"questions": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(questionType))),
+				Description: "Вопросы с ID больше after в порядке ID",
+				Args: graphql.FieldConfigArgument{
+					"after": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+					"limit": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultQuestionsLimit},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					after, _ := p.Args["after"].(int)
+					limit, _ := p.Args["limit"].(int)
+					if limit < 1 || limit > maxQuestionsLimit {
+						return nil, errInvalidLimit
+					}
+					questions, err := questionService.GetQuestionsPage(p.Context, max(after, 0), limit)
+					if err != nil {
+						return nil, err
+					}
+					result := make([]*entity.Question, len(questions))
+					for i := range questions {
+						result[i] = &questions[i]
+					}
+					return result, nil
+				},
+			},
+			"question": &graphql.Field{
+				Type: questionType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					question, err := loadersFromContext(p.Context).questions.Load(p.Context, p.Args["id"].(int))()
+					if err != nil || question == nil {
+						return nil, err
+					}
+					return question, nil
+				},
+			},
+			"answer": &graphql.Field{
+				Type: answerType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					answer, err := answerService.GetAnswer(p.Context, p.Args["id"].(int))
+					if errors.Is(err, entity.ErrAnswerNotFound) {
+						return nil, nil
+					}
+					if err != nil {
+						return nil, err
+					}
+					return answer, nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createQuestion": &graphql.Field{
+				Type: graphql.NewNonNull(questionType),
+				Args: graphql.FieldConfigArgument{
+					"text": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return questionService.CreateQuestion(p.Context, p.Args["text"].(string))
+				},
+			},
+			"deleteQuestion": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := questionService.DeleteQuestion(p.Context, p.Args["id"].(int), 0); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"createAnswer": &graphql.Field{
+				Type: graphql.NewNonNull(answerType),
+				Args: graphql.FieldConfigArgument{
+					"questionId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+					// userId необязателен: автор — авторизованный пользователь
+					"userId": &graphql.ArgumentConfig{Type: graphql.String},
+					"text":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if service.AuditMetaFromContext(p.Context).Actor == "" {
+						return nil, entity.ErrUnauthorized
+					}
+					userID, _ := p.Args["userId"].(string)
+					return answerService.CreateAnswer(p.Context, p.Args["questionId"].(int), userID, p.Args["text"].(string))
+				},
+			},
+			"deleteAnswer": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := answerService.DeleteAnswer(p.Context, p.Args["id"].(int), 0); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Mutation: mutationType})
+}
+
+func answerPointers(answers []entity.Answer) []*entity.Answer {
+	result := make([]*entity.Answer, len(answers))
+	for i := range answers {
+		result[i] = &answers[i]
+	}
+	return result
+}
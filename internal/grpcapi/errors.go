@@ -0,0 +1,67 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// toStatus переводит ошибку сервиса в статус gRPC по HTTP коду entity.CustomError; поле ошибки
+// валидации передаётся в деталях BadRequest. Прочие ошибки скрываются за Internal, как в sendCustomError REST API
+func toStatus(err error) error {
+	var customErr entity.CustomError
+	if errors.As(err, &customErr) {
+		st := status.New(codeFromHTTP(customErr.Code), customErr.Message)
+		if customErr.Field != "" {
+			detailed, detailsErr := st.WithDetails(&errdetails.BadRequest{
+				FieldViolations: []*errdetails.BadRequest_FieldViolation{
+					{Field: customErr.Field, Description: customErr.Message},
+				},
+			})
+			if detailsErr == nil {
+				st = detailed
+			}
+		}
+		return st.Err()
+	}
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	}
+	log.Printf("Ошибка gRPC обработчика: %v", err)
+	return status.Error(codes.Internal, entity.ErrDatabaseQuery.Message)
+}
+
+func codeFromHTTP(code int) codes.Code {
+	switch code {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	case http.StatusServiceUnavailable:
+		return codes.Unavailable
+	}
+	if code >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
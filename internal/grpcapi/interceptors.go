@@ -0,0 +1,107 @@
+package grpcapi
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+)
+
+const (
+	// requestIDMetadataKey ключ метаданных с ID запроса, аналог заголовка X-Request-ID
+	requestIDMetadataKey = "x-request-id"
+	// authorizationMetadataKey ключ метаданных с токеном доступа, аналог заголовка Authorization
+	authorizationMetadataKey = "authorization"
+)
+
+// RecoverInterceptor превращает панику обработчика в Internal, как RecoverMiddleware для HTTP
+func RecoverInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Паника восстановлена в %s: %v", info.FullMethod, r)
+			err = status.Error(codes.Internal, "Внутренняя ошибка сервера")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// LogInterceptor пишет метод, адрес клиента, код ответа и длительность, как LogMiddleware для HTTP
+func LogInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	addr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+	duration := time.Since(start).Milliseconds()
+	log.Printf("[gRPC] %s %s - код: %s (%.0fms)", info.FullMethod, addr, status.Code(err), float64(duration))
+	return resp, err
+}
+
+// AuditInterceptor кладёт в контекст данные для журнала аудита: ID запроса из метаданных x-request-id
+// (или сгенерированный) и IP клиента. Автора заполняет AuthInterceptor
+func AuditInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	meta := entity.AuditMeta{RequestID: service.NewRequestID()}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDMetadataKey); len(values) > 0 && service.ValidRequestID(values[0]) {
+			meta.RequestID = values[0]
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		meta.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(meta.IP); err == nil {
+			meta.IP = host
+		}
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadataKey, meta.RequestID))
+	return handler(service.WithAuditMeta(ctx, meta), req)
+}
+
+// AuthInterceptor проверяет токен из метаданных authorization: Bearer и записывает пользователя автором
+// в данные аудита, как AuthMiddleware для HTTP. Вызовы без токена проходят анонимно, сервисы сами решают,
+// нужна ли авторизация. Должен стоять после AuditInterceptor
+func AuthInterceptor(authService service.AuthService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		token := bearerToken(ctx)
+		if token == "" {
+			return handler(ctx, req)
+		}
+
+		userID, err := authService.Authenticate(ctx, token)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		meta := service.AuditMetaFromContext(ctx)
+		meta.Actor = userID
+		return handler(service.WithAuditMeta(ctx, meta), req)
+	}
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	values := md.Get(authorizationMetadataKey)
+	if len(values) == 0 {
+		return ""
+	}
+	scheme, token, found := strings.Cut(values[0], " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,171 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+	questionsv1 "github.com/andrey-samosuk/answer-questions/proto/questions/v1"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 100
+)
+
+// Server реализация questionsv1.QuestionsServiceServer поверх тех же сервисов, что и REST API
+type Server struct {
+	questionsv1.UnimplementedQuestionsServiceServer
+	questionService service.QuestionService
+	answerService   service.AnswerService
+	requestTimeout  int
+}
+
+func NewServer(questionService service.QuestionService, answerService service.AnswerService, requestTimeout int) *Server {
+	return &Server{
+		questionService: questionService,
+		answerService:   answerService,
+		requestTimeout:  requestTimeout,
+	}
+}
+
+// NewGRPCServer создаёт gRPC сервер с interceptors восстановления после паники, логирования, аудита
+// и аутентификации и регистрирует в нём Server. Reflection включён для grpcurl и подобных инструментов
+func NewGRPCServer(server *Server, authService service.AuthService) *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.ChainUnaryInterceptor(RecoverInterceptor, LogInterceptor, AuditInterceptor, AuthInterceptor(authService)))
+	questionsv1.RegisterQuestionsServiceServer(grpcServer, server)
+	reflection.Register(grpcServer)
+	return grpcServer
+}
+
+func (s *Server) CreateQuestion(ctx context.Context, req *questionsv1.CreateQuestionRequest) (*questionsv1.Question, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	question, err := s.questionService.CreateQuestion(ctx, req.GetText())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return questionToProto(question), nil
+}
+
+func (s *Server) GetQuestion(ctx context.Context, req *questionsv1.GetQuestionRequest) (*questionsv1.Question, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	question, err := s.questionService.GetQuestion(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	answers, err := s.answerService.GetAnswersByQuestion(ctx, question.ID)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := questionToProto(question)
+	response.Answers = make([]*questionsv1.Answer, len(answers))
+	for i := range answers {
+		response.Answers[i] = answerToProto(&answers[i])
+	}
+	return response, nil
+}
+
+func (s *Server) ListQuestions(ctx context.Context, req *questionsv1.ListQuestionsRequest) (*questionsv1.ListQuestionsResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	pageSize := int(req.GetPageSize())
+	if pageSize == 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, status.Errorf(codes.InvalidArgument, "page_size должен быть от 1 до %d", maxPageSize)
+	}
+	if req.GetAfterId() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "after_id не может быть отрицательным")
+	}
+
+	// лишний вопрос показывает, что за страницей есть ещё
+	questions, err := s.questionService.GetQuestionsPage(ctx, int(req.GetAfterId()), pageSize+1)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	response := &questionsv1.ListQuestionsResponse{}
+	if len(questions) > pageSize {
+		questions = questions[:pageSize]
+		response.NextAfterId = int64(questions[pageSize-1].ID)
+	}
+	response.Questions = make([]*questionsv1.Question, len(questions))
+	for i := range questions {
+		response.Questions[i] = &questionsv1.Question{Id: int64(questions[i].ID), Text: questions[i].Text}
+	}
+	return response, nil
+}
+
+func (s *Server) DeleteQuestion(ctx context.Context, req *questionsv1.DeleteQuestionRequest) (*questionsv1.DeleteQuestionResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	if err := s.questionService.DeleteQuestion(ctx, int(req.GetId()), 0); err != nil {
+		return nil, toStatus(err)
+	}
+	return &questionsv1.DeleteQuestionResponse{}, nil
+}
+
+func (s *Server) CreateAnswer(ctx context.Context, req *questionsv1.CreateAnswerRequest) (*questionsv1.Answer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	answer, err := s.answerService.CreateAnswer(ctx, int(req.GetQuestionId()), req.GetUserId(), req.GetText())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return answerToProto(answer), nil
+}
+
+func (s *Server) GetAnswer(ctx context.Context, req *questionsv1.GetAnswerRequest) (*questionsv1.Answer, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	answer, err := s.answerService.GetAnswer(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return answerToProto(answer), nil
+}
+
+func (s *Server) DeleteAnswer(ctx context.Context, req *questionsv1.DeleteAnswerRequest) (*questionsv1.DeleteAnswerResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(s.requestTimeout)*time.Second)
+	defer cancel()
+
+	if err := s.answerService.DeleteAnswer(ctx, int(req.GetId()), 0); err != nil {
+		return nil, toStatus(err)
+	}
+	return &questionsv1.DeleteAnswerResponse{}, nil
+}
+
+func questionToProto(question *entity.Question) *questionsv1.Question {
+	return &questionsv1.Question{
+		Id:        int64(question.ID),
+		Text:      question.Text,
+		CreatedAt: timestamppb.New(question.CreatedAt),
+	}
+}
+
+func answerToProto(answer *entity.Answer) *questionsv1.Answer {
+	return &questionsv1.Answer{
+		Id:         int64(answer.ID),
+		QuestionId: int64(answer.QuestionID),
+		UserId:     answer.UserID,
+		Text:       answer.Text,
+		CreatedAt:  timestamppb.New(answer.CreatedAt),
+	}
+}
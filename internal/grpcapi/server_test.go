@@ -0,0 +1,214 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/service"
+	questionsv1 "github.com/andrey-samosuk/answer-questions/proto/questions/v1"
+)
+
+type mockQuestionService struct {
+	service.QuestionService
+	getQuestion    func(ctx context.Context, id int) (*entity.Question, error)
+	getPage        func(ctx context.Context, afterID, limit int) ([]entity.Question, error)
+	createQuestion func(ctx context.Context, text string) (*entity.Question, error)
+}
+
+func (m *mockQuestionService) GetQuestion(ctx context.Context, id int) (*entity.Question, error) {
+	return m.getQuestion(ctx, id)
+}
+
+func (m *mockQuestionService) GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	return m.getPage(ctx, afterID, limit)
+}
+
+func (m *mockQuestionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
+	return m.createQuestion(ctx, text)
+}
+
+type mockAnswerService struct {
+	service.AnswerService
+	getAnswersByQuestion func(ctx context.Context, questionID int) ([]entity.Answer, error)
+	createAnswer         func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error)
+}
+
+func (m *mockAnswerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+	return m.createAnswer(ctx, questionID, userID, text)
+}
+
+func (m *mockAnswerService) GetAnswersByQuestion(ctx context.Context, questionID int) ([]entity.Answer, error) {
+	return m.getAnswersByQuestion(ctx, questionID)
+}
+
+// newTestClient поднимает gRPC сервер с теми же interceptors, что и в приложении, поверх bufconn
+func newTestClient(t *testing.T, questionService service.QuestionService, answerService service.AnswerService) questionsv1.QuestionsServiceClient {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	grpcServer := NewGRPCServer(NewServer(questionService, answerService, 5), service.NewStaticTokenAuthService(map[string]string{"alice-token": "alice"}))
+	go func() { _ = grpcServer.Serve(listener) }()
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return questionsv1.NewQuestionsServiceClient(conn)
+}
+
+func TestGetQuestion_WithAnswers(t *testing.T) {
+	questionService := &mockQuestionService{
+		getQuestion: func(ctx context.Context, id int) (*entity.Question, error) {
+			return &entity.Question{ID: id, Text: "What is Go?"}, nil
+		},
+	}
+	answerService := &mockAnswerService{
+		getAnswersByQuestion: func(ctx context.Context, questionID int) ([]entity.Answer, error) {
+			return []entity.Answer{{ID: 7, QuestionID: questionID, UserID: "alice", Text: "A language"}}, nil
+		},
+	}
+	client := newTestClient(t, questionService, answerService)
+
+	question, err := client.GetQuestion(context.Background(), &questionsv1.GetQuestionRequest{Id: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if question.GetId() != 3 || question.GetText() != "What is Go?" {
+		t.Errorf("expected question 3, got %v", question)
+	}
+	if len(question.GetAnswers()) != 1 || question.GetAnswers()[0].GetUserId() != "alice" {
+		t.Errorf("expected one answer by alice, got %v", question.GetAnswers())
+	}
+}
+
+func TestListQuestions_Pages(t *testing.T) {
+	questionService := &mockQuestionService{
+		getPage: func(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+			var page []entity.Question
+			for id := afterID + 1; id <= 3 && len(page) < limit; id++ {
+				page = append(page, entity.Question{ID: id, Text: "Q"})
+			}
+			return page, nil
+		},
+	}
+	client := newTestClient(t, questionService, &mockAnswerService{})
+
+	resp, err := client.ListQuestions(context.Background(), &questionsv1.ListQuestionsRequest{PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetQuestions()) != 2 || resp.GetNextAfterId() != 2 {
+		t.Errorf("expected 2 questions and next_after_id 2, got %d and %d", len(resp.GetQuestions()), resp.GetNextAfterId())
+	}
+
+	resp, err = client.ListQuestions(context.Background(), &questionsv1.ListQuestionsRequest{AfterId: 2, PageSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(resp.GetQuestions()) != 1 || resp.GetNextAfterId() != 0 {
+		t.Errorf("expected last page with 1 question, got %d and next_after_id %d", len(resp.GetQuestions()), resp.GetNextAfterId())
+	}
+
+	_, err = client.ListQuestions(context.Background(), &questionsv1.ListQuestionsRequest{PageSize: 1000})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument, got %v", err)
+	}
+}
+
+func TestErrorsMapToStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected codes.Code
+		message  string
+	}{
+		{"not found", entity.ErrQuestionNotFound, codes.NotFound, entity.ErrQuestionNotFound.Message},
+		{"invalid", entity.ErrInvalidQuestionText, codes.InvalidArgument, entity.ErrInvalidQuestionText.Message},
+		{"conflict", entity.ErrQuestionAlreadyExists, codes.AlreadyExists, entity.ErrQuestionAlreadyExists.Message},
+		{"unexpected", errors.New("connection refused"), codes.Internal, entity.ErrDatabaseQuery.Message},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			questionService := &mockQuestionService{
+				createQuestion: func(ctx context.Context, text string) (*entity.Question, error) {
+					return nil, tt.err
+				},
+			}
+			client := newTestClient(t, questionService, &mockAnswerService{})
+
+			_, err := client.CreateQuestion(context.Background(), &questionsv1.CreateQuestionRequest{Text: "Q"})
+			st := status.Convert(err)
+			if st.Code() != tt.expected || st.Message() != tt.message {
+				t.Errorf("expected %s %q, got %s %q", tt.expected, tt.message, st.Code(), st.Message())
+			}
+		})
+	}
+}
+
+func TestRecoverInterceptor(t *testing.T) {
+	questionService := &mockQuestionService{
+		createQuestion: func(ctx context.Context, text string) (*entity.Question, error) {
+			panic("boom")
+		},
+	}
+	client := newTestClient(t, questionService, &mockAnswerService{})
+
+	_, err := client.CreateQuestion(context.Background(), &questionsv1.CreateQuestionRequest{Text: "Q"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected Internal after panic, got %v", err)
+	}
+
+	// сервер продолжает обслуживать запросы после паники
+	_, err = client.CreateQuestion(context.Background(), &questionsv1.CreateQuestionRequest{Text: "Q"})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("expected server to keep serving, got %v", err)
+	}
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	var actor string
+	answerService := &mockAnswerService{
+		createAnswer: func(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+			actor = service.AuditMetaFromContext(ctx).Actor
+			return &entity.Answer{ID: 1, QuestionID: questionID, UserID: actor, Text: text}, nil
+		},
+	}
+	client := newTestClient(t, &mockQuestionService{}, answerService)
+	req := &questionsv1.CreateAnswerRequest{QuestionId: 1, UserId: "alice", Text: "A"}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer wrong-token")
+	if _, err := client.CreateAnswer(ctx, req); status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated for an unknown token, got %v", err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer alice-token")
+	answer, err := client.CreateAnswer(ctx, req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if actor != "alice" || answer.GetUserId() != "alice" {
+		t.Errorf("expected actor alice, got %q", actor)
+	}
+
+	// без токена вызов проходит анонимно, а авторизацию проверяет сервис
+	if _, err := client.CreateAnswer(context.Background(), req); err != nil || actor != "" {
+		t.Errorf("expected anonymous call to reach the service without actor, got %q, %v", actor, err)
+	}
+}
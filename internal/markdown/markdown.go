@@ -0,0 +1,74 @@
+package markdown
+
+import (
+	"strings"
+
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// maxNesting наибольшая вложенность списков и цитат. Разбор глубоких вложений квадратичен по длине текста,
+// поэтому маркер списка или цитаты глубже этого уровня остаётся текстом абзаца
+const maxNesting = 32
+
+// converter разбирает CommonMark без HTML-блоков и HTML в строке: такой HTML остаётся текстом
+var converter = goldmark.New(goldmark.WithParser(parser.NewParser(
+	parser.WithBlockParsers(
+		util.Prioritized(parser.NewSetextHeadingParser(), 100),
+		util.Prioritized(parser.NewThematicBreakParser(), 200),
+		util.Prioritized(nestingLimit{parser.NewListParser()}, 300),
+		util.Prioritized(parser.NewListItemParser(), 400),
+		util.Prioritized(parser.NewCodeBlockParser(), 500),
+		util.Prioritized(parser.NewATXHeadingParser(), 600),
+		util.Prioritized(parser.NewFencedCodeBlockParser(), 700),
+		util.Prioritized(nestingLimit{parser.NewBlockquoteParser()}, 800),
+		util.Prioritized(parser.NewParagraphParser(), 1000),
+	),
+	parser.WithInlineParsers(
+		util.Prioritized(parser.NewCodeSpanParser(), 100),
+		util.Prioritized(parser.NewLinkParser(), 200),
+		util.Prioritized(parser.NewAutoLinkParser(), 300),
+		util.Prioritized(parser.NewEmphasisParser(), 500),
+	),
+	parser.WithParagraphTransformers(parser.DefaultParagraphTransformers()...),
+)))
+
+// Render переводит текст в разметке CommonMark в HTML. Поддерживаются абзацы, заголовки, блоки кода
+// (у огороженного блока с языком <code> получает класс language-<язык> для подсветки синтаксиса на клиенте),
+// цитаты, списки, линии, выделение, код в строке, ссылки, в том числе по ссылкам-определениям, и картинки.
+// HTML в тексте не исполняется, а показывается как текст; результат дополнительно проходит Sanitize
+func Render(source string) string {
+	source = strings.ReplaceAll(source, "\x00", "�")
+
+	var b strings.Builder
+	if err := converter.Convert([]byte(source), &b); err != nil {
+		return ""
+	}
+	return Sanitize(b.String())
+}
+
+// nestingLimit не даёт открыть список или цитату глубже maxNesting
+type nestingLimit struct {
+	parser.BlockParser
+}
+
+func (p nestingLimit) Open(parent ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	if nestingDepth(parent) >= maxNesting {
+		return nil, parser.NoChildren
+	}
+	return p.BlockParser.Open(parent, reader, pc)
+}
+
+// nestingDepth число списков и цитат среди node и его предков
+func nestingDepth(node ast.Node) int {
+	depth := 0
+	for ; node != nil; node = node.Parent() {
+		if node.Kind() == ast.KindList || node.Kind() == ast.KindBlockquote {
+			depth++
+		}
+	}
+	return depth
+}
@@ -0,0 +1,156 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	xhtml "golang.org/x/net/html"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{
+			"paragraph with emphasis and code",
+			"Почему `fmt.Println` **не** печатает *ничего*?",
+			"<p>Почему <code>fmt.Println</code> <strong>не</strong> печатает <em>ничего</em>?</p>\n",
+		},
+		{
+			"fenced code with language class",
+			"```go\nfunc main() {\n\tfmt.Println(\"<b>\")\n}\n```",
+			"<pre><code class=\"language-go\">func main() {\n\tfmt.Println(\"&lt;b&gt;\")\n}\n</code></pre>\n",
+		},
+		{
+			"fenced code with unsafe info string",
+			"```js\" onclick=\"x\ncode\n```",
+			"<pre><code>code\n</code></pre>\n",
+		},
+		{
+			"indented code",
+			"    a := 1\n\nтекст",
+			"<pre><code>a := 1\n</code></pre>\n<p>текст</p>\n",
+		},
+		{
+			"headings",
+			"# Один\nДва\n---",
+			"<h1>Один</h1>\n<h2>Два</h2>\n",
+		},
+		{
+			"tight nested list",
+			"- один\n- два\n  - вложенный",
+			"<ul>\n<li>один</li>\n<li>два\n<ul>\n<li>вложенный</li>\n</ul>\n</li>\n</ul>\n",
+		},
+		{
+			"loose ordered list",
+			"3. a\n\n4. b",
+			"<ol start=\"3\">\n<li>\n<p>a</p>\n</li>\n<li>\n<p>b</p>\n</li>\n</ol>\n",
+		},
+		{
+			"blockquote with lazy line and break",
+			"> цитата\nленивая\n\n***",
+			"<blockquote>\n<p>цитата\nленивая</p>\n</blockquote>\n<hr />\n",
+		},
+		{
+			"underscores inside words",
+			"snake_case_name и __strong__",
+			"<p>snake_case_name и <strong>strong</strong></p>\n",
+		},
+		{
+			"links, autolinks and entities",
+			"[Go](https://go.dev \"Сайт\") <a@b.co> &copy; &bogus;",
+			`<p><a href="https://go.dev" title="Сайт" rel="nofollow noopener">Go</a> <a href="mailto:a@b.co" rel="nofollow noopener">a@b.co</a> © &amp;bogus;</p>` + "\n",
+		},
+		{
+			"reference links",
+			"[Go][go] и [go]\n\n[go]: https://go.dev",
+			`<p><a href="https://go.dev" rel="nofollow noopener">Go</a> и <a href="https://go.dev" rel="nofollow noopener">go</a></p>` + "\n",
+		},
+		{
+			"html shown as text",
+			"<b>жирный</b>\n\n<div>блок</div>",
+			"<p>&lt;b&gt;жирный&lt;/b&gt;</p>\n<p>&lt;div&gt;блок&lt;/div&gt;</p>\n",
+		},
+		{
+			"hard line breaks",
+			"строка  \nперенос\\\nещё",
+			"<p>строка<br />\nперенос<br />\nещё</p>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.source); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRender_DeepNesting(t *testing.T) {
+	for _, marker := range []string{"- ", "* ", "> ", "1. ", "- > "} {
+		source := strings.Repeat(marker, 10000/len(marker)) + "x"
+		start := time.Now()
+		got := Render(source)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("render of %q nesting took %v", marker, elapsed)
+		}
+		if depth := strings.Count(got, "<ul>") + strings.Count(got, "<ol>") + strings.Count(got, "<blockquote>"); depth > maxNesting {
+			t.Errorf("expected at most %d nested blocks for %q, got %d", maxNesting, marker, depth)
+		}
+	}
+}
+
+func TestRender_XSS(t *testing.T) {
+	sources := []string{
+		"<script>alert(1)</script>",
+		"<img src=x onerror=alert(1)>",
+		"[клик](javascript:alert(1))",
+		"[клик](JAVASCRIPT:alert(1))",
+		"[клик](javas&#99;ript:alert(1))",
+		"[клик](\"onmouseover=alert(1))",
+		"![x](data:image/svg+xml,<svg onload=alert(1)>)",
+		"<javascript:alert(1)>",
+		"[x](https://example.com\" onclick=\"alert(1))",
+	}
+	for _, source := range sources {
+		got := Render(source)
+		tokenizer := xhtml.NewTokenizer(strings.NewReader(got))
+		for tt := tokenizer.Next(); tt != xhtml.ErrorToken; tt = tokenizer.Next() {
+			token := tokenizer.Token()
+			if tt != xhtml.StartTagToken && tt != xhtml.SelfClosingTagToken {
+				continue
+			}
+			if _, ok := allowedTags[token.Data]; !ok {
+				t.Errorf("render of %q contains tag %q: %s", source, token.Data, got)
+			}
+			for _, attr := range token.Attr {
+				value := strings.ToLower(attr.Val)
+				if strings.HasPrefix(attr.Key, "on") || strings.HasPrefix(value, "javascript:") || strings.HasPrefix(value, "data:") {
+					t.Errorf("render of %q contains attribute %s=%q", source, attr.Key, attr.Val)
+				}
+			}
+		}
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`<p onclick="x">a<script>alert(1)</script>b</p>`, "<p>ab</p>"},
+		{`<div><a href="https://go.dev" target="_blank">go</a></div>`, `<a href="https://go.dev" rel="nofollow noopener">go</a>`},
+		{`<a href="vbscript:x">x</a><img src="mailto:a@b.co">`, "<a>x</a>"},
+		{`<code class="language-go x">a</code><ol start="1; x">`, "<code>a</code><ol>"},
+		{`<p>"кавычки" &amp; <b>жирный</b></p>`, `<p>"кавычки" &amp; жирный</p>`},
+	}
+	for _, tt := range tests {
+		if got := Sanitize(tt.in); got != tt.want {
+			t.Errorf("Sanitize(%q): expected %q, got %q", tt.in, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package markdown
+
+import (
+	"html"
+	"io"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// allowedTags разрешённые теги и их атрибуты; всё остальное вырезается, текст внутри сохраняется
+var allowedTags = map[string][]string{
+	"p": nil, "br": nil, "hr": nil, "blockquote": nil, "pre": nil,
+	"h1": nil, "h2": nil, "h3": nil, "h4": nil, "h5": nil, "h6": nil,
+	"strong": nil, "em": nil, "code": {"class"},
+	"ul": nil, "ol": {"start"}, "li": nil,
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+}
+
+// droppedTags теги, которые вырезаются вместе с содержимым
+var droppedTags = map[string]bool{"script": true, "style": true, "iframe": true, "object": true, "textarea": true, "title": true}
+
+var voidTags = map[string]bool{"br": true, "hr": true, "img": true}
+
+var (
+	codeClassPattern = regexp.MustCompile(`^language-[A-Za-z0-9_+#.-]{1,32}$`)
+	numberPattern    = regexp.MustCompile(`^[0-9]{1,9}$`)
+)
+
+// allowedSchemes схемы ссылок и картинок; ссылки без схемы (относительные) тоже разрешены
+var allowedSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// textEscaper экранирует текст между тегами; кавычки в тексте безопасны и остаются как есть
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// Sanitize оставляет в HTML только теги и атрибуты из белого списка. Ссылки допускаются только http, https,
+// mailto и относительные, картинки — только http и https; ссылки получают rel="nofollow noopener"
+func Sanitize(s string) string {
+	var b strings.Builder
+	tokenizer := xhtml.NewTokenizer(strings.NewReader(s))
+	dropDepth := 0
+	for {
+		tt := tokenizer.Next()
+		if tt == xhtml.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				return ""
+			}
+			return b.String()
+		}
+		token := tokenizer.Token()
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken:
+			if droppedTags[token.Data] {
+				if tt == xhtml.StartTagToken {
+					dropDepth++
+				}
+				continue
+			}
+			if dropDepth > 0 {
+				continue
+			}
+			writeStartTag(&b, token)
+		case xhtml.EndTagToken:
+			if droppedTags[token.Data] {
+				if dropDepth > 0 {
+					dropDepth--
+				}
+				continue
+			}
+			if _, ok := allowedTags[token.Data]; ok && dropDepth == 0 && !voidTags[token.Data] {
+				b.WriteString("</" + token.Data + ">")
+			}
+		case xhtml.TextToken:
+			if dropDepth == 0 {
+				b.WriteString(textEscaper.Replace(token.Data))
+			}
+		}
+	}
+}
+
+func writeStartTag(b *strings.Builder, token xhtml.Token) {
+	allowed, ok := allowedTags[token.Data]
+	if !ok {
+		return
+	}
+	var attrs []xhtml.Attribute
+	for _, attr := range token.Attr {
+		if attr.Namespace == "" && slices.Contains(allowed, attr.Key) && allowedAttr(token.Data, attr.Key, attr.Val) {
+			attrs = append(attrs, attr)
+		}
+	}
+	// картинка без адреса бессмысленна
+	if token.Data == "img" && !hasAttr(attrs, "src") {
+		return
+	}
+
+	b.WriteString("<" + token.Data)
+	for _, attr := range attrs {
+		b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+	}
+	if token.Data == "a" && hasAttr(attrs, "href") {
+		b.WriteString(` rel="nofollow noopener"`)
+	}
+	if voidTags[token.Data] {
+		b.WriteString(" />")
+		return
+	}
+	b.WriteString(">")
+}
+
+func allowedAttr(tag, key, value string) bool {
+	switch key {
+	case "href":
+		return safeURL(value, true)
+	case "src":
+		return safeURL(value, false)
+	case "class":
+		return tag == "code" && codeClassPattern.MatchString(value)
+	case "start":
+		return numberPattern.MatchString(value)
+	}
+	return true
+}
+
+// safeURL relative разрешает ссылки без схемы; javascript:, data: и прочие схемы отклоняются
+func safeURL(value string, relative bool) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	if u.Scheme == "" {
+		return relative
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !relative && scheme == "mailto" {
+		return false
+	}
+	return allowedSchemes[scheme]
+}
+
+func hasAttr(attrs []xhtml.Attribute, key string) bool {
+	for _, attr := range attrs {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
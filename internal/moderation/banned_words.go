@@ -0,0 +1,95 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// minStemLength основа короче не отделяется от окончания, чтобы короткие слова не совпадали со всем подряд
+const minStemLength = 3
+
+// wordEndings окончания русских существительных, прилагательных и глаголов (с ё, заменённой на е)
+// и простые английские суффиксы. Слова совпадают, если после отбрасывания окончаний их основы равны
+var wordEndings = map[string]bool{
+	"а": true, "я": true, "о": true, "е": true, "ь": true, "ы": true, "и": true, "у": true, "ю": true,
+	"ой": true, "ей": true, "ою": true, "ею": true, "ом": true, "ем": true, "ам": true, "ям": true,
+	"ами": true, "ями": true, "ах": true, "ях": true, "ов": true, "ев": true, "ью": true,
+	"ия": true, "ие": true, "ий": true, "ии": true, "ию": true, "ием": true, "иям": true, "иях": true,
+	"ый": true, "ая": true, "яя": true, "ое": true, "ее": true, "ые": true,
+	"ого": true, "его": true, "ому": true, "ему": true, "ую": true, "юю": true,
+	"ым": true, "им": true, "ых": true, "их": true, "ыми": true, "ими": true,
+	"ть": true, "ти": true, "ешь": true, "ет": true, "ете": true, "ут": true, "ют": true,
+	"ишь": true, "ит": true, "ите": true, "ат": true, "ят": true, "л": true, "ла": true, "ло": true, "ли": true,
+	"ся": true, "сь": true, "ться": true, "тся": true, "ется": true, "ются": true, "ится": true, "ятся": true,
+	"лся": true, "лась": true, "лось": true, "лись": true,
+	"s": true, "es": true, "ed": true, "ing": true, "er": true, "ers": true,
+}
+
+// maxEndingLength самое длинное окончание в символах
+const maxEndingLength = 4
+
+// BannedWords отклоняет текст с запрещёнными словами в любой форме: "дурак" находит "дурака",
+// "дураки" и "дураками". Регистр и ё не учитываются; пустой список выключает проверку
+type BannedWords struct {
+	stems map[string]bool
+}
+
+func NewBannedWords(words []string) *BannedWords {
+	stems := make(map[string]bool, len(words))
+	for _, word := range words {
+		word = normalizeWord(strings.TrimSpace(word))
+		if word == "" {
+			continue
+		}
+		stems[word] = true
+		stems[stem(word)] = true
+	}
+	return &BannedWords{stems: stems}
+}
+
+func (*BannedWords) Name() string { return "banned_words" }
+
+func (c *BannedWords) Check(ctx context.Context, content Content) Decision {
+	if len(c.stems) == 0 {
+		return Decision{Action: Allow}
+	}
+	words := strings.FieldsFunc(content.Text, func(r rune) bool { return !unicode.IsLetter(r) })
+	for _, word := range words {
+		if c.matches(normalizeWord(word)) {
+			return Decision{Action: Reject, Err: entity.ErrBannedWords}
+		}
+	}
+	return Decision{Action: Allow}
+}
+
+// matches проверяет слово целиком и все его основы, которые остаются после отбрасывания окончания
+func (c *BannedWords) matches(word string) bool {
+	if c.stems[word] {
+		return true
+	}
+	runes := []rune(word)
+	for n := 1; n <= maxEndingLength && len(runes)-n >= minStemLength; n++ {
+		if wordEndings[string(runes[len(runes)-n:])] && c.stems[string(runes[:len(runes)-n])] {
+			return true
+		}
+	}
+	return false
+}
+
+// stem отбрасывает самое длинное окончание, оставляя основу не короче minStemLength
+func stem(word string) string {
+	runes := []rune(word)
+	for n := maxEndingLength; n >= 1; n-- {
+		if len(runes)-n >= minStemLength && wordEndings[string(runes[len(runes)-n:])] {
+			return string(runes[:len(runes)-n])
+		}
+	}
+	return word
+}
+
+func normalizeWord(word string) string {
+	return strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+}
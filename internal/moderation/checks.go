@@ -0,0 +1,194 @@
+package moderation
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+const (
+	// MaxTextLength размер колонок text VARCHAR(10000) в questions и answers; проверяется исходный
+	// текст в Markdown, а не HTML после рендеринга
+	MaxTextLength = 10000
+	// MaxUserIDLength размер колонки answers.user_id VARCHAR(255)
+	MaxUserIDLength = 255
+)
+
+// Length отклоняет текст и ID пользователя длиннее колонок БД; длина считается в символах, как в VARCHAR
+type Length struct {
+	MaxText   int
+	MaxUserID int
+}
+
+func (Length) Name() string { return "length" }
+
+func (c Length) Check(ctx context.Context, content Content) Decision {
+	if utf8.RuneCountInString(content.Text) > c.MaxText {
+		return Decision{Action: Reject, Err: entity.ErrTextTooLong}
+	}
+	if utf8.RuneCountInString(content.UserID) > c.MaxUserID {
+		return Decision{Action: Reject, Err: entity.ErrUserIDTooLong}
+	}
+	return Decision{Action: Allow}
+}
+
+// Flood отклоняет текст, в котором один символ повторяется подряд больше MaxRun раз ("ааааааа", "!!!!!!!!").
+// Пробелы и код в Markdown ("--------", "0000" в hex-дампе) не считаются; MaxRun 0 выключает проверку
+type Flood struct {
+	MaxRun int
+}
+
+func (Flood) Name() string { return "flood" }
+
+func (c Flood) Check(ctx context.Context, content Content) Decision {
+	if c.MaxRun <= 0 {
+		return Decision{Action: Allow}
+	}
+	var prev rune
+	run := 0
+	for _, r := range withoutCode(content.Text) {
+		if unicode.IsSpace(r) {
+			prev, run = 0, 0
+			continue
+		}
+		if unicode.ToLower(r) == prev {
+			run++
+		} else {
+			prev, run = unicode.ToLower(r), 1
+		}
+		if run > c.MaxRun {
+			return Decision{Action: Reject, Err: entity.ErrCharacterFlood}
+		}
+	}
+	return Decision{Action: Allow}
+}
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+const (
+	// spamMinLetters короче этого текст не проверяется на капс
+	spamMinLetters = 20
+	// spamCapsShare доля заглавных букв, после которой текст считается криком
+	spamCapsShare = 0.7
+	// spamLinkShare доля текста, занятая ссылками, после которой текст считается рекламой
+	spamLinkShare = 0.5
+)
+
+// Spam откладывает на ручную проверку текст с большим числом ссылок, текст, который почти целиком
+// состоит из ссылок, и текст заглавными буквами вне кода. Эвристики ошибаются, поэтому текст не отклоняется сразу
+type Spam struct {
+	MaxLinks int
+}
+
+func (Spam) Name() string { return "spam" }
+
+func (c Spam) Check(ctx context.Context, content Content) Decision {
+	links := linkPattern.FindAllString(content.Text, -1)
+	if len(links) > c.MaxLinks {
+		return Decision{Action: Hold, Err: entity.ErrSuspectedSpam}
+	}
+	linkLength := 0
+	for _, link := range links {
+		linkLength += utf8.RuneCountInString(link)
+	}
+	if linkLength > 0 && float64(linkLength) > spamLinkShare*float64(utf8.RuneCountInString(content.Text)) {
+		return Decision{Action: Hold, Err: entity.ErrSuspectedSpam}
+	}
+
+	// константы и SQL в коде пишутся заглавными, это не крик
+	letters, upper := 0, 0
+	for _, r := range withoutCode(content.Text) {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	if letters >= spamMinLetters && float64(upper) > spamCapsShare*float64(letters) {
+		return Decision{Action: Hold, Err: entity.ErrSuspectedSpam}
+	}
+	return Decision{Action: Allow}
+}
+
+// withoutCode убирает из Markdown блоки кода в ``` или ~~~ и код в обратных кавычках. Незакрытый блок
+// продолжается до конца текста, незакрытая обратная кавычка остаётся текстом, как в CommonMark
+func withoutCode(text string) string {
+	var b strings.Builder
+	var fence string
+	for _, line := range strings.SplitAfter(text, "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if len(line)-len(trimmed) > 3 {
+			trimmed = ""
+		}
+		if fence != "" {
+			if strings.HasPrefix(trimmed, fence) && strings.TrimSpace(strings.TrimLeft(trimmed, fence[:1])) == "" {
+				fence = ""
+			}
+			b.WriteString("\n")
+			continue
+		}
+		if marker := fenceMarker(trimmed); marker != "" {
+			fence = marker
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString(withoutCodeSpans(line))
+	}
+	return b.String()
+}
+
+// fenceMarker открывающая граница блока кода в начале строки: три и больше ` или ~
+func fenceMarker(line string) string {
+	for _, c := range []string{"`", "~"} {
+		n := len(line) - len(strings.TrimLeft(line, c))
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+// withoutCodeSpans убирает код в обратных кавычках: span закрывается последовательностью той же длины
+func withoutCodeSpans(line string) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(line, '`')
+		if start < 0 {
+			b.WriteString(line)
+			return b.String()
+		}
+		n := len(line[start:]) - len(strings.TrimLeft(line[start:], "`"))
+		ticks := line[start : start+n]
+		end := closingTicks(line[start+n:], ticks)
+		if end < 0 {
+			b.WriteString(line[:start+n])
+			line = line[start+n:]
+			continue
+		}
+		b.WriteString(line[:start])
+		b.WriteByte(' ')
+		line = line[start+n+end+n:]
+	}
+}
+
+// closingTicks индекс последовательности обратных кавычек ровно такой же длины, как ticks; -1, если её нет
+func closingTicks(s, ticks string) int {
+	offset := 0
+	for {
+		i := strings.Index(s[offset:], ticks)
+		if i < 0 {
+			return -1
+		}
+		i += offset
+		n := len(s[i:]) - len(strings.TrimLeft(s[i:], "`"))
+		if n == len(ticks) {
+			return i
+		}
+		offset = i + n
+	}
+}
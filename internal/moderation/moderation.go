@@ -0,0 +1,106 @@
+package moderation
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+const (
+	KindQuestion = "question"
+	KindAnswer   = "answer"
+	KindComment  = "comment"
+)
+
+// Action решение проверки; большее значение строже
+type Action int
+
+const (
+	Allow Action = iota
+	Hold
+	Reject
+)
+
+func (a Action) String() string {
+	switch a {
+	case Allow:
+		return "allow"
+	case Hold:
+		return "hold"
+	case Reject:
+		return "reject"
+	}
+	return "unknown"
+}
+
+// Content проверяемый текст; UserID пустой у вопросов
+type Content struct {
+	Kind   string
+	UserID string
+	Text   string
+}
+
+// Decision итог проверки. Err — ошибка для автора при Reject и причина при Hold,
+// Check — имя проверки, которая приняла решение
+type Decision struct {
+	Action Action
+	Err    entity.CustomError
+	Check  string
+}
+
+// Check одна проверка цепочки; должна быть безопасна для параллельного вызова
+type Check interface {
+	Name() string
+	Check(ctx context.Context, content Content) Decision
+}
+
+// Moderator проверяет текст перед сохранением; сервисы зависят от него, а не от Chain
+type Moderator interface {
+	Moderate(ctx context.Context, content Content) Decision
+}
+
+// Chain выполняет проверки по порядку. Первый Reject сразу отклоняет текст; Hold запоминается,
+// но следующие проверки всё равно выполняются, чтобы текст, который надо отклонить, не ушёл на проверку
+type Chain struct {
+	checks []Check
+}
+
+func NewChain(checks ...Check) *Chain {
+	return &Chain{checks: checks}
+}
+
+func (c *Chain) Moderate(ctx context.Context, content Content) Decision {
+	result := Decision{Action: Allow}
+	for _, check := range c.checks {
+		decision := check.Check(ctx, content)
+		if decision.Action == Allow {
+			continue
+		}
+		decision.Check = check.Name()
+		if decision.Action == Reject {
+			return decision
+		}
+		if result.Action == Allow {
+			result = decision
+		}
+	}
+	return result
+}
+
+// Policy настройки стандартной цепочки: MaxLinks — сколько ссылок допускается без ручной проверки,
+// MaxRepeatedChars — самый длинный допустимый повтор одного символа
+type Policy struct {
+	BannedWords      []string
+	MaxLinks         int
+	MaxRepeatedChars int
+}
+
+// NewDefaultChain длина, запрещённые слова, повторы символов и спам
+func NewDefaultChain(policy Policy) *Chain {
+	return NewChain(
+		Length{MaxText: MaxTextLength, MaxUserID: MaxUserIDLength},
+		NewBannedWords(policy.BannedWords),
+		Flood{MaxRun: policy.MaxRepeatedChars},
+		Spam{MaxLinks: policy.MaxLinks},
+	)
+}
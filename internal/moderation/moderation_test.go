@@ -0,0 +1,84 @@
+package moderation
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+func TestDefaultChain(t *testing.T) {
+	chain := NewDefaultChain(Policy{
+		BannedWords:      []string{"дурак", "Сволочь", "scam"},
+		MaxLinks:         2,
+		MaxRepeatedChars: 5,
+	})
+
+	tests := []struct {
+		name   string
+		text   string
+		userID string
+		action Action
+		err    entity.CustomError
+	}{
+		{"plain text", "Как настроить индексы в PostgreSQL?", "alice", Allow, entity.CustomError{}},
+		{"too long", strings.Repeat("я", MaxTextLength+1), "alice", Reject, entity.ErrTextTooLong},
+		{"max length in runes", strings.Repeat("ябв ", MaxTextLength/4), "alice", Allow, entity.CustomError{}},
+		{"long user id", "Ответ", strings.Repeat("u", MaxUserIDLength+1), Reject, entity.ErrUserIDTooLong},
+		{"banned word", "Сам ты дурак", "alice", Reject, entity.ErrBannedWords},
+		{"banned word inflected", "Не слушайте этого ДУРАКА!", "alice", Reject, entity.ErrBannedWords},
+		{"banned word plural instrumental", "с дураками не спорят", "alice", Reject, entity.ErrBannedWords},
+		{"banned word soft sign stem", "какие сволочи", "alice", Reject, entity.ErrBannedWords},
+		{"banned word instrumental", "Сволочью назвали", "alice", Reject, entity.ErrBannedWords},
+		{"english suffix", "these are scams", "alice", Reject, entity.ErrBannedWords},
+		{"different word with same prefix", "дуракаваляние", "alice", Allow, entity.CustomError{}},
+		{"flood", "Помогите!!!!!!!", "alice", Reject, entity.ErrCharacterFlood},
+		{"flood ignores case", "ааААаа", "alice", Reject, entity.ErrCharacterFlood},
+		{"too many links", "см. https://a.example https://b.example www.c.example", "alice", Hold, entity.ErrSuspectedSpam},
+		{"mostly links", "тут https://shop.example/very/long/path/to/product?ref=spam", "alice", Hold, entity.ErrSuspectedSpam},
+		{"caps", "КУПИТЕ НАШИ КУРСЫ ПРЯМО СЕЙЧАС", "alice", Hold, entity.ErrSuspectedSpam},
+		{"flood in fenced code", "Вывод:\n```\n0x00000000 --------\n```\nчто это?", "alice", Allow, entity.CustomError{}},
+		{"flood in inline code", "Разделитель `==========` в заголовке", "alice", Allow, entity.CustomError{}},
+		{"flood after closed fence", "```\nok\n```\nПомогите!!!!!!!", "alice", Reject, entity.ErrCharacterFlood},
+		{"flood with unmatched backtick", "Кавычка ` и крик!!!!!!!", "alice", Reject, entity.ErrCharacterFlood},
+		{"caps in code", "Медленно:\n~~~sql\nSELECT ID, NAME FROM USERS WHERE STATUS = 'ACTIVE'\n~~~", "alice", Allow, entity.CustomError{}},
+		{"caps in inline code", "Флаг ``O_RDWR|O_CREATE|O_TRUNC|O_EXCL|O_APPEND|O_SYNC`` не работает", "alice", Allow, entity.CustomError{}},
+		{"reject wins over hold", "ДУРАК ДУРАК ДУРАК ДУРАК ДУРАК", "alice", Reject, entity.ErrBannedWords},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decision := chain.Moderate(context.Background(), Content{Kind: KindAnswer, UserID: tt.userID, Text: tt.text})
+			if decision.Action != tt.action {
+				t.Fatalf("expected %s, got %s (%s: %s)", tt.action, decision.Action, decision.Check, decision.Err.Message)
+			}
+			if decision.Err != tt.err {
+				t.Errorf("expected error %q, got %q", tt.err.Message, decision.Err.Message)
+			}
+			if tt.action != Allow && decision.Err.Field == "" {
+				t.Errorf("expected field-level error, got %+v", decision.Err)
+			}
+		})
+	}
+}
+
+type holdCheck struct{}
+
+func (holdCheck) Name() string { return "hold" }
+
+func (holdCheck) Check(ctx context.Context, content Content) Decision {
+	return Decision{Action: Hold, Err: entity.ErrSuspectedSpam}
+}
+
+func TestChain_KeepsFirstHold(t *testing.T) {
+	decision := NewChain(holdCheck{}, Flood{MaxRun: 3}).Moderate(context.Background(), Content{Text: "ok"})
+	if decision.Action != Hold || decision.Check != "hold" {
+		t.Errorf("expected hold by the first check, got %+v", decision)
+	}
+
+	decision = NewChain(holdCheck{}, Flood{MaxRun: 3}).Moderate(context.Background(), Content{Text: "oooo"})
+	if decision.Action != Reject || decision.Check != "flood" {
+		t.Errorf("expected later reject to win over hold, got %+v", decision)
+	}
+}
@@ -0,0 +1,83 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens   float64
+	updated  time.Time
+	fullAt   time.Time
+	capacity float64
+}
+
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := limit.capacity()
+	rate := limit.ratePerSecond()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now, capacity: capacity}
+		s.buckets[key] = b
+	}
+
+	elapsed := now.Sub(b.updated).Seconds()
+	b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+	b.updated = now
+
+	result := Result{Limit: int(capacity)}
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - b.tokens) / rate)
+	}
+
+	result.Remaining = int(math.Floor(b.tokens))
+	result.Reset = secondsToDuration((capacity - b.tokens) / rate)
+	b.fullAt = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep удаляет ведра, которые уже успели наполниться: их состояние совпадает с новым ведром
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.fullAt) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
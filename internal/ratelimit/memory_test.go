@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStore_TakeExhaustsAndRefills(t *testing.T) {
+	now := time.Date(2025, 12, 5, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	limit := Limit{Requests: 2, Period: time.Second, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(context.Background(), "ip:1.2.3.4", limit)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !result.Allowed {
+			t.Errorf("expected request %d to be allowed", i+1)
+		}
+	}
+
+	result, _ := store.Take(context.Background(), "ip:1.2.3.4", limit)
+	if result.Allowed {
+		t.Errorf("expected request to be rejected after burst is exhausted")
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 500*time.Millisecond {
+		t.Errorf("expected retry after in (0, 500ms], got %v", result.RetryAfter)
+	}
+
+	other, _ := store.Take(context.Background(), "ip:5.6.7.8", limit)
+	if !other.Allowed {
+		t.Errorf("expected other key to have its own bucket")
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	result, _ = store.Take(context.Background(), "ip:1.2.3.4", limit)
+	if !result.Allowed {
+		t.Errorf("expected request to be allowed after refill")
+	}
+}
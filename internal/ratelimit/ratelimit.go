@@ -0,0 +1,39 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// Limit описывает token bucket: Requests токенов восстанавливаются за Period, ёмкость ведра — Burst
+type Limit struct {
+	Requests int
+	Period   time.Duration
+	Burst    int
+}
+
+func (l Limit) capacity() float64 {
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+	return float64(l.Requests)
+}
+
+// ratePerSecond скорость пополнения ведра
+func (l Limit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Store хранит состояние ведер. MemoryStore подходит для одного инстанса,
+// для нескольких реплик нужна реализация поверх общего хранилища
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
@@ -2,12 +2,17 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const pgForeignKeyViolation = "23503"
+
 type answerRepository struct {
 	db *gorm.DB
 }
@@ -17,7 +22,11 @@ func NewAnswerRepository(db *gorm.DB) AnswerRepository {
 }
 
 func (r *answerRepository) Create(ctx context.Context, answer *entity.Answer) (*entity.Answer, error) {
-	if err := r.db.WithContext(ctx).Create(answer).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).Create(answer).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			return nil, entity.ErrQuestionNotFound
+		}
 		return nil, err
 	}
 	return answer, nil
@@ -25,15 +34,26 @@ func (r *answerRepository) Create(ctx context.Context, answer *entity.Answer) (*
 
 func (r *answerRepository) GetByID(ctx context.Context, id int) (*entity.Answer, error) {
 	var answer entity.Answer
-	if err := r.db.WithContext(ctx).First(&answer, id).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).First(&answer, id).Error; err != nil {
 		return nil, err
 	}
 	return &answer, nil
 }
 
+func (r *answerRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Answer, error) {
+	if len(ids) == 0 {
+		return []entity.Answer{}, nil
+	}
+	var answers []entity.Answer
+	if err := dbFromContext(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
 func (r *answerRepository) GetByQuestionID(ctx context.Context, questionID int) ([]entity.Answer, error) {
 	var answers []entity.Answer
-	if err := r.db.WithContext(ctx).Where("question_id = ?", questionID).Order("created_at DESC").Find(&answers).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).Where("question_id = ?", questionID).Order("created_at DESC").Find(&answers).Error; err != nil {
 		return nil, err
 	}
 	if answers == nil {
@@ -42,8 +62,214 @@ func (r *answerRepository) GetByQuestionID(ctx context.Context, questionID int)
 	return answers, nil
 }
 
+func (r *answerRepository) GetByQuestionIDs(ctx context.Context, questionIDs []int) ([]entity.Answer, error) {
+	if len(questionIDs) == 0 {
+		return []entity.Answer{}, nil
+	}
+	var answers []entity.Answer
+	if err := dbFromContext(ctx, r.db).Where("question_id IN ?", questionIDs).Order("question_id, created_at, id").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *answerRepository) GetPublishedByQuestionID(ctx context.Context, questionID int, viewerID string) ([]entity.Answer, error) {
+	var answers []entity.Answer
+	err := dbFromContext(ctx, r.db).
+		Scopes(publishedOrOwn("user_id", viewerID)).
+		Where("question_id = ?", questionID).
+		Order("created_at DESC").
+		Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	if answers == nil {
+		return []entity.Answer{}, nil
+	}
+	return answers, nil
+}
+
+func (r *answerRepository) GetPublishedByQuestionIDs(ctx context.Context, questionIDs []int, viewerID string) ([]entity.Answer, error) {
+	if len(questionIDs) == 0 {
+		return []entity.Answer{}, nil
+	}
+	var answers []entity.Answer
+	err := dbFromContext(ctx, r.db).
+		Scopes(publishedOrOwn("user_id", viewerID)).
+		Where("question_id IN ?", questionIDs).
+		Order("question_id, created_at, id").
+		Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *answerRepository) GetByUserID(ctx context.Context, userID string) ([]entity.Answer, error) {
+	var answers []entity.Answer
+	if err := dbFromContext(ctx, r.db).Where("user_id = ?", userID).Order("id").Find(&answers).Error; err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *answerRepository) GetPageByUserID(ctx context.Context, userID string, afterID, limit int) ([]entity.Answer, error) {
+	var answers []entity.Answer
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+// ReassignUser увеличивает версию изменённых ответов, чтобы их ETag перестали совпадать
+func (r *answerRepository) ReassignUser(ctx context.Context, userID, newUserID string) ([]entity.Answer, error) {
+	var reassigned []entity.Answer
+	err := dbFromContext(ctx, r.db).Model(&reassigned).
+		Clauses(clause.Returning{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"user_id": newUserID,
+			"version": gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return reassigned, nil
+}
+
+func (r *answerRepository) CountByQuestionIDs(ctx context.Context, questionIDs []int) (map[int]int, error) {
+	counts := make(map[int]int, len(questionIDs))
+	if len(questionIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		QuestionID int
+		Count      int
+	}
+	err := dbFromContext(ctx, r.db).Model(&entity.Answer{}).
+		Select("question_id, COUNT(*) AS count").
+		Where("question_id IN ?", questionIDs).
+		Group("question_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.QuestionID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *answerRepository) GetStatsByQuestionIDs(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error) {
+	stats := make(map[int]entity.AnswerStats, len(questionIDs))
+	if len(questionIDs) == 0 {
+		return stats, nil
+	}
+	var rows []struct {
+		entity.Answer
+		AnswerCount int
+	}
+	// самый новый ответ выбирается в той же группировке, что и счётчик, как в GetByQuestionID: по created_at
+	err := dbFromContext(ctx, r.db).Raw(`
+		SELECT a.*, g.answer_count
+		FROM answers a
+		JOIN (
+			SELECT COUNT(*) AS answer_count, (ARRAY_AGG(id ORDER BY created_at DESC, id DESC))[1] AS latest_id
+			FROM answers
+			WHERE question_id IN ? AND moderation_status = ?
+			GROUP BY question_id
+		) g ON a.id = g.latest_id`, questionIDs, entity.ModerationApproved).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		latest := row.Answer
+		stats[row.QuestionID] = entity.AnswerStats{Count: row.AnswerCount, Latest: &latest}
+	}
+	return stats, nil
+}
+
+func (r *answerRepository) GetPageByModerationStatus(ctx context.Context, status string, afterID, limit int) ([]entity.Answer, error) {
+	var answers []entity.Answer
+	err := dbFromContext(ctx, r.db).
+		Where("moderation_status = ? AND id > ?", status, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&answers).Error
+	if err != nil {
+		return nil, err
+	}
+	return answers, nil
+}
+
+func (r *answerRepository) SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Answer{}).
+		Where("id = ? AND moderation_status = ?", id, from).
+		Updates(map[string]any{
+			"moderation_status": to,
+			"moderation_reason": reason,
+			"version":           gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *answerRepository) CountByFilter(ctx context.Context, filter entity.AnswerFilter) (int64, error) {
+	var count int64
+	if err := applyAnswerFilter(dbFromContext(ctx, r.db).Model(&entity.Answer{}), filter).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *answerRepository) DeleteByFilter(ctx context.Context, filter entity.AnswerFilter, limit int) ([]entity.Answer, error) {
+	db := dbFromContext(ctx, r.db)
+	ids := applyAnswerFilter(db.Model(&entity.Answer{}).Select("id"), filter).Order("id")
+	if limit > 0 {
+		ids = ids.Limit(limit)
+	}
+
+	var deleted []entity.Answer
+	err := db.Clauses(clause.Returning{}).
+		Where("id IN (?)", ids).
+		Delete(&deleted).Error
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
+
+func applyAnswerFilter(query *gorm.DB, filter entity.AnswerFilter) *gorm.DB {
+	if len(filter.IDs) > 0 {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.UserID != "" {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+	if filter.QuestionID != 0 {
+		query = query.Where("question_id = ?", filter.QuestionID)
+	}
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at < ?", *filter.CreatedTo)
+	}
+	return query
+}
+
+func (r *answerRepository) DeleteVersion(ctx context.Context, id, version int) (bool, error) {
+	result := dbFromContext(ctx, r.db).Where("id = ? AND version = ?", id, version).Delete(&entity.Answer{})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *answerRepository) Delete(ctx context.Context, id int) error {
-	result := r.db.WithContext(ctx).Delete(&entity.Answer{}, id)
+	result := dbFromContext(ctx, r.db).Delete(&entity.Answer{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
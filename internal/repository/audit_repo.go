@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type auditRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditRepository(db *gorm.DB) AuditRepository {
+	return &auditRepository{db: db}
+}
+
+func (r *auditRepository) Add(ctx context.Context, event *entity.AuditEvent) error {
+	return dbFromContext(ctx, r.db).Create(event).Error
+}
+
+func (r *auditRepository) List(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error) {
+	query := dbFromContext(ctx, r.db)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.EntityType != "" {
+		query = query.Where("entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != 0 {
+		query = query.Where("entity_id = ?", filter.EntityID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+
+	events := []entity.AuditEvent{}
+	if err := query.Order("id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *auditRepository) EraseUser(ctx context.Context, userID string, dropText bool) error {
+	db := dbFromContext(ctx, r.db)
+	// триггер append-only пропускает изменения только с этим флагом, и только до конца транзакции
+	if err := db.Exec("SELECT set_config('app.audit_erasure', 'on', true)").Error; err != nil {
+		return err
+	}
+	err := db.Model(&entity.AuditEvent{}).
+		Where("actor = ?", userID).
+		Updates(map[string]any{"actor": entity.ErasedUserID, "ip": ""}).Error
+	if err != nil {
+		return err
+	}
+	for _, column := range []string{"before", "after"} {
+		if _, err := scrubUserJSON(ctx, r.db, "audit_events", column, "", userID, dropText); err != nil {
+			return err
+		}
+	}
+	return db.Exec("SELECT set_config('app.audit_erasure', 'off', true)").Error
+}
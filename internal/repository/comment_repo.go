@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// commentColumns колонки comments, ссылающиеся на ответ и вопрос
+var commentColumns = map[string]string{
+	entity.ModerationTypeQuestion: "question_id",
+	entity.ModerationTypeAnswer:   "answer_id",
+}
+
+// commentParentConstraint внешний ключ comments.parent_id: комментарий, на который отвечают, удалён до вставки
+const commentParentConstraint = "comments_parent_id_fkey"
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+func (r *commentRepository) Create(ctx context.Context, comment *entity.Comment) error {
+	if err := dbFromContext(ctx, r.db).Create(comment).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+			if pgErr.ConstraintName == commentParentConstraint {
+				return entity.ErrCommentNotFound
+			}
+			if comment.QuestionID != nil {
+				return entity.ErrQuestionNotFound
+			}
+			return entity.ErrAnswerNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *commentRepository) GetByID(ctx context.Context, id int) (*entity.Comment, error) {
+	var comment entity.Comment
+	if err := dbFromContext(ctx, r.db).First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *commentRepository) GetPage(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error) {
+	column, ok := commentColumns[itemType]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип %q", itemType)
+	}
+	comments := []entity.Comment{}
+	err := dbFromContext(ctx, r.db).
+		Where(column+" = ? AND id > ?", itemID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+func (r *commentRepository) Update(ctx context.Context, id, version int, text string) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Comment{}).
+		Where("id = ? AND version = ?", id, version).
+		Updates(map[string]any{
+			"text":    text,
+			"version": gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *commentRepository) Delete(ctx context.Context, id int) error {
+	result := dbFromContext(ctx, r.db).Delete(&entity.Comment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrCommentNotFound
+	}
+	return nil
+}
+
+func (r *commentRepository) CountByAnswerIDs(ctx context.Context, answerIDs []int) (map[int]int, error) {
+	counts := make(map[int]int, len(answerIDs))
+	if len(answerIDs) == 0 {
+		return counts, nil
+	}
+	var rows []struct {
+		AnswerID int
+		Count    int
+	}
+	err := dbFromContext(ctx, r.db).Model(&entity.Comment{}).
+		Select("answer_id, COUNT(*) AS count").
+		Where("answer_id IN ?", answerIDs).
+		Group("answer_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		counts[row.AnswerID] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *commentRepository) GetPageByUser(ctx context.Context, userID string, afterID, limit int) ([]entity.Comment, error) {
+	var comments []entity.Comment
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&comments).Error
+	if err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// ReassignUser увеличивает версию изменённых комментариев, как ReassignUser у ответов
+func (r *commentRepository) ReassignUser(ctx context.Context, userID, newUserID string) (int64, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Comment{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"user_id": newUserID,
+			"version": gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *commentRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.Comment{})
+	return result.RowsAffected, result.Error
+}
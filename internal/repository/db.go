@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
 )
@@ -11,19 +12,295 @@ type QuestionRepository interface {
 
 	GetByID(ctx context.Context, id int) (*entity.Question, error)
 
-	GetAll(ctx context.Context) ([]entity.Question, error)
+	// GetByIDs возвращает найденные вопросы в порядке ID; отсутствующие ID пропускаются
+	GetByIDs(ctx context.Context, ids []int) ([]entity.Question, error)
+
+	// GetByIDForShare читает вопрос с блокировкой FOR SHARE до конца транзакции:
+	// удаление вопроса ждёт, пока транзакция не завершится
+	GetByIDForShare(ctx context.Context, id int) (*entity.Question, error)
+
+	// GetAll возвращает одобренные модератором вопросы и вопросы viewerID в любом статусе,
+	// новые первыми; пустой viewerID — только одобренные
+	GetAll(ctx context.Context, viewerID string) ([]entity.Question, error)
+
+	// GetPage возвращает до limit вопросов с ID больше afterID в порядке ID (keyset-пагинация)
+	// независимо от статуса модерации
+	GetPage(ctx context.Context, afterID, limit int) ([]entity.Question, error)
+
+	// GetPublishedPage как GetPage, но только одобренные модератором вопросы и вопросы viewerID
+	GetPublishedPage(ctx context.Context, afterID, limit int, viewerID string) ([]entity.Question, error)
+
+	// GetPageByModerationStatus возвращает до limit вопросов со статусом status и ID больше afterID
+	GetPageByModerationStatus(ctx context.Context, status string, afterID, limit int) ([]entity.Question, error)
+
+	// SetModerationStatus меняет статус с from на to и увеличивает версию; false, если вопроса нет
+	// или его статус уже не from (другой модератор успел раньше)
+	SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error)
 
 	GetByText(ctx context.Context, text string) (*entity.Question, error)
 
+	// GetPageByAuthor возвращает до limit вопросов автора с ID больше afterID в порядке ID
+	GetPageByAuthor(ctx context.Context, authorID string, afterID, limit int) ([]entity.Question, error)
+
+	// ClearAuthor убирает автора у всех его вопросов и возвращает изменённые вопросы
+	ClearAuthor(ctx context.Context, authorID string) ([]entity.Question, error)
+
 	Delete(ctx context.Context, id int) error
+
+	// DeleteVersion удаляет вопрос версии version; false, если вопроса нет или его версия уже другая
+	DeleteVersion(ctx context.Context, id, version int) (bool, error)
 }
 
 type AnswerRepository interface {
+	// Create возвращает entity.ErrQuestionNotFound, если вопрос удалён до вставки
 	Create(ctx context.Context, answer *entity.Answer) (*entity.Answer, error)
 
 	GetByID(ctx context.Context, id int) (*entity.Answer, error)
 
+	// GetByIDs возвращает найденные ответы в порядке ID; отсутствующие ID пропускаются
+	GetByIDs(ctx context.Context, ids []int) ([]entity.Answer, error)
+
 	GetByQuestionID(ctx context.Context, questionID int) ([]entity.Answer, error)
 
+	// GetByQuestionIDs возвращает ответы на несколько вопросов, упорядоченные по вопросу и времени создания
+	GetByQuestionIDs(ctx context.Context, questionIDs []int) ([]entity.Answer, error)
+
+	// GetPublishedByQuestionID как GetByQuestionID, но только одобренные модератором ответы и ответы viewerID
+	GetPublishedByQuestionID(ctx context.Context, questionID int, viewerID string) ([]entity.Answer, error)
+
+	// GetPublishedByQuestionIDs как GetByQuestionIDs, но только одобренные модератором ответы и ответы viewerID
+	GetPublishedByQuestionIDs(ctx context.Context, questionIDs []int, viewerID string) ([]entity.Answer, error)
+
+	// GetPageByModerationStatus возвращает до limit ответов со статусом status и ID больше afterID
+	GetPageByModerationStatus(ctx context.Context, status string, afterID, limit int) ([]entity.Answer, error)
+
+	// SetModerationStatus меняет статус с from на to и увеличивает версию; false, если ответа нет
+	// или его статус уже не from
+	SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error)
+
+	GetByUserID(ctx context.Context, userID string) ([]entity.Answer, error)
+
+	// GetPageByUserID возвращает до limit ответов пользователя с ID больше afterID в порядке ID
+	GetPageByUserID(ctx context.Context, userID string, afterID, limit int) ([]entity.Answer, error)
+
+	// ReassignUser заменяет автора всех ответов пользователя и возвращает изменённые ответы
+	ReassignUser(ctx context.Context, userID, newUserID string) ([]entity.Answer, error)
+
+	// CountByQuestionIDs возвращает число ответов на каждый вопрос; вопросов без ответов нет в map
+	CountByQuestionIDs(ctx context.Context, questionIDs []int) (map[int]int, error)
+
+	// GetStatsByQuestionIDs возвращает число одобренных ответов и самый новый из них для каждого вопроса
+	// одним сгруппированным запросом; вопросов без таких ответов нет в map
+	GetStatsByQuestionIDs(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error)
+
+	// CountByFilter возвращает число ответов, подходящих под фильтр
+	CountByFilter(ctx context.Context, filter entity.AnswerFilter) (int64, error)
+
+	// DeleteByFilter удаляет до limit подходящих ответов в порядке ID (0 — без ограничения)
+	// и возвращает удалённые целиком; ответы, удалённые параллельно, в результат не попадают
+	DeleteByFilter(ctx context.Context, filter entity.AnswerFilter, limit int) ([]entity.Answer, error)
+
 	Delete(ctx context.Context, id int) error
+
+	// DeleteVersion удаляет ответ версии version; false, если ответа нет или его версия уже другая
+	DeleteVersion(ctx context.Context, id, version int) (bool, error)
+}
+
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+
+	AppliedMigrationVersion(ctx context.Context) (int64, error)
+
+	LatestMigrationVersion() (int64, error)
+}
+
+// MigrationRepository применяет SQL миграции goose из каталога и ведёт таблицу goose_db_version,
+// совместимую с утилитой goose
+type MigrationRepository interface {
+	// Status возвращает миграции из каталога по возрастанию версии с отметкой о применении
+	Status(ctx context.Context) ([]entity.Migration, error)
+
+	// Up применяет Up-секцию миграции в транзакции; false, если миграция уже применена
+	// (например, другой репликой, запущенной одновременно)
+	Up(ctx context.Context, version int64) (bool, error)
+
+	// Down применяет Down-секцию миграции в транзакции; false, если миграция уже не применена
+	Down(ctx context.Context, version int64) (bool, error)
+}
+
+type StatsRepository interface {
+	Get(ctx context.Context) (*entity.Stats, error)
+}
+
+type IdempotencyRepository interface {
+	// Reserve атомарно создаёт запись; false, если ключ уже занят
+	Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error)
+
+	Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error)
+
+	// Complete сохраняет ответ и продлевает запись до expiresAt
+	Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte, expiresAt time.Time) error
+
+	Delete(ctx context.Context, scope, key string) error
+
+	// DeleteIfExpired удаляет запись, только если она истекла к now: параллельный запрос мог уже занять ключ заново
+	DeleteIfExpired(ctx context.Context, scope, key string, now time.Time) error
+
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *entity.Webhook) error
+
+	GetByID(ctx context.Context, id int) (*entity.Webhook, error)
+
+	GetByOwner(ctx context.Context, ownerID string) ([]entity.Webhook, error)
+
+	// DeleteByOwner удаляет все вебхуки владельца вместе с доставками и возвращает их число
+	DeleteByOwner(ctx context.Context, ownerID string) (int64, error)
+
+	// EraseUserFromDeliveries заменяет ID пользователя на entity.ErasedUserID в данных событий
+	// в доставках чужих вебхуков; с dropText убирает из них и текст
+	EraseUserFromDeliveries(ctx context.Context, userID string, dropText bool) error
+
+	// GetActiveByEventType возвращает активные вебхуки, подписанные на тип события
+	GetActiveByEventType(ctx context.Context, eventType string) ([]entity.Webhook, error)
+
+	Delete(ctx context.Context, id int) error
+
+	// CreateDeliveries ставит доставки в очередь; повтор того же события на вебхук игнорируется
+	CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error
+
+	// ClaimDueDeliveries забирает доставки, чьё время подошло, и откладывает их на lease,
+	// чтобы другие реплики не взяли их повторно. Если реплика упадёт, доставка вернётся в очередь
+	ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.WebhookDelivery, error)
+
+	GetDeliveryByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error)
+
+	// GetDeliveries возвращает последние доставки вебхука, status пустой — все статусы
+	GetDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]entity.WebhookDelivery, error)
+
+	UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error
+}
+
+// JobRepository хранит фоновые административные задачи
+type JobRepository interface {
+	Create(ctx context.Context, job *entity.Job) error
+
+	GetByID(ctx context.Context, id int64) (*entity.Job, error)
+
+	// ClaimNext забирает самую старую ожидающую задачу или задачу, чья аренда истекла
+	// (реплика упала или остановилась посреди работы), переводит её в running, продлевает аренду
+	// до now+lease и выдаёт новый LeaseToken. nil, если задач нет
+	ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (*entity.Job, error)
+
+	// Update сохраняет статус и прогресс задачи, только если её LeaseToken всё ещё job.LeaseToken;
+	// false, если задачу после истечения аренды забрала другая реплика
+	Update(ctx context.Context, job *entity.Job) (bool, error)
+}
+
+// AuditRepository журнал аудита; записи только добавляются и меняются только при удалении данных пользователя
+type AuditRepository interface {
+	// Add записывает событие; вызывается в транзакции вместе с изменением данных
+	Add(ctx context.Context, event *entity.AuditEvent) error
+
+	// List возвращает до limit событий с ID меньше beforeID (0 — с самого нового), новые первыми
+	List(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error)
+
+	// EraseUser заменяет пользователя на entity.ErasedUserID в авторе событий и в снимках до и после
+	// и стирает IP его событий; с dropText убирает из его снимков и текст. Вызывается в транзакции
+	EraseUser(ctx context.Context, userID string, dropText bool) error
+}
+
+type NotificationRepository interface {
+	// Add записывает уведомление; вызывается в транзакции вместе с изменением, о котором оно сообщает
+	Add(ctx context.Context, notification *entity.Notification) error
+
+	// ListByUser возвращает до limit уведомлений пользователя с ID меньше beforeID (0 — с самого нового),
+	// новые первыми
+	ListByUser(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.Notification, error)
+
+	// DeleteByUser удаляет все уведомления пользователя и возвращает их число
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// FlagRepository жалобы пользователей на вопросы и ответы
+type FlagRepository interface {
+	// Create возвращает entity.ErrFlagAlreadyExists, если у пользователя уже есть открытая жалоба на запись
+	Create(ctx context.Context, flag *entity.Flag) error
+
+	// CountOpen возвращает число открытых жалоб на вопрос или ответ
+	CountOpen(ctx context.Context, itemType string, itemID int) (int64, error)
+
+	// ListOpenItemIDs возвращает до limit ID существующих записей типа itemType с открытыми жалобами
+	// и ID больше afterID в порядке ID
+	ListOpenItemIDs(ctx context.Context, itemType string, afterID, limit int) ([]int, error)
+
+	// GetOpenByItems возвращает открытые жалобы на записи, упорядоченные по записи и времени жалобы
+	GetOpenByItems(ctx context.Context, itemType string, itemIDs []int) ([]entity.Flag, error)
+
+	// Resolve закрывает открытые жалобы на запись со статусом status и возвращает их число
+	Resolve(ctx context.Context, itemType string, itemID int, status, resolvedBy string, resolvedAt time.Time) (int64, error)
+
+	// GetPageByUser возвращает до limit жалоб пользователя с ID больше afterID в порядке ID
+	GetPageByUser(ctx context.Context, userID string, afterID int64, limit int) ([]entity.Flag, error)
+
+	// DeleteByUser удаляет все жалобы пользователя и возвращает их число
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+// CommentRepository комментарии к ответам и вопросам; itemType — entity.ModerationTypeAnswer
+// или entity.ModerationTypeQuestion. Комментарии удаляются вместе с ответом или вопросом (ON DELETE CASCADE)
+type CommentRepository interface {
+	// Create возвращает entity.ErrAnswerNotFound или entity.ErrQuestionNotFound, если запись удалена до вставки,
+	// и entity.ErrCommentNotFound, если удалён комментарий ParentID
+	Create(ctx context.Context, comment *entity.Comment) error
+
+	GetByID(ctx context.Context, id int) (*entity.Comment, error)
+
+	// GetPage возвращает до limit комментариев к записи с ID больше afterID в порядке ID
+	GetPage(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error)
+
+	// Update меняет текст и увеличивает версию; false, если комментария нет или его версия уже не version
+	Update(ctx context.Context, id, version int, text string) (bool, error)
+
+	Delete(ctx context.Context, id int) error
+
+	// CountByAnswerIDs возвращает число комментариев к каждому ответу; ответов без комментариев нет в map
+	CountByAnswerIDs(ctx context.Context, answerIDs []int) (map[int]int, error)
+
+	// GetPageByUser возвращает до limit комментариев пользователя с ID больше afterID в порядке ID
+	GetPageByUser(ctx context.Context, userID string, afterID, limit int) ([]entity.Comment, error)
+
+	// ReassignUser заменяет автора всех комментариев пользователя и возвращает их число
+	ReassignUser(ctx context.Context, userID, newUserID string) (int64, error)
+
+	// DeleteByUser удаляет все комментарии пользователя вместе с ответами на них и возвращает число комментариев пользователя
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
+}
+
+type OutboxRepository interface {
+	// Add записывает сообщение; вызывается в транзакции вместе с изменением данных
+	Add(ctx context.Context, message *entity.OutboxMessage) error
+
+	// TryLockRelay берёт блокировку relay до конца транзакции; false, если relay уже работает в другой реплике
+	TryLockRelay(ctx context.Context) (bool, error)
+
+	// GetByEventID возвращает сообщение по ID события
+	GetByEventID(ctx context.Context, eventID string) (*entity.OutboxMessage, error)
+
+	// FetchUnpublished возвращает неопубликованные и не отложенные сообщения в порядке записи
+	FetchUnpublished(ctx context.Context, limit int) ([]entity.OutboxMessage, error)
+
+	MarkPublished(ctx context.Context, ids []int64, publishedAt time.Time) error
+
+	// MarkFailed записывает неудачную попытку и sink, которые уже получили событие.
+	// Сообщение откладывается (parked_at), когда попыток становится maxAttempts; 0 — без предела
+	MarkFailed(ctx context.Context, id int64, deliveredSinks []string, lastError string, maxAttempts int) error
+
+	DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error)
+
+	// EraseUser заменяет ID пользователя на entity.ErasedUserID в данных событий;
+	// с dropText убирает из них и текст
+	EraseUser(ctx context.Context, userID string, dropText bool) error
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// erasureKeys ключи снимков и данных событий, в которых хранится ID пользователя: автор ответа,
+// комментария, жалобы и уведомления, автор вопроса и записи модерации, владелец вебхука
+var erasureKeys = []string{"user_id", "author_id", "owner_id"}
+
+// scrubUserJSON заменяет ID пользователя на entity.ErasedUserID в JSON-объекте column таблицы table,
+// а если field не пустой — в его вложенном объекте field. С dropText из объекта убирается и текст.
+// Возвращает число изменённых строк
+func scrubUserJSON(ctx context.Context, db *gorm.DB, table, column, field, userID string, dropText bool) (int64, error) {
+	object := column
+	if field != "" {
+		object = column + " -> '" + field + "'"
+	}
+	if dropText {
+		object = "(" + object + " - 'text')"
+	}
+
+	var total int64
+	for _, key := range erasureKeys {
+		scrubbed := "jsonb_set(" + object + ", '{" + key + "}', to_jsonb(?::text))"
+		if field != "" {
+			scrubbed = "jsonb_set(" + column + ", '{" + field + "}', " + scrubbed + ")"
+		}
+		where := column + " ->> '" + key + "' = ?"
+		if field != "" {
+			where = column + " -> '" + field + "' ->> '" + key + "' = ?"
+		}
+		result := dbFromContext(ctx, db).Exec("UPDATE "+table+" SET "+column+" = "+scrubbed+" WHERE "+where, entity.ErasedUserID, userID)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		total += result.RowsAffected
+	}
+	return total, nil
+}
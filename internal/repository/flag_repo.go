@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+const pgUniqueViolation = "23505"
+
+// flaggedTables таблицы, на записи которых можно пожаловаться
+var flaggedTables = map[string]string{
+	entity.ModerationTypeQuestion: "questions",
+	entity.ModerationTypeAnswer:   "answers",
+}
+
+type flagRepository struct {
+	db *gorm.DB
+}
+
+func NewFlagRepository(db *gorm.DB) FlagRepository {
+	return &flagRepository{db: db}
+}
+
+func (r *flagRepository) Create(ctx context.Context, flag *entity.Flag) error {
+	if err := dbFromContext(ctx, r.db).Create(flag).Error; err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+			return entity.ErrFlagAlreadyExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *flagRepository) CountOpen(ctx context.Context, itemType string, itemID int) (int64, error) {
+	var count int64
+	err := dbFromContext(ctx, r.db).Model(&entity.Flag{}).
+		Where("item_type = ? AND item_id = ? AND status = ?", itemType, itemID, entity.FlagOpen).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListOpenItemIDs пропускает жалобы на удалённые вопросы и ответы: у таблицы жалоб нет внешнего ключа,
+// потому что она ссылается на две таблицы
+func (r *flagRepository) ListOpenItemIDs(ctx context.Context, itemType string, afterID, limit int) ([]int, error) {
+	table, ok := flaggedTables[itemType]
+	if !ok {
+		return nil, fmt.Errorf("неизвестный тип %q", itemType)
+	}
+	ids := []int{}
+	err := dbFromContext(ctx, r.db).Table("flags f").
+		Joins("JOIN "+table+" i ON i.id = f.item_id").
+		Where("f.item_type = ? AND f.status = ? AND f.item_id > ?", itemType, entity.FlagOpen, afterID).
+		Group("f.item_id").
+		Order("f.item_id").
+		Limit(limit).
+		Pluck("f.item_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *flagRepository) GetOpenByItems(ctx context.Context, itemType string, itemIDs []int) ([]entity.Flag, error) {
+	if len(itemIDs) == 0 {
+		return []entity.Flag{}, nil
+	}
+	var flags []entity.Flag
+	err := dbFromContext(ctx, r.db).
+		Where("item_type = ? AND item_id IN ? AND status = ?", itemType, itemIDs, entity.FlagOpen).
+		Order("item_id, id").
+		Find(&flags).Error
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func (r *flagRepository) Resolve(ctx context.Context, itemType string, itemID int, status, resolvedBy string, resolvedAt time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Flag{}).
+		Where("item_type = ? AND item_id = ? AND status = ?", itemType, itemID, entity.FlagOpen).
+		Updates(map[string]any{
+			"status":      status,
+			"resolved_by": resolvedBy,
+			"resolved_at": resolvedAt,
+		})
+	return result.RowsAffected, result.Error
+}
+
+func (r *flagRepository) GetPageByUser(ctx context.Context, userID string, afterID int64, limit int) ([]entity.Flag, error) {
+	var flags []entity.Flag
+	err := dbFromContext(ctx, r.db).
+		Where("user_id = ? AND id > ?", userID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&flags).Error
+	if err != nil {
+		return nil, err
+	}
+	return flags, nil
+}
+
+func (r *flagRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.Flag{})
+	return result.RowsAffected, result.Error
+}
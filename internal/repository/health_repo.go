@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type healthRepository struct {
+	db            *gorm.DB
+	migrationsDir string
+}
+
+func NewHealthRepository(db *gorm.DB, migrationsDir string) HealthRepository {
+	return &healthRepository{db: db, migrationsDir: migrationsDir}
+}
+
+func (r *healthRepository) Ping(ctx context.Context) error {
+	sqlDB, err := r.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (r *healthRepository) AppliedMigrationVersion(ctx context.Context) (int64, error) {
+	var version int64
+	err := r.db.WithContext(ctx).
+		Raw("SELECT COALESCE(MAX(version_id), 0) FROM goose_db_version WHERE is_applied").
+		Scan(&version).Error
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// LatestMigrationVersion возвращает версию последней миграции из каталога goose (префикс имени файла)
+func (r *healthRepository) LatestMigrationVersion() (int64, error) {
+	entries, err := os.ReadDir(r.migrationsDir)
+	if err != nil {
+		return 0, err
+	}
+
+	var latest int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		prefix, _, found := strings.Cut(name, "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("некорректное имя миграции %s: %w", name, err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+func (r *idempotencyRepository) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	result := dbFromContext(ctx, r.db).Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *idempotencyRepository) Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error) {
+	var record entity.IdempotencyRecord
+	if err := dbFromContext(ctx, r.db).Where("scope = ? AND key = ?", scope, key).First(&record).Error; err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte, expiresAt time.Time) error {
+	encodedHeaders, err := json.Marshal(headers)
+	if err != nil {
+		return err
+	}
+	return dbFromContext(ctx, r.db).Model(&entity.IdempotencyRecord{}).
+		Where("scope = ? AND key = ?", scope, key).
+		Updates(map[string]interface{}{
+			"status":           entity.IdempotencyStatusCompleted,
+			"response_status":  status,
+			"response_headers": gorm.Expr("?::jsonb", string(encodedHeaders)),
+			"response_body":    body,
+			"expires_at":       expiresAt,
+		}).Error
+}
+
+func (r *idempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	return dbFromContext(ctx, r.db).Where("scope = ? AND key = ?", scope, key).Delete(&entity.IdempotencyRecord{}).Error
+}
+
+func (r *idempotencyRepository) DeleteIfExpired(ctx context.Context, scope, key string, now time.Time) error {
+	return dbFromContext(ctx, r.db).Where("scope = ? AND key = ? AND expires_at <= ?", scope, key, now).
+		Delete(&entity.IdempotencyRecord{}).Error
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("expires_at <= ?", now).Delete(&entity.IdempotencyRecord{})
+	return result.RowsAffected, result.Error
+}
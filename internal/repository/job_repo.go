@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type jobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Create(ctx context.Context, job *entity.Job) error {
+	return dbFromContext(ctx, r.db).Create(job).Error
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id int64) (*entity.Job, error) {
+	var job entity.Job
+	if err := dbFromContext(ctx, r.db).First(&job, id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (*entity.Job, error) {
+	var job entity.Job
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? OR (status = ? AND lease_until < ?)", entity.JobPending, entity.JobRunning, now).
+			Order("id").
+			First(&job).Error; err != nil {
+			return err
+		}
+
+		token := make([]byte, 16)
+		if _, err := rand.Read(token); err != nil {
+			return err
+		}
+		leaseUntil := now.Add(lease)
+		job.Status = entity.JobRunning
+		job.LeaseUntil = &leaseUntil
+		job.LeaseToken = hex.EncodeToString(token)
+		return tx.Save(&job).Error
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Update(ctx context.Context, job *entity.Job) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(job).
+		Where("lease_token = ?", job.LeaseToken).
+		Select("status", "processed", "error", "lease_until", "finished_at", "updated_at").
+		Updates(job)
+	return result.RowsAffected > 0, result.Error
+}
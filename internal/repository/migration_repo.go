@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// migrationLockKey ключ pg advisory lock: миграции из нескольких процессов выполняются по очереди
+const migrationLockKey = 7_305_094_112_359
+
+// migrationFile разобранный SQL файл goose
+type migrationFile struct {
+	version       int64
+	name          string
+	up            []string
+	down          []string
+	noTransaction bool
+}
+
+type migrationRepository struct {
+	db            *gorm.DB
+	migrationsDir string
+}
+
+func NewMigrationRepository(db *gorm.DB, migrationsDir string) MigrationRepository {
+	return &migrationRepository{db: db, migrationsDir: migrationsDir}
+}
+
+func (r *migrationRepository) Status(ctx context.Context) ([]entity.Migration, error) {
+	files, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.ensureVersionTable(ctx); err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		VersionID int64
+		Tstamp    time.Time
+	}
+	err = r.db.WithContext(ctx).
+		Raw("SELECT version_id, MAX(tstamp) AS tstamp FROM goose_db_version WHERE is_applied AND version_id > 0 GROUP BY version_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.VersionID] = row.Tstamp
+	}
+
+	migrations := make([]entity.Migration, 0, len(files))
+	for _, file := range files {
+		migration := entity.Migration{Version: file.version, Name: file.name}
+		if at, ok := applied[file.version]; ok {
+			migration.Applied = true
+			migration.AppliedAt = &at
+		}
+		migrations = append(migrations, migration)
+	}
+	return migrations, nil
+}
+
+func (r *migrationRepository) Up(ctx context.Context, version int64) (bool, error) {
+	file, err := r.find(version)
+	if err != nil {
+		return false, err
+	}
+	return r.run(ctx, file, file.up, false, func(tx *gorm.DB) error {
+		return tx.Exec("INSERT INTO goose_db_version (version_id, is_applied) VALUES (?, TRUE)", version).Error
+	})
+}
+
+func (r *migrationRepository) Down(ctx context.Context, version int64) (bool, error) {
+	file, err := r.find(version)
+	if err != nil {
+		return false, err
+	}
+	return r.run(ctx, file, file.down, true, func(tx *gorm.DB) error {
+		return tx.Exec("DELETE FROM goose_db_version WHERE version_id = ?", version).Error
+	})
+}
+
+// run выполняет statements и record в одной транзакции, для NO TRANSACTION — по очереди без неё.
+// Всё выполняется под pg_advisory_lock на одном соединении, и уже под ним заново проверяется, применена ли
+// миграция: статус мог измениться, пока другой процесс держал блокировку. applied — ожидаемое состояние
+// до запуска; если оно другое, миграция пропускается и возвращается false
+func (r *migrationRepository) run(ctx context.Context, file *migrationFile, statements []string, applied bool, record func(tx *gorm.DB) error) (bool, error) {
+	if err := r.ensureVersionTable(ctx); err != nil {
+		return false, err
+	}
+
+	ran := false
+	err := r.db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer func() {
+			// без отмены контекста: иначе блокировка осталась бы на соединении в пуле
+			if err := conn.WithContext(context.WithoutCancel(ctx)).Exec("SELECT pg_advisory_unlock(?)", migrationLockKey).Error; err != nil {
+				log.Printf("Ошибка снятия блокировки миграций: %v", err)
+			}
+		}()
+
+		apply := func(tx *gorm.DB) error {
+			var isApplied bool
+			err := tx.Raw("SELECT EXISTS (SELECT 1 FROM goose_db_version WHERE version_id = ? AND is_applied)", file.version).
+				Scan(&isApplied).Error
+			if err != nil {
+				return err
+			}
+			if isApplied != applied {
+				return nil
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("миграция %s: %w", file.name, err)
+				}
+			}
+			if err := record(tx); err != nil {
+				return err
+			}
+			ran = true
+			return nil
+		}
+		if file.noTransaction {
+			return apply(conn)
+		}
+		return conn.Transaction(apply)
+	})
+	if err != nil {
+		return false, err
+	}
+	return ran, nil
+}
+
+// ensureVersionTable создаёт таблицу версий так же, как goose, если миграции ещё не применялись.
+// Под той же блокировкой, что и миграции: одновременный CREATE TABLE IF NOT EXISTS падает в PostgreSQL
+func (r *migrationRepository) ensureVersionTable(ctx context.Context) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		err := tx.Exec(`CREATE TABLE IF NOT EXISTS goose_db_version (
+			id SERIAL PRIMARY KEY,
+			version_id BIGINT NOT NULL,
+			is_applied BOOLEAN NOT NULL,
+			tstamp TIMESTAMP DEFAULT NOW()
+		)`).Error
+		if err != nil {
+			return err
+		}
+		return tx.Exec(`INSERT INTO goose_db_version (version_id, is_applied)
+			SELECT 0, TRUE WHERE NOT EXISTS (SELECT 1 FROM goose_db_version)`).Error
+	})
+}
+
+func (r *migrationRepository) find(version int64) (*migrationFile, error) {
+	files, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		if files[i].version == version {
+			return &files[i], nil
+		}
+	}
+	return nil, fmt.Errorf("миграция %d не найдена в %s", version, r.migrationsDir)
+}
+
+func (r *migrationRepository) load() ([]migrationFile, error) {
+	entries, err := os.ReadDir(r.migrationsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []migrationFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		prefix, _, found := strings.Cut(name, "_")
+		if !found {
+			continue
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное имя миграции %s: %w", name, err)
+		}
+		file, err := parseMigration(filepath.Join(r.migrationsDir, name))
+		if err != nil {
+			return nil, err
+		}
+		file.version, file.name = version, name
+		files = append(files, *file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].version < files[j].version })
+	return files, nil
+}
+
+// parseMigration разбирает аннотации goose: секции Up/Down, StatementBegin/StatementEnd
+// для выражений с ";" внутри и NO TRANSACTION. Выражения вне блоков разделяются ";" в конце строки
+func parseMigration(path string) (*migrationFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	file := &migrationFile{}
+	var section *[]string
+	var statement strings.Builder
+	inBlock := false
+
+	flush := func() {
+		if text := strings.TrimSpace(statement.String()); text != "" && section != nil {
+			*section = append(*section, text)
+		}
+		statement.Reset()
+	}
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if annotation, ok := strings.CutPrefix(trimmed, "-- +goose "); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				flush()
+				section = &file.up
+			case "Down":
+				flush()
+				section = &file.down
+			case "StatementBegin":
+				flush()
+				inBlock = true
+			case "StatementEnd":
+				flush()
+				inBlock = false
+			case "NO TRANSACTION":
+				file.noTransaction = true
+			}
+			continue
+		}
+		if section == nil || (!inBlock && (trimmed == "" || strings.HasPrefix(trimmed, "--"))) {
+			continue
+		}
+
+		statement.WriteString(line)
+		statement.WriteByte('\n')
+		if !inBlock && strings.HasSuffix(trimmed, ";") {
+			flush()
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if inBlock {
+		return nil, fmt.Errorf("%s: нет -- +goose StatementEnd", path)
+	}
+	flush()
+	return file, nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) Add(ctx context.Context, notification *entity.Notification) error {
+	return dbFromContext(ctx, r.db).Create(notification).Error
+}
+
+func (r *notificationRepository) ListByUser(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.Notification, error) {
+	query := dbFromContext(ctx, r.db).Where("user_id = ?", userID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	notifications := []entity.Notification{}
+	if err := query.Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("user_id = ?", userID).Delete(&entity.Notification{})
+	return result.RowsAffected, result.Error
+}
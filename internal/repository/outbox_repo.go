@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// outboxRelayLockKey ключ pg advisory lock: relay работает в одной реплике, что сохраняет порядок событий
+const outboxRelayLockKey = 7_305_094_112_358
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Add(ctx context.Context, message *entity.OutboxMessage) error {
+	return dbFromContext(ctx, r.db).Create(message).Error
+}
+
+func (r *outboxRepository) TryLockRelay(ctx context.Context) (bool, error) {
+	var locked bool
+	if err := dbFromContext(ctx, r.db).Raw("SELECT pg_try_advisory_xact_lock(?)", outboxRelayLockKey).Scan(&locked).Error; err != nil {
+		return false, err
+	}
+	return locked, nil
+}
+
+func (r *outboxRepository) GetByEventID(ctx context.Context, eventID string) (*entity.OutboxMessage, error) {
+	var message entity.OutboxMessage
+	if err := dbFromContext(ctx, r.db).Where("event_id = ?", eventID).First(&message).Error; err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+func (r *outboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]entity.OutboxMessage, error) {
+	var messages []entity.OutboxMessage
+	err := dbFromContext(ctx, r.db).
+		Where("published_at IS NULL AND parked_at IS NULL").
+		Order("id").
+		Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, ids []int64, publishedAt time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).Model(&entity.OutboxMessage{}).Where("id IN ?", ids).Update("published_at", publishedAt).Error
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, deliveredSinks []string, lastError string, maxAttempts int) error {
+	if deliveredSinks == nil {
+		deliveredSinks = []string{}
+	}
+	delivered, err := json.Marshal(deliveredSinks)
+	if err != nil {
+		return err
+	}
+	updates := map[string]interface{}{
+		"attempts":        gorm.Expr("attempts + 1"),
+		"last_error":      lastError,
+		"delivered_sinks": gorm.Expr("?::jsonb", string(delivered)),
+	}
+	if maxAttempts > 0 {
+		// attempts в SET — значение до обновления
+		updates["parked_at"] = gorm.Expr("CASE WHEN attempts + 1 >= ? THEN NOW() END", maxAttempts)
+	}
+	return dbFromContext(ctx, r.db).Model(&entity.OutboxMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+func (r *outboxRepository) DeletePublishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("published_at IS NOT NULL AND published_at < ?", before).Delete(&entity.OutboxMessage{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *outboxRepository) EraseUser(ctx context.Context, userID string, dropText bool) error {
+	_, err := scrubUserJSON(ctx, r.db, "outbox", "payload", "data", userID, dropText)
+	return err
+}
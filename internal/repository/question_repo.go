@@ -6,8 +6,25 @@ import (
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// published оставляет в выборке только одобренные модератором вопросы и ответы
+func published(db *gorm.DB) *gorm.DB {
+	return db.Where("moderation_status = ?", entity.ModerationApproved)
+}
+
+// publishedOrOwn как published, но оставляет и записи viewerID в любом статусе, чтобы автор
+// видел свои записи на проверке. column — колонка с автором записи
+func publishedOrOwn(column, viewerID string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if viewerID == "" {
+			return published(db)
+		}
+		return db.Where("(moderation_status = ? OR "+column+" = ?)", entity.ModerationApproved, viewerID)
+	}
+}
+
 type questionRepository struct {
 	db *gorm.DB
 }
@@ -17,7 +34,7 @@ func NewQuestionRepository(db *gorm.DB) QuestionRepository {
 }
 
 func (r *questionRepository) Create(ctx context.Context, question *entity.Question) error {
-	if err := r.db.WithContext(ctx).Create(question).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).Create(question).Error; err != nil {
 		return err
 	}
 	return nil
@@ -25,30 +42,129 @@ func (r *questionRepository) Create(ctx context.Context, question *entity.Questi
 
 func (r *questionRepository) GetByID(ctx context.Context, id int) (*entity.Question, error) {
 	var question entity.Question
-	if err := r.db.WithContext(ctx).First(&question, id).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).First(&question, id).Error; err != nil {
 		return nil, err
 	}
 	return &question, nil
 }
 
-func (r *questionRepository) GetAll(ctx context.Context) ([]entity.Question, error) {
+func (r *questionRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Question, error) {
+	if len(ids) == 0 {
+		return []entity.Question{}, nil
+	}
+	var questions []entity.Question
+	if err := dbFromContext(ctx, r.db).Where("id IN ?", ids).Order("id").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *questionRepository) GetByIDForShare(ctx context.Context, id int) (*entity.Question, error) {
+	var question entity.Question
+	if err := dbFromContext(ctx, r.db).Clauses(clause.Locking{Strength: "SHARE"}).First(&question, id).Error; err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+func (r *questionRepository) GetAll(ctx context.Context, viewerID string) ([]entity.Question, error) {
+	var questions []entity.Question
+	if err := dbFromContext(ctx, r.db).Scopes(publishedOrOwn("author_id", viewerID)).Order("created_at DESC").Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *questionRepository) GetPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	var questions []entity.Question
+	if err := dbFromContext(ctx, r.db).Where("id > ?", afterID).Order("id").Limit(limit).Find(&questions).Error; err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *questionRepository) GetPublishedPage(ctx context.Context, afterID, limit int, viewerID string) ([]entity.Question, error) {
 	var questions []entity.Question
-	if err := r.db.WithContext(ctx).Order("created_at DESC").Find(&questions).Error; err != nil {
+	err := dbFromContext(ctx, r.db).
+		Scopes(publishedOrOwn("author_id", viewerID)).
+		Where("id > ?", afterID).
+		Order("id").
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
 		return nil, err
 	}
 	return questions, nil
 }
 
+func (r *questionRepository) GetPageByModerationStatus(ctx context.Context, status string, afterID, limit int) ([]entity.Question, error) {
+	var questions []entity.Question
+	err := dbFromContext(ctx, r.db).
+		Where("moderation_status = ? AND id > ?", status, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func (r *questionRepository) SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error) {
+	result := dbFromContext(ctx, r.db).Model(&entity.Question{}).
+		Where("id = ? AND moderation_status = ?", id, from).
+		Updates(map[string]any{
+			"moderation_status": to,
+			"moderation_reason": reason,
+			"version":           gorm.Expr("version + 1"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *questionRepository) GetByText(ctx context.Context, text string) (*entity.Question, error) {
 	var question entity.Question
-	if err := r.db.WithContext(ctx).Where("text = ?", text).First(&question).Error; err != nil {
+	if err := dbFromContext(ctx, r.db).Where("text = ?", text).First(&question).Error; err != nil {
 		return nil, err
 	}
 	return &question, nil
 }
 
+func (r *questionRepository) GetPageByAuthor(ctx context.Context, authorID string, afterID, limit int) ([]entity.Question, error) {
+	var questions []entity.Question
+	err := dbFromContext(ctx, r.db).
+		Where("author_id = ? AND id > ?", authorID, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&questions).Error
+	if err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+// ClearAuthor увеличивает версию изменённых вопросов, как ReassignUser у ответов
+func (r *questionRepository) ClearAuthor(ctx context.Context, authorID string) ([]entity.Question, error) {
+	var cleared []entity.Question
+	err := dbFromContext(ctx, r.db).Model(&cleared).
+		Clauses(clause.Returning{}).
+		Where("author_id = ?", authorID).
+		Updates(map[string]any{
+			"author_id": "",
+			"version":   gorm.Expr("version + 1"),
+		}).Error
+	if err != nil {
+		return nil, err
+	}
+	return cleared, nil
+}
+
+func (r *questionRepository) DeleteVersion(ctx context.Context, id, version int) (bool, error) {
+	result := dbFromContext(ctx, r.db).Where("id = ? AND version = ?", id, version).Delete(&entity.Question{})
+	return result.RowsAffected > 0, result.Error
+}
+
 func (r *questionRepository) Delete(ctx context.Context, id int) error {
-	result := r.db.WithContext(ctx).Delete(&entity.Question{}, id)
+	result := dbFromContext(ctx, r.db).Delete(&entity.Question{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+func (r *statsRepository) Get(ctx context.Context) (*entity.Stats, error) {
+	var stats entity.Stats
+	err := dbFromContext(ctx, r.db).Raw(`SELECT
+		(SELECT COUNT(*) FROM questions) AS questions,
+		(SELECT COUNT(*) FROM answers) AS answers,
+		(SELECT COUNT(DISTINCT user_id) FROM answers) AS users,
+		(SELECT COUNT(*) FROM outbox WHERE published_at IS NULL AND parked_at IS NULL) AS outbox_pending,
+		(SELECT COUNT(*) FROM outbox WHERE parked_at IS NOT NULL) AS outbox_parked,
+		(SELECT COUNT(*) FROM webhooks WHERE active) AS webhooks_active,
+		(SELECT COUNT(*) FROM webhook_deliveries WHERE status = ?) AS webhook_deliveries_pending,
+		(SELECT COUNT(*) FROM webhook_deliveries WHERE status = ?) AS webhook_deliveries_dead`,
+		entity.WebhookDeliveryPending, entity.WebhookDeliveryDead,
+	).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
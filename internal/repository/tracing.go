@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+const (
+	tracingSpanKey        = "otel:span"
+	tracingCallbackBefore = "otel:before"
+	tracingCallbackAfter  = "otel:after"
+)
+
+var tracer = otel.Tracer("github.com/andrey-samosuk/answer-questions/internal/repository")
+
+// RegisterTracing вешает на GORM колбэки, создающие DB-спан на каждый запрос.
+// Имя спана — краткая сводка запроса вида "SELECT questions".
+func RegisterTracing(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register(tracingCallbackBefore, startSpan("INSERT")),
+		cb.Create().After("gorm:create").Register(tracingCallbackAfter, endSpan),
+		cb.Query().Before("gorm:query").Register(tracingCallbackBefore, startSpan("SELECT")),
+		cb.Query().After("gorm:query").Register(tracingCallbackAfter, endSpan),
+		cb.Update().Before("gorm:update").Register(tracingCallbackBefore, startSpan("UPDATE")),
+		cb.Update().After("gorm:update").Register(tracingCallbackAfter, endSpan),
+		cb.Delete().Before("gorm:delete").Register(tracingCallbackBefore, startSpan("DELETE")),
+		cb.Delete().After("gorm:delete").Register(tracingCallbackAfter, endSpan),
+		cb.Row().Before("gorm:row").Register(tracingCallbackBefore, startSpan("ROW")),
+		cb.Row().After("gorm:row").Register(tracingCallbackAfter, endSpan),
+		cb.Raw().Before("gorm:raw").Register(tracingCallbackBefore, startSpan("RAW")),
+		cb.Raw().After("gorm:raw").Register(tracingCallbackAfter, endSpan),
+	)
+}
+
+func startSpan(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Statement == nil || db.Statement.Context == nil {
+			return
+		}
+		ctx, span := tracer.Start(db.Statement.Context, statementSummary(operation, db.Statement.Table),
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("db.system", "postgresql"),
+				attribute.String("db.operation", operation),
+				attribute.String("db.sql.table", db.Statement.Table),
+			),
+		)
+		db.Statement.Context = ctx
+		db.InstanceSet(tracingSpanKey, span)
+	}
+}
+
+func endSpan(db *gorm.DB) {
+	value, ok := db.InstanceGet(tracingSpanKey)
+	if !ok {
+		return
+	}
+	span, ok := value.(trace.Span)
+	if !ok {
+		return
+	}
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.statement", db.Statement.SQL.String()),
+		attribute.Int64("db.rows_affected", db.RowsAffected),
+	)
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		span.RecordError(db.Error)
+		span.SetStatus(codes.Error, db.Error.Error())
+	}
+}
+
+func statementSummary(operation, table string) string {
+	if table == "" {
+		return operation
+	}
+	return operation + " " + table
+}
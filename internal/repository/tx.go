@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type txContextKey struct{}
+
+// TxManager выполняет несколько операций репозиториев в одной транзакции (unit of work)
+type TxManager interface {
+	// WithinTx открывает транзакцию и передаёт её в fn через контекст. Репозитории,
+	// вызванные с этим контекстом, работают внутри транзакции. Ошибка fn откатывает транзакцию.
+	// Вложенный вызов работает через savepoint: его ошибка откатывает только вложенную часть
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+type txManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) TxManager {
+	return &txManager{db: db}
+}
+
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	db := m.db
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		db = tx
+	}
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txContextKey{}, tx))
+	})
+}
+
+// dbFromContext возвращает транзакцию из контекста или db, если транзакции нет
+func dbFromContext(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txContextKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+func (r *webhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	return dbFromContext(ctx, r.db).Create(webhook).Error
+}
+
+func (r *webhookRepository) GetByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+	if err := dbFromContext(ctx, r.db).First(&webhook, id).Error; err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *webhookRepository) GetByOwner(ctx context.Context, ownerID string) ([]entity.Webhook, error) {
+	var webhooks []entity.Webhook
+	if err := dbFromContext(ctx, r.db).Where("owner_id = ?", ownerID).Order("id").Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		return []entity.Webhook{}, nil
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) GetActiveByEventType(ctx context.Context, eventType string) ([]entity.Webhook, error) {
+	filter, err := json.Marshal([]string{eventType})
+	if err != nil {
+		return nil, err
+	}
+	var webhooks []entity.Webhook
+	if err := dbFromContext(ctx, r.db).Where("active AND event_types @> ?::jsonb", string(filter)).Find(&webhooks).Error; err != nil {
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+func (r *webhookRepository) DeleteByOwner(ctx context.Context, ownerID string) (int64, error) {
+	result := dbFromContext(ctx, r.db).Where("owner_id = ?", ownerID).Delete(&entity.Webhook{})
+	return result.RowsAffected, result.Error
+}
+
+func (r *webhookRepository) EraseUserFromDeliveries(ctx context.Context, userID string, dropText bool) error {
+	_, err := scrubUserJSON(ctx, r.db, "webhook_deliveries", "payload", "data", userID, dropText)
+	return err
+}
+
+func (r *webhookRepository) Delete(ctx context.Context, id int) error {
+	result := dbFromContext(ctx, r.db).Delete(&entity.Webhook{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return entity.ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return dbFromContext(ctx, r.db).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "webhook_id"}, {Name: "event_id"}}, DoNothing: true}).
+		Create(&deliveries).Error
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+	err := dbFromContext(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", entity.WebhookDeliveryPending, now).
+			Order("next_attempt_at").
+			Limit(limit).
+			Find(&deliveries).Error; err != nil {
+			return err
+		}
+		if len(deliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(deliveries))
+		for i := range deliveries {
+			ids[i] = deliveries[i].ID
+		}
+		return tx.Model(&entity.WebhookDelivery{}).Where("id IN ?", ids).Update("next_attempt_at", now.Add(lease)).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) GetDeliveryByID(ctx context.Context, id int64) (*entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	if err := dbFromContext(ctx, r.db).First(&delivery, id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (r *webhookRepository) GetDeliveries(ctx context.Context, webhookID int, status string, limit int) ([]entity.WebhookDelivery, error) {
+	query := dbFromContext(ctx, r.db).Where("webhook_id = ?", webhookID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []entity.WebhookDelivery
+	if err := query.Order("id DESC").Limit(limit).Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		return []entity.WebhookDelivery{}, nil
+	}
+	return deliveries, nil
+}
+
+func (r *webhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	return dbFromContext(ctx, r.db).Save(delivery).Error
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// AdminService операции для администрирования из командной строки.
+// Удаления пишут в outbox те же события, что и API
+type AdminService interface {
+	// ListQuestions возвращает до limit вопросов с ID больше afterID вместе с числом ответов
+	ListQuestions(ctx context.Context, afterID, limit int) ([]entity.QuestionSummary, error)
+
+	// DeleteQuestions удаляет вопросы с ответами в одной транзакции. Если какого-то вопроса нет,
+	// ничего не удаляется. dryRun только возвращает то, что будет удалено
+	DeleteQuestions(ctx context.Context, ids []int, dryRun bool) ([]entity.QuestionSummary, error)
+
+	// PurgeUserAnswers удаляет все ответы пользователя в одной транзакции
+	PurgeUserAnswers(ctx context.Context, userID string, dryRun bool) ([]entity.Answer, error)
+
+	Stats(ctx context.Context) (*entity.Stats, error)
+}
+
+type adminService struct {
+	questionRepo repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	statsRepo    repository.StatsRepository
+	txManager    repository.TxManager
+	outbox       repository.OutboxRepository
+	audit        repository.AuditRepository
+}
+
+func NewAdminService(
+	questionRepo repository.QuestionRepository,
+	answerRepo repository.AnswerRepository,
+	statsRepo repository.StatsRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+) AdminService {
+	return &adminService{
+		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		statsRepo:    statsRepo,
+		txManager:    txManager,
+		outbox:       outbox,
+		audit:        audit,
+	}
+}
+
+func (s *adminService) ListQuestions(ctx context.Context, afterID, limit int) ([]entity.QuestionSummary, error) {
+	ctx, span := startSpan(ctx, "AdminService.ListQuestions")
+	defer span.End()
+
+	questions, err := s.questionRepo.GetPage(ctx, afterID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	summaries, err := s.summarize(ctx, questions)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return summaries, nil
+}
+
+func (s *adminService) DeleteQuestions(ctx context.Context, ids []int, dryRun bool) ([]entity.QuestionSummary, error) {
+	ctx, span := startSpan(ctx, "AdminService.DeleteQuestions")
+	defer span.End()
+
+	questions := make([]entity.Question, 0, len(ids))
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		question, err := s.questionRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, spanError(span, fmt.Errorf("вопрос %d: %w", id, entity.ErrQuestionNotFound))
+			}
+			return nil, spanError(span, entity.ErrDatabaseQuery)
+		}
+		questions = append(questions, *question)
+	}
+
+	summaries, err := s.summarize(ctx, questions)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if dryRun {
+		return summaries, nil
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for _, question := range questions {
+			if err := s.questionRepo.Delete(ctx, question.ID); err != nil {
+				return err
+			}
+			if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityQuestion, question.ID, question, nil); err != nil {
+				return err
+			}
+			if err := recordEvent(ctx, s.outbox, events.TypeQuestionDeleted, question.ID, map[string]int{"id": question.ID}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		if err == entity.ErrQuestionNotFound {
+			return nil, spanError(span, entity.ErrQuestionNotFound)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return summaries, nil
+}
+
+func (s *adminService) PurgeUserAnswers(ctx context.Context, userID string, dryRun bool) ([]entity.Answer, error) {
+	ctx, span := startSpan(ctx, "AdminService.PurgeUserAnswers")
+	defer span.End()
+
+	if strings.TrimSpace(userID) == "" {
+		return nil, spanError(span, entity.ErrInvalidUserID)
+	}
+
+	answers, err := s.answerRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	if answers == nil {
+		answers = []entity.Answer{}
+	}
+	if dryRun || len(answers) == 0 {
+		return answers, nil
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for _, answer := range answers {
+			// ответ мог быть удалён вместе с вопросом после выборки, событие о вопросе уже записано
+			if err := s.answerRepo.Delete(ctx, answer.ID); err != nil {
+				if err == entity.ErrAnswerNotFound {
+					continue
+				}
+				return err
+			}
+			if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityAnswer, answer.ID, answer, nil); err != nil {
+				return err
+			}
+			err := recordEvent(ctx, s.outbox, events.TypeAnswerDeleted, answer.QuestionID, map[string]int{
+				"id":          answer.ID,
+				"question_id": answer.QuestionID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return answers, nil
+}
+
+func (s *adminService) Stats(ctx context.Context) (*entity.Stats, error) {
+	ctx, span := startSpan(ctx, "AdminService.Stats")
+	defer span.End()
+
+	stats, err := s.statsRepo.Get(ctx)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return stats, nil
+}
+
+func (s *adminService) summarize(ctx context.Context, questions []entity.Question) ([]entity.QuestionSummary, error) {
+	ids := make([]int, len(questions))
+	for i, question := range questions {
+		ids[i] = question.ID
+	}
+	counts, err := s.answerRepo.CountByQuestionIDs(ctx, ids)
+	if err != nil {
+		return nil, entity.ErrDatabaseQuery
+	}
+
+	summaries := make([]entity.QuestionSummary, len(questions))
+	for i, question := range questions {
+		summaries[i] = entity.QuestionSummary{Question: question, Answers: counts[question.ID]}
+	}
+	return summaries, nil
+}
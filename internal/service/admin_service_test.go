@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+)
+
+func (r *memoryTransferQuestionRepository) GetByID(ctx context.Context, id int) (*entity.Question, error) {
+	for _, q := range r.store.questions {
+		if q.ID == id {
+			return &q, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// Delete удаляет вопрос вместе с ответами, как ON DELETE CASCADE
+func (r *memoryTransferQuestionRepository) Delete(ctx context.Context, id int) error {
+	for i, q := range r.store.questions {
+		if q.ID == id {
+			r.store.questions = append(r.store.questions[:i:i], r.store.questions[i+1:]...)
+			answers := r.store.answers[:0:0]
+			for _, a := range r.store.answers {
+				if a.QuestionID != id {
+					answers = append(answers, a)
+				}
+			}
+			r.store.answers = answers
+			return nil
+		}
+	}
+	return entity.ErrQuestionNotFound
+}
+
+func (r *memoryTransferAnswerRepository) GetByUserID(ctx context.Context, userID string) ([]entity.Answer, error) {
+	var result []entity.Answer
+	for _, a := range r.store.answers {
+		if a.UserID == userID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryTransferAnswerRepository) CountByQuestionIDs(ctx context.Context, questionIDs []int) (map[int]int, error) {
+	counts := make(map[int]int)
+	answers, _ := r.GetByQuestionIDs(ctx, questionIDs)
+	for _, a := range answers {
+		counts[a.QuestionID]++
+	}
+	return counts, nil
+}
+
+func (r *memoryTransferAnswerRepository) Delete(ctx context.Context, id int) error {
+	for i, a := range r.store.answers {
+		if a.ID == id {
+			r.store.answers = append(r.store.answers[:i:i], r.store.answers[i+1:]...)
+			return nil
+		}
+	}
+	return entity.ErrAnswerNotFound
+}
+
+func newMemoryAdminService() (*memoryTransferStore, *memoryOutboxRepository, *memoryAuditRepository, AdminService) {
+	store := &memoryTransferStore{
+		questions: []entity.Question{{ID: 1, Text: "Первый"}, {ID: 2, Text: "Второй"}},
+		answers: []entity.Answer{
+			{ID: 1, QuestionID: 1, UserID: "alice", Text: "a"},
+			{ID: 2, QuestionID: 1, UserID: "bob", Text: "b"},
+			{ID: 3, QuestionID: 2, UserID: "alice", Text: "c"},
+		},
+	}
+	outbox := &memoryOutboxRepository{}
+	audit := &memoryAuditRepository{}
+	return store, outbox, audit, NewAdminService(
+		&memoryTransferQuestionRepository{store: store},
+		&memoryTransferAnswerRepository{store: store},
+		nil,
+		store,
+		outbox,
+		audit,
+	)
+}
+
+func TestAdminService_DeleteQuestions(t *testing.T) {
+	ctx := context.Background()
+	store, outbox, audit, svc := newMemoryAdminService()
+
+	planned, err := svc.DeleteQuestions(ctx, []int{1}, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(planned) != 1 || planned[0].Answers != 2 {
+		t.Errorf("expected question 1 with 2 answers, got %+v", planned)
+	}
+	if len(store.questions) != 2 || len(outbox.messages) != 0 {
+		t.Errorf("expected dry run to keep data and record no events")
+	}
+
+	// неизвестный ID отменяет удаление целиком
+	_, err = svc.DeleteQuestions(ctx, []int{1, 99}, false)
+	if !errors.Is(err, entity.ErrQuestionNotFound) {
+		t.Errorf("expected ErrQuestionNotFound, got %v", err)
+	}
+	if len(store.questions) != 2 {
+		t.Errorf("expected no questions deleted, got %d left", len(store.questions))
+	}
+
+	if _, err := svc.DeleteQuestions(ctx, []int{1, 1}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.questions) != 1 || len(store.answers) != 1 {
+		t.Errorf("expected question 1 deleted with its answers, got %d questions and %d answers", len(store.questions), len(store.answers))
+	}
+	if len(outbox.messages) != 1 || outbox.messages[0].EventType != events.TypeQuestionDeleted {
+		t.Errorf("expected one question.deleted event, got %d", len(outbox.messages))
+	}
+	if len(audit.events) != 1 || audit.events[0].EntityType != entity.AuditEntityQuestion || audit.events[0].Before == nil {
+		t.Errorf("expected one audit event with a snapshot of question 1, got %+v", audit.events)
+	}
+}
+
+func TestAdminService_PurgeUserAnswers(t *testing.T) {
+	ctx := context.Background()
+	store, outbox, _, svc := newMemoryAdminService()
+
+	if _, err := svc.PurgeUserAnswers(ctx, "  ", false); err != entity.ErrInvalidUserID {
+		t.Errorf("expected ErrInvalidUserID, got %v", err)
+	}
+
+	planned, err := svc.PurgeUserAnswers(ctx, "alice", true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(planned) != 2 || len(store.answers) != 3 {
+		t.Errorf("expected dry run to list 2 answers and keep all 3, got %d and %d", len(planned), len(store.answers))
+	}
+
+	purged, err := svc.PurgeUserAnswers(ctx, "alice", false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(purged) != 2 || len(store.answers) != 1 || store.answers[0].UserID != "bob" {
+		t.Errorf("expected only bob's answer to remain, got %+v", store.answers)
+	}
+	if len(outbox.messages) != 2 {
+		t.Errorf("expected 2 answer.deleted events, got %d", len(outbox.messages))
+	}
+}
+
+type fakeMigrationRepository struct {
+	migrations []entity.Migration
+	calls      []string
+}
+
+func (r *fakeMigrationRepository) Status(ctx context.Context) ([]entity.Migration, error) {
+	return append([]entity.Migration(nil), r.migrations...), nil
+}
+
+func (r *fakeMigrationRepository) Up(ctx context.Context, version int64) (bool, error) {
+	return r.set(version, true, "up")
+}
+
+func (r *fakeMigrationRepository) Down(ctx context.Context, version int64) (bool, error) {
+	return r.set(version, false, "down")
+}
+
+func (r *fakeMigrationRepository) set(version int64, applied bool, call string) (bool, error) {
+	for i := range r.migrations {
+		if r.migrations[i].Version == version {
+			if r.migrations[i].Applied == applied {
+				return false, nil
+			}
+			r.migrations[i].Applied = applied
+			r.calls = append(r.calls, call+" "+r.migrations[i].Name)
+			return true, nil
+		}
+	}
+	return false, errors.New("unknown migration")
+}
+
+// racingMigrationRepository применяет миграцию от имени другого процесса между Status и Up
+type racingMigrationRepository struct {
+	*fakeMigrationRepository
+	appliedByOther int64
+}
+
+func (r *racingMigrationRepository) Status(ctx context.Context) ([]entity.Migration, error) {
+	migrations, err := r.fakeMigrationRepository.Status(ctx)
+	for i := range r.migrations {
+		if r.migrations[i].Version == r.appliedByOther {
+			r.migrations[i].Applied = true
+		}
+	}
+	return migrations, err
+}
+
+func TestMigrationService_SkipsMigrationAppliedConcurrently(t *testing.T) {
+	repo := &racingMigrationRepository{fakeMigrationRepository: &fakeMigrationRepository{migrations: []entity.Migration{
+		{Version: 1, Name: "1_a.sql"},
+		{Version: 2, Name: "2_b.sql"},
+	}}, appliedByOther: 1}
+
+	applied, err := NewMigrationService(repo).Up(context.Background(), false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 1 || applied[0].Version != 2 || len(repo.calls) != 1 {
+		t.Errorf("expected only 2_b.sql to be applied, got %+v and calls %v", applied, repo.calls)
+	}
+}
+
+func TestMigrationService_UpAndDown(t *testing.T) {
+	ctx := context.Background()
+	repo := &fakeMigrationRepository{migrations: []entity.Migration{
+		{Version: 1, Name: "1_a.sql", Applied: true},
+		{Version: 2, Name: "2_b.sql"},
+		{Version: 3, Name: "3_c.sql"},
+	}}
+	svc := NewMigrationService(repo)
+
+	pending, err := svc.Up(ctx, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pending) != 2 || len(repo.calls) != 0 {
+		t.Errorf("expected 2 pending migrations and no changes, got %d and %v", len(pending), repo.calls)
+	}
+
+	if _, err := svc.Up(ctx, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := svc.Down(ctx, 2, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"up 2_b.sql", "up 3_c.sql", "down 3_c.sql", "down 2_b.sql"}
+	if len(repo.calls) != len(expected) {
+		t.Fatalf("expected calls %v, got %v", expected, repo.calls)
+	}
+	for i := range expected {
+		if repo.calls[i] != expected[i] {
+			t.Errorf("expected call %d to be %q, got %q", i, expected[i], repo.calls[i])
+		}
+	}
+}
@@ -2,83 +2,236 @@ package service
 
 import (
 	"context"
+	"errors"
+	"slices"
+
+	"gorm.io/gorm"
 
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
 	"github.com/andrey-samosuk/answer-questions/internal/repository"
 )
 
 type AnswerService interface {
+	// CreateAnswer создаёт ответ от авторизованного пользователя; userID из запроса должен с ним совпадать
 	CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error)
 
 	GetAnswer(ctx context.Context, id int) (*entity.Answer, error)
 
+	// GetAnswersByIDs возвращает ответы в порядке запрошенных ID одним запросом.
+	// Ненайденные ID возвращаются в missing, а не ошибкой; повторы ID игнорируются
+	GetAnswersByIDs(ctx context.Context, ids []int) (answers []entity.Answer, missing []int, err error)
+
+	// GetAnswersByQuestion возвращает одобренные модератором ответы и неодобренные ответы
+	// пользователя из контекста запроса, новые первыми
 	GetAnswersByQuestion(ctx context.Context, questionID int) ([]entity.Answer, error)
 
-	DeleteAnswer(ctx context.Context, id int) error
+	// GetAnswersByQuestionIDs загружает ответы на несколько вопросов одним запросом.
+	// Ответы каждого вопроса упорядочены как в GetAnswersByQuestion: новые первыми
+	GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error)
+
+	// GetAnswerStats возвращает число одобренных ответов и самый новый из них для каждого вопроса;
+	// вопросов без ответов нет в map
+	GetAnswerStats(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error)
+
+	// DeleteAnswer удаляет ответ; expectedVersion как в QuestionService.DeleteQuestion
+	DeleteAnswer(ctx context.Context, id, expectedVersion int) error
 }
 
 type answerService struct {
 	answerRepo   repository.AnswerRepository
 	questionRepo repository.QuestionRepository
+	txManager    repository.TxManager
+	outbox       repository.OutboxRepository
+	audit        repository.AuditRepository
+	moderator    moderation.Moderator
 }
 
-func NewAnswerService(answerRepo repository.AnswerRepository, questionRepo repository.QuestionRepository) AnswerService {
+func NewAnswerService(answerRepo repository.AnswerRepository, questionRepo repository.QuestionRepository, txManager repository.TxManager, outbox repository.OutboxRepository, audit repository.AuditRepository, moderator moderation.Moderator) AnswerService {
 	return &answerService{
 		answerRepo:   answerRepo,
 		questionRepo: questionRepo,
+		txManager:    txManager,
+		outbox:       outbox,
+		audit:        audit,
+		moderator:    moderator,
 	}
 }
 
-func (s *answerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
-	if userID == "" {
-		return nil, entity.ErrInvalidUserID
+// answerAuthor автор ответа — авторизованный пользователь, как у вопросов. user_id из запроса необязателен,
+// но если передан, должен с ним совпадать. Без авторизованного пользователя ответ не создаётся
+// ни в одном транспорте (REST, GraphQL, gRPC)
+func answerAuthor(ctx context.Context, userID string) (string, error) {
+	actor := AuditMetaFromContext(ctx).Actor
+	if actor == "" {
+		return "", entity.ErrUnauthorized
 	}
-
-	if text == "" {
-		return nil, entity.ErrInvalidAnswerText
+	if userID != "" && userID != actor {
+		return "", entity.ErrUserIDMismatch
 	}
+	return actor, nil
+}
 
-	_, err := s.questionRepo.GetByID(ctx, questionID)
+func (s *answerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+	ctx, span := startSpan(ctx, "AnswerService.CreateAnswer")
+	defer span.End()
+
+	userID, err := answerAuthor(ctx, userID)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := ValidateAnswer(userID, text); err != nil {
+		return nil, spanError(span, err)
+	}
+	status, reason, err := moderationStatus(ctx, s.moderator, moderation.Content{Kind: moderation.KindAnswer, UserID: userID, Text: text})
 	if err != nil {
-		return nil, entity.ErrQuestionNotFound
+		return nil, spanError(span, err)
 	}
 
 	answer := &entity.Answer{
-		QuestionID: questionID,
-		UserID:     userID,
-		Text:       text,
+		QuestionID:       questionID,
+		UserID:           userID,
+		Text:             text,
+		ModerationStatus: status,
+		ModerationReason: reason,
 	}
 
-	createdAnswer, err := s.answerRepo.Create(ctx, answer)
+	// блокировка вопроса не даёт удалить его между проверкой и вставкой ответа
+	var createdAnswer *entity.Answer
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		question, err := s.questionRepo.GetByIDForShare(ctx, questionID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrQuestionNotFound
+			}
+			return err
+		}
+		// на вопрос, скрытый модерацией, ответить нельзя: для всех, кроме автора, его нет
+		if !entity.Published(question.ModerationStatus) {
+			return entity.ErrQuestionNotFound
+		}
+
+		created, err := s.answerRepo.Create(ctx, answer)
+		if err != nil {
+			return err
+		}
+		createdAnswer = created
+		if err := recordAudit(ctx, s.audit, entity.AuditActionCreate, entity.AuditEntityAnswer, created.ID, nil, created); err != nil {
+			return err
+		}
+		if !entity.Published(created.ModerationStatus) {
+			return nil
+		}
+		return recordEvent(ctx, s.outbox, events.TypeAnswerCreated, created.QuestionID, created)
+	})
 	if err != nil {
-		return nil, entity.ErrDatabaseQuery
+		if err == entity.ErrQuestionNotFound {
+			return nil, spanError(span, entity.ErrQuestionNotFound)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
 	}
 
 	return createdAnswer, nil
 }
 
 func (s *answerService) GetAnswer(ctx context.Context, id int) (*entity.Answer, error) {
+	ctx, span := startSpan(ctx, "AnswerService.GetAnswer")
+	defer span.End()
+
 	answer, err := s.answerRepo.GetByID(ctx, id)
 	if err != nil {
-		return nil, entity.ErrAnswerNotFound
+		return nil, spanError(span, entity.ErrAnswerNotFound)
 	}
 	return answer, nil
 }
 
+func (s *answerService) GetAnswersByIDs(ctx context.Context, ids []int) ([]entity.Answer, []int, error) {
+	ctx, span := startSpan(ctx, "AnswerService.GetAnswersByIDs")
+	defer span.End()
+
+	ids, err := uniqueIDs(ids)
+	if err != nil {
+		return nil, nil, spanError(span, err)
+	}
+
+	answers, err := s.answerRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	answers, missing := orderByIDs(ids, answers, func(a entity.Answer) int { return a.ID })
+	return answers, missing, nil
+}
+
 func (s *answerService) GetAnswersByQuestion(ctx context.Context, questionID int) ([]entity.Answer, error) {
-	answers, err := s.answerRepo.GetByQuestionID(ctx, questionID)
+	ctx, span := startSpan(ctx, "AnswerService.GetAnswersByQuestion")
+	defer span.End()
+
+	answers, err := s.answerRepo.GetPublishedByQuestionID(ctx, questionID, AuditMetaFromContext(ctx).Actor)
 	if err != nil {
-		return nil, entity.ErrDatabaseQuery
+		return nil, spanError(span, entity.ErrDatabaseQuery)
 	}
 	return answers, nil
 }
 
-func (s *answerService) DeleteAnswer(ctx context.Context, id int) error {
-	if err := s.answerRepo.Delete(ctx, id); err != nil {
-		if err == entity.ErrAnswerNotFound {
-			return entity.ErrAnswerNotFound
+func (s *answerService) GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+	ctx, span := startSpan(ctx, "AnswerService.GetAnswersByQuestionIDs")
+	defer span.End()
+
+	answers, err := s.answerRepo.GetPublishedByQuestionIDs(ctx, questionIDs, AuditMetaFromContext(ctx).Actor)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+
+	byQuestion := make(map[int][]entity.Answer, len(questionIDs))
+	for _, answer := range answers {
+		byQuestion[answer.QuestionID] = append(byQuestion[answer.QuestionID], answer)
+	}
+	// репозиторий отдаёт ответы от старых к новым
+	for _, group := range byQuestion {
+		slices.Reverse(group)
+	}
+	return byQuestion, nil
+}
+
+func (s *answerService) GetAnswerStats(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error) {
+	ctx, span := startSpan(ctx, "AnswerService.GetAnswerStats")
+	defer span.End()
+
+	stats, err := s.answerRepo.GetStatsByQuestionIDs(ctx, questionIDs)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return stats, nil
+}
+
+func (s *answerService) DeleteAnswer(ctx context.Context, id, expectedVersion int) error {
+	ctx, span := startSpan(ctx, "AnswerService.DeleteAnswer")
+	defer span.End()
+
+	answer, err := s.answerRepo.GetByID(ctx, id)
+	if err != nil {
+		return spanError(span, entity.ErrAnswerNotFound)
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := deleteVersion(ctx, id, expectedVersion, answer.Version, s.answerRepo.Delete, s.answerRepo.DeleteVersion); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityAnswer, id, answer, nil); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, events.TypeAnswerDeleted, answer.QuestionID, map[string]int{
+			"id":          answer.ID,
+			"question_id": answer.QuestionID,
+		})
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return spanError(span, customErr)
 		}
-		return entity.ErrDatabaseQuery
+		return spanError(span, entity.ErrDatabaseQuery)
 	}
 	return nil
 }
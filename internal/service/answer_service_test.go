@@ -0,0 +1,301 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// lockingStore моделирует строки вопросов с блокировками: FOR SHARE держится до конца
+// транзакции, удаление ждёт снятия всех блокировок и каскадно удаляет ответы.
+// Вставка ответа к удалённому вопросу падает с непереведённой ошибкой FK, как без проверки
+type lockingStore struct {
+	mu        sync.Mutex
+	questions map[int]*sync.RWMutex
+	answers   map[int]int
+	nextID    int
+	// beforeInsert вызывается между проверкой вопроса и вставкой ответа
+	beforeInsert func()
+}
+
+type storeTxKey struct{}
+
+type storeTx struct {
+	unlocks []func()
+}
+
+func (s *lockingStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	tx := &storeTx{}
+	defer func() {
+		for _, unlock := range tx.unlocks {
+			unlock()
+		}
+	}()
+	return fn(context.WithValue(ctx, storeTxKey{}, tx))
+}
+
+type lockingQuestionRepository struct {
+	repository.QuestionRepository
+	store *lockingStore
+}
+
+func (r *lockingQuestionRepository) GetByIDForShare(ctx context.Context, id int) (*entity.Question, error) {
+	r.store.mu.Lock()
+	lock, ok := r.store.questions[id]
+	r.store.mu.Unlock()
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	lock.RLock()
+	tx := ctx.Value(storeTxKey{}).(*storeTx)
+	tx.unlocks = append(tx.unlocks, lock.RUnlock)
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.questions[id]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entity.Question{ID: id}, nil
+}
+
+func (r *lockingQuestionRepository) GetByID(ctx context.Context, id int) (*entity.Question, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.questions[id]; !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &entity.Question{ID: id}, nil
+}
+
+func (r *lockingQuestionRepository) Delete(ctx context.Context, id int) error {
+	r.store.mu.Lock()
+	lock, ok := r.store.questions[id]
+	r.store.mu.Unlock()
+	if !ok {
+		return entity.ErrQuestionNotFound
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	delete(r.store.questions, id)
+	for answerID, questionID := range r.store.answers {
+		if questionID == id {
+			delete(r.store.answers, answerID)
+		}
+	}
+	return nil
+}
+
+type lockingAnswerRepository struct {
+	repository.AnswerRepository
+	store *lockingStore
+}
+
+func (r *lockingAnswerRepository) Create(ctx context.Context, answer *entity.Answer) (*entity.Answer, error) {
+	if r.store.beforeInsert != nil {
+		r.store.beforeInsert()
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	if _, ok := r.store.questions[answer.QuestionID]; !ok {
+		return nil, errors.New(`insert or update on table "answers" violates foreign key constraint`)
+	}
+	r.store.nextID++
+	answer.ID = r.store.nextID
+	r.store.answers[answer.ID] = answer.QuestionID
+	return answer, nil
+}
+
+func newLockingServices(questionCount int) (*lockingStore, QuestionService, AnswerService) {
+	store := &lockingStore{
+		questions: make(map[int]*sync.RWMutex),
+		answers:   make(map[int]int),
+	}
+	for id := 1; id <= questionCount; id++ {
+		store.questions[id] = &sync.RWMutex{}
+	}
+	questionRepo := &lockingQuestionRepository{store: store}
+	answerRepo := &lockingAnswerRepository{store: store}
+	return store, NewQuestionService(questionRepo, store, nil, nil, nil), NewAnswerService(answerRepo, questionRepo, store, nil, nil, nil)
+}
+
+func TestAnswerService_ModerationRejectsAndHoldsContent(t *testing.T) {
+	store, _, _ := newLockingServices(1)
+	questionRepo := &lockingQuestionRepository{store: store}
+	moderator := moderation.NewDefaultChain(moderation.Policy{BannedWords: []string{"дурак"}, MaxLinks: 1, MaxRepeatedChars: 5})
+	answerService := NewAnswerService(&lockingAnswerRepository{store: store}, questionRepo, store, nil, nil, moderator)
+
+	if _, err := answerService.CreateAnswer(actorContext("user1"), 1, "user1", "Спроси у дураков"); err != entity.ErrBannedWords {
+		t.Errorf("expected %q, got %v", entity.ErrBannedWords.Message, err)
+	}
+	if len(store.answers) != 0 {
+		t.Errorf("expected rejected answer not to be saved, got %d", len(store.answers))
+	}
+
+	answer, err := answerService.CreateAnswer(actorContext("user1"), 1, "user1", "Смотри https://a.example и https://b.example")
+	if err != nil {
+		t.Fatalf("expected held answer to be saved, got %v", err)
+	}
+	if answer.ModerationStatus != entity.ModerationPending || answer.ModerationReason != entity.ErrSuspectedSpam.Message {
+		t.Errorf("expected answer pending as suspected spam, got %+v", answer)
+	}
+
+	answer, err = answerService.CreateAnswer(actorContext("user1"), 1, "user1", "Используйте context.WithTimeout")
+	if err != nil {
+		t.Fatalf("expected clean answer to be created, got %v", err)
+	}
+	if answer.ModerationStatus != entity.ModerationApproved {
+		t.Errorf("expected clean answer to be approved, got %+v", answer)
+	}
+}
+
+// actorContext контекст запроса от авторизованного пользователя
+func actorContext(userID string) context.Context {
+	return WithAuditMeta(context.Background(), entity.AuditMeta{Actor: userID})
+}
+
+func TestAnswerService_AuthorFromAuthenticatedUser(t *testing.T) {
+	_, _, answerService := newLockingServices(1)
+	alice := actorContext("alice")
+
+	if _, err := answerService.CreateAnswer(context.Background(), 1, "alice", "Без токена"); !errors.Is(err, entity.ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized, got %v", err)
+	}
+
+	if _, err := answerService.CreateAnswer(alice, 1, "bob", "Чужое имя"); !errors.Is(err, entity.ErrUserIDMismatch) {
+		t.Errorf("expected ErrUserIDMismatch, got %v", err)
+	}
+	answer, err := answerService.CreateAnswer(alice, 1, "", "Без user_id")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if answer.UserID != "alice" {
+		t.Errorf("expected author alice, got %s", answer.UserID)
+	}
+}
+
+func TestAnswerService_DeleteWaitsForAnswerCreation(t *testing.T) {
+	checked := make(chan struct{})
+	proceed := make(chan struct{})
+	store, questionService, answerService := newLockingServices(1)
+	store.beforeInsert = func() {
+		close(checked)
+		<-proceed
+	}
+
+	created := make(chan error, 1)
+	go func() {
+		_, err := answerService.CreateAnswer(actorContext("user1"), 1, "user1", "Go is a language")
+		created <- err
+	}()
+
+	<-checked
+	deleted := make(chan error, 1)
+	go func() {
+		deleted <- questionService.DeleteQuestion(context.Background(), 1, 0)
+	}()
+
+	select {
+	case err := <-deleted:
+		t.Fatalf("expected delete to wait for answer creation, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(proceed)
+
+	if err := <-created; err != nil {
+		t.Errorf("expected answer to be created, got %v", err)
+	}
+	if err := <-deleted; err != nil {
+		t.Errorf("expected question to be deleted, got %v", err)
+	}
+	if len(store.answers) != 0 {
+		t.Errorf("expected answers to be deleted with question, got %d", len(store.answers))
+	}
+}
+
+func TestAnswerService_ConcurrentDeleteAndCreate(t *testing.T) {
+	const questions = 50
+	_, questionService, answerService := newLockingServices(questions)
+
+	var wg sync.WaitGroup
+	for id := 1; id <= questions; id++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := answerService.CreateAnswer(actorContext("user1"), id, "user1", "Go is a language")
+			if err != nil && err != entity.ErrQuestionNotFound {
+				t.Errorf("expected success or ErrQuestionNotFound, got %v", err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if err := questionService.DeleteQuestion(context.Background(), id, 0); err != nil {
+				t.Errorf("unexpected delete error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// TestAnswerService_ConcurrentDeleteAndCreatePostgres проверяет то же на реальной БД.
+// TEST_POSTGRES_DSN — DSN базы с применёнными миграциями
+func TestAnswerService_ConcurrentDeleteAndCreatePostgres(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN не задан")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect: %v", err)
+	}
+
+	txManager := repository.NewTxManager(db)
+	questionRepo := repository.NewQuestionRepository(db)
+	outbox := repository.NewOutboxRepository(db)
+	questionService := NewQuestionService(questionRepo, txManager, outbox, nil, nil)
+	answerService := NewAnswerService(repository.NewAnswerRepository(db), questionRepo, txManager, outbox, nil, nil)
+
+	for i := 0; i < 20; i++ {
+		question, err := questionService.CreateQuestion(context.Background(), "race "+time.Now().Format(time.RFC3339Nano))
+		if err != nil {
+			t.Fatalf("failed to create question: %v", err)
+		}
+
+		var wg sync.WaitGroup
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := answerService.CreateAnswer(actorContext("user1"), question.ID, "user1", "answer")
+				if err != nil && err != entity.ErrQuestionNotFound {
+					t.Errorf("expected success or ErrQuestionNotFound, got %v", err)
+				}
+			}()
+		}
+		if err := questionService.DeleteQuestion(context.Background(), question.ID, 0); err != nil {
+			t.Errorf("unexpected delete error: %v", err)
+		}
+		wg.Wait()
+
+		var orphans int64
+		db.Model(&entity.Answer{}).Where("question_id = ?", question.ID).Count(&orphans)
+		if orphans != 0 {
+			t.Errorf("expected no answers for deleted question, got %d", orphans)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type auditContextKey struct{}
+
+// WithAuditMeta кладёт в контекст автора операции, ID запроса и IP. Вызывается транспортом
+// на входе запроса: HTTP middleware, gRPC interceptor, командами CLI
+func WithAuditMeta(ctx context.Context, meta entity.AuditMeta) context.Context {
+	return context.WithValue(ctx, auditContextKey{}, meta)
+}
+
+func AuditMetaFromContext(ctx context.Context) entity.AuditMeta {
+	meta, _ := ctx.Value(auditContextKey{}).(entity.AuditMeta)
+	return meta
+}
+
+// NewRequestID случайный ID запроса для журнала аудита, если клиент не передал свой
+func NewRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// maxRequestIDLength ограничивает ID запроса, переданный клиентом
+const maxRequestIDLength = 64
+
+// ValidRequestID принимает короткие ID из букв, цифр, '-', '_' и '.', чтобы клиент не мог записать
+// в журнал произвольный текст
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// recordAudit записывает изменение в журнал аудита. Как и recordEvent, вызывается внутри WithinTx,
+// поэтому запись сохраняется тогда и только тогда, когда фиксируется само изменение.
+// before или after равен nil, если снимка нет (создание, удаление)
+func recordAudit(ctx context.Context, audit repository.AuditRepository, action, entityType string, entityID int, before, after any) error {
+	if audit == nil {
+		return nil
+	}
+	meta := AuditMetaFromContext(ctx)
+	event := &entity.AuditEvent{
+		Actor:      meta.Actor,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		RequestID:  meta.RequestID,
+		IP:         meta.IP,
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return err
+		}
+	}
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return err
+		}
+	}
+	return audit.Add(ctx, event)
+}
+
+// AuditService чтение журнала аудита
+type AuditService interface {
+	// ListEvents возвращает до limit событий, новые первыми; beforeID — ID последнего события
+	// предыдущей страницы, 0 для первой страницы
+	ListEvents(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error)
+}
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+func (s *auditService) ListEvents(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error) {
+	ctx, span := startSpan(ctx, "AuditService.ListEvents")
+	defer span.End()
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return nil, spanError(span, entity.ErrInvalidTimeRange)
+	}
+
+	events, err := s.repo.List(ctx, filter, beforeID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return events, nil
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type memoryAuditRepository struct {
+	repository.AuditRepository
+	events []entity.AuditEvent
+	err    error
+}
+
+func (r *memoryAuditRepository) Add(ctx context.Context, event *entity.AuditEvent) error {
+	if r.err != nil {
+		return r.err
+	}
+	event.ID = int64(len(r.events) + 1)
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *memoryTransferQuestionRepository) DeleteVersion(ctx context.Context, id, version int) (bool, error) {
+	question, err := r.GetByID(ctx, id)
+	if err != nil || question.Version != version {
+		return false, nil
+	}
+	return true, r.Delete(ctx, id)
+}
+
+func TestQuestionService_DeleteChecksVersion(t *testing.T) {
+	store := &memoryTransferStore{questions: []entity.Question{{ID: 1, Text: "Что такое Go?", Version: 2}}}
+	audit := &memoryAuditRepository{}
+	svc := NewQuestionService(&memoryTransferQuestionRepository{store: store}, store, nil, audit, nil)
+
+	if err := svc.DeleteQuestion(context.Background(), 1, 1); !errors.Is(err, entity.ErrPreconditionFailed) {
+		t.Fatalf("expected ErrPreconditionFailed for a stale version, got %v", err)
+	}
+	if len(store.questions) != 1 || len(audit.events) != 0 {
+		t.Fatalf("expected question to stay without audit events, got %d questions and %d events", len(store.questions), len(audit.events))
+	}
+	if err := svc.DeleteQuestion(context.Background(), 1, 2); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(store.questions) != 0 {
+		t.Errorf("expected question to be deleted, got %+v", store.questions)
+	}
+}
+
+func TestQuestionService_RecordsAudit(t *testing.T) {
+	store := &memoryTransferStore{}
+	audit := &memoryAuditRepository{}
+	svc := NewQuestionService(&memoryTransferQuestionRepository{store: store}, store, nil, audit, nil)
+	ctx := WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "alice", RequestID: "req-1", IP: "10.0.0.1"})
+
+	question, err := svc.CreateQuestion(ctx, "Что такое Go?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.DeleteQuestion(ctx, question.ID, 0); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(audit.events) != 2 {
+		t.Fatalf("expected 2 audit events, got %d", len(audit.events))
+	}
+	created, deleted := audit.events[0], audit.events[1]
+	if created.Action != entity.AuditActionCreate || created.Before != nil || created.After == nil {
+		t.Errorf("expected create with only an after snapshot, got %+v", created)
+	}
+	if deleted.Action != entity.AuditActionDelete || deleted.After != nil || deleted.EntityID != question.ID {
+		t.Errorf("expected delete of question %d with only a before snapshot, got %+v", question.ID, deleted)
+	}
+	var before entity.Question
+	if err := json.Unmarshal(deleted.Before, &before); err != nil || before.Text != "Что такое Go?" {
+		t.Errorf("expected before snapshot with the question text, got %s", deleted.Before)
+	}
+	if deleted.Actor != "alice" || deleted.RequestID != "req-1" || deleted.IP != "10.0.0.1" {
+		t.Errorf("expected audit meta from context, got %+v", deleted)
+	}
+
+	// без записи в журнал изменение не фиксируется
+	audit.err = errors.New("connection reset")
+	if _, err := svc.CreateQuestion(ctx, "Второй вопрос"); err != entity.ErrDatabaseQuery {
+		t.Errorf("expected ErrDatabaseQuery, got %v", err)
+	}
+	if len(store.questions) != 0 {
+		t.Errorf("expected the question to be rolled back, got %+v", store.questions)
+	}
+}
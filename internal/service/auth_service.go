@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type AuthService interface {
+	// Authenticate возвращает ID пользователя по токену доступа
+	Authenticate(ctx context.Context, token string) (string, error)
+}
+
+type staticTokenAuthService struct {
+	tokens map[string]string
+}
+
+// NewStaticTokenAuthService проверяет токены по статическому списку token -> user_id из конфигурации
+func NewStaticTokenAuthService(tokens map[string]string) AuthService {
+	return &staticTokenAuthService{tokens: tokens}
+}
+
+func (s *staticTokenAuthService) Authenticate(ctx context.Context, token string) (string, error) {
+	if token == "" {
+		return "", entity.ErrUnauthorized
+	}
+	for known, userID := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return userID, nil
+		}
+	}
+	return "", entity.ErrUnauthorized
+}
@@ -0,0 +1,40 @@
+package service
+
+import "github.com/andrey-samosuk/answer-questions/internal/entity"
+
+// MaxBatchIDs максимальное число ID в одном пакетном запросе
+const MaxBatchIDs = 100
+
+// uniqueIDs убирает повторы, сохраняя порядок запроса, и проверяет размер пакета
+func uniqueIDs(ids []int) ([]int, error) {
+	seen := make(map[int]bool, len(ids))
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchIDs {
+		return nil, entity.ErrTooManyIDs
+	}
+	return unique, nil
+}
+
+// orderByIDs раскладывает найденное в порядке запрошенных ID и возвращает ID, которых нет
+func orderByIDs[T any](ids []int, found []T, idOf func(T) int) ([]T, []int) {
+	byID := make(map[int]T, len(found))
+	for _, item := range found {
+		byID[idOf(item)] = item
+	}
+	ordered := make([]T, 0, len(found))
+	missing := []int{}
+	for _, id := range ids {
+		if item, ok := byID[id]; ok {
+			ordered = append(ordered, item)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return ordered, missing
+}
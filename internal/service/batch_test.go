@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type batchQuestionRepository struct {
+	repository.QuestionRepository
+	questions map[int]entity.Question
+	requested []int
+}
+
+func (r *batchQuestionRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Question, error) {
+	r.requested = ids
+	var found []entity.Question
+	for _, id := range ids {
+		if q, ok := r.questions[id]; ok {
+			found = append(found, q)
+		}
+	}
+	// как и в БД, результат идёт в порядке ID, а не в порядке запроса
+	slices.SortFunc(found, func(a, b entity.Question) int { return a.ID - b.ID })
+	return found, nil
+}
+
+func TestQuestionService_GetQuestionsByIDs(t *testing.T) {
+	repo := &batchQuestionRepository{questions: map[int]entity.Question{
+		1: {ID: 1, Text: "Q1"},
+		3: {ID: 3, Text: "Q3"},
+	}}
+	questionService := NewQuestionService(repo, nil, nil, nil, nil)
+
+	questions, missing, err := questionService.GetQuestionsByIDs(context.Background(), []int{3, 2, 1, 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !slices.Equal(repo.requested, []int{3, 2, 1}) {
+		t.Errorf("expected one query for unique ids [3 2 1], got %v", repo.requested)
+	}
+	if len(questions) != 2 || questions[0].ID != 3 || questions[1].ID != 1 {
+		t.Errorf("expected questions in requested order [3 1], got %+v", questions)
+	}
+	if !slices.Equal(missing, []int{2}) {
+		t.Errorf("expected missing [2], got %v", missing)
+	}
+
+	tooMany := make([]int, MaxBatchIDs+1)
+	for i := range tooMany {
+		tooMany[i] = i + 1
+	}
+	if _, _, err := questionService.GetQuestionsByIDs(context.Background(), tooMany); !errors.Is(err, entity.ErrTooManyIDs) {
+		t.Errorf("expected ErrTooManyIDs, got %v", err)
+	}
+}
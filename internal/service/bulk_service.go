@@ -0,0 +1,287 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+const (
+	// bulkMaxIDs ограничивает список ID в запросе; большие выборки задаются фильтрами
+	bulkMaxIDs = 1000
+	// jobLease аренда задачи продлевается после каждой пачки, пачка должна уложиться в это время
+	jobLease          = time.Minute
+	jobMaxErrorLength = 1000
+)
+
+// errJobLeaseLost аренда задачи истекла, и её забрала другая реплика: эта реплика прекращает работу
+var errJobLeaseLost = errors.New("аренда задачи потеряна")
+
+// BulkService массовые административные операции. Небольшие выборки обрабатываются сразу
+// в одной транзакции, большие — фоновой задачей пачками
+type BulkService interface {
+	// DeleteAnswers удаляет ответы по фильтру; dryRun только считает подходящие ответы.
+	// Если подходящих не больше BulkPolicy.SyncLimit, они удаляются в одной транзакции,
+	// иначе создаётся задача и в результате возвращается её ID
+	DeleteAnswers(ctx context.Context, requestedBy string, filter entity.AnswerFilter, dryRun bool) (*entity.BulkDeleteResult, error)
+
+	GetJob(ctx context.Context, id int64) (*entity.Job, error)
+
+	// RunNextJob забирает и выполняет одну задачу; false, если задач нет. При отмене ctx задача
+	// остаётся running и после истечения аренды продолжается с того места, где остановилась
+	RunNextJob(ctx context.Context) (bool, error)
+}
+
+// BulkPolicy SyncLimit — сколько ответов можно удалить прямо в запросе,
+// BatchSize — сколько ответов фоновая задача удаляет в одной транзакции
+type BulkPolicy struct {
+	SyncLimit int
+	BatchSize int
+}
+
+type bulkService struct {
+	answerRepo  repository.AnswerRepository
+	jobRepo     repository.JobRepository
+	txManager   repository.TxManager
+	outbox      repository.OutboxRepository
+	audit       repository.AuditRepository
+	invalidator CacheInvalidator
+	policy      BulkPolicy
+	now         func() time.Time
+}
+
+// NewBulkService invalidator nil, если кэш выключен
+func NewBulkService(
+	answerRepo repository.AnswerRepository,
+	jobRepo repository.JobRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+	invalidator CacheInvalidator,
+	policy BulkPolicy,
+) BulkService {
+	return &bulkService{
+		answerRepo:  answerRepo,
+		jobRepo:     jobRepo,
+		txManager:   txManager,
+		outbox:      outbox,
+		audit:       audit,
+		invalidator: invalidator,
+		policy:      policy,
+		now:         time.Now,
+	}
+}
+
+func ValidateAnswerFilter(filter entity.AnswerFilter) error {
+	if filter.IsEmpty() {
+		return entity.ErrEmptyAnswerFilter
+	}
+	if len(filter.IDs) > bulkMaxIDs {
+		return entity.ErrTooManyBulkIDs
+	}
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return entity.ErrInvalidTimeRange
+	}
+	return nil
+}
+
+func (s *bulkService) DeleteAnswers(ctx context.Context, requestedBy string, filter entity.AnswerFilter, dryRun bool) (*entity.BulkDeleteResult, error) {
+	ctx, span := startSpan(ctx, "BulkService.DeleteAnswers")
+	defer span.End()
+
+	if err := ValidateAnswerFilter(filter); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	matched, err := s.answerRepo.CountByFilter(ctx, filter)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	result := &entity.BulkDeleteResult{Matched: matched, DryRun: dryRun}
+	if dryRun || matched == 0 {
+		return result, nil
+	}
+
+	if matched <= int64(s.policy.SyncLimit) {
+		var deleted []entity.Answer
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			deleted, err = s.deleteAnswers(ctx, filter, 0)
+			return err
+		})
+		if err != nil {
+			return nil, spanError(span, entity.ErrDatabaseQuery)
+		}
+		result.Deleted = len(deleted)
+		s.invalidateAnswers(ctx, deleted)
+		return result, nil
+	}
+
+	// ответы, созданные после запроса, задача не трогает, даже если они подходят под фильтр
+	if filter.CreatedTo == nil {
+		createdTo := s.now()
+		filter.CreatedTo = &createdTo
+	}
+	params, err := json.Marshal(filter)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	job := &entity.Job{
+		Type:      entity.JobTypeAnswersBulkDelete,
+		Status:    entity.JobPending,
+		Params:    params,
+		Total:     matched,
+		CreatedBy: requestedBy,
+	}
+	if err := s.jobRepo.Create(ctx, job); err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	result.JobID = job.ID
+	return result, nil
+}
+
+func (s *bulkService) GetJob(ctx context.Context, id int64) (*entity.Job, error) {
+	ctx, span := startSpan(ctx, "BulkService.GetJob")
+	defer span.End()
+
+	job, err := s.jobRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, spanError(span, entity.ErrJobNotFound)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return job, nil
+}
+
+func (s *bulkService) RunNextJob(ctx context.Context) (bool, error) {
+	ctx, span := startSpan(ctx, "BulkService.RunNextJob")
+	defer span.End()
+
+	job, err := s.jobRepo.ClaimNext(ctx, s.now(), jobLease)
+	if err != nil {
+		return false, spanError(span, err)
+	}
+	if job == nil {
+		return false, nil
+	}
+
+	runErr := s.runJob(ctx, job)
+	if ctx.Err() != nil {
+		return true, nil
+	}
+	if errors.Is(runErr, errJobLeaseLost) {
+		log.Printf("Задача %d (%s) перешла к другой реплике", job.ID, job.Type)
+		return true, nil
+	}
+
+	finishedAt := s.now()
+	job.FinishedAt = &finishedAt
+	job.LeaseUntil = nil
+	job.Status = entity.JobSucceeded
+	if runErr != nil {
+		log.Printf("Задача %d (%s) завершилась ошибкой: %v", job.ID, job.Type, runErr)
+		job.Status = entity.JobFailed
+		job.Error = truncate(runErr.Error(), jobMaxErrorLength)
+	}
+	ok, err := s.jobRepo.Update(ctx, job)
+	if err != nil {
+		return true, spanError(span, err)
+	}
+	if !ok {
+		log.Printf("Задача %d (%s) перешла к другой реплике, итог не сохранён", job.ID, job.Type)
+	}
+	return true, nil
+}
+
+// runJob удаляет ответы пачками; каждая пачка вместе с прогрессом задачи фиксируется
+// в своей транзакции, поэтому Processed всегда совпадает с числом удалённых ответов.
+// Если задачу забрала другая реплика, пачка откатывается и возвращается errJobLeaseLost
+func (s *bulkService) runJob(ctx context.Context, job *entity.Job) error {
+	if job.Type != entity.JobTypeAnswersBulkDelete {
+		return fmt.Errorf("неизвестный тип задачи %q", job.Type)
+	}
+	var filter entity.AnswerFilter
+	if err := json.Unmarshal(job.Params, &filter); err != nil {
+		return fmt.Errorf("некорректные параметры задачи: %w", err)
+	}
+
+	for ctx.Err() == nil {
+		progress := *job
+		var deleted []entity.Answer
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			var err error
+			deleted, err = s.deleteAnswers(ctx, filter, s.policy.BatchSize)
+			if err != nil {
+				return err
+			}
+			leaseUntil := s.now().Add(jobLease)
+			progress.Processed += int64(len(deleted))
+			progress.LeaseUntil = &leaseUntil
+			ok, err := s.jobRepo.Update(ctx, &progress)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return errJobLeaseLost
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		s.invalidateAnswers(ctx, deleted)
+		done := progress.Processed-job.Processed < int64(s.policy.BatchSize)
+		*job = progress
+		if done {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// invalidateAnswers сбрасывает кэш ответов каждого затронутого вопроса; вызывается после коммита,
+// иначе параллельное чтение может снова закэшировать ещё не удалённые ответы
+func (s *bulkService) invalidateAnswers(ctx context.Context, deleted []entity.Answer) {
+	if s.invalidator == nil {
+		return
+	}
+	invalidated := make(map[int]bool)
+	for _, answer := range deleted {
+		if !invalidated[answer.QuestionID] {
+			invalidated[answer.QuestionID] = true
+			s.invalidator.InvalidateAnswers(ctx, answer.QuestionID)
+		}
+	}
+}
+
+// deleteAnswers удаляет ответы и записывает для каждого событие answer.deleted и запись аудита;
+// вызывается внутри WithinTx
+func (s *bulkService) deleteAnswers(ctx context.Context, filter entity.AnswerFilter, limit int) ([]entity.Answer, error) {
+	deleted, err := s.answerRepo.DeleteByFilter(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, answer := range deleted {
+		if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityAnswer, answer.ID, answer, nil); err != nil {
+			return nil, err
+		}
+		err := recordEvent(ctx, s.outbox, events.TypeAnswerDeleted, answer.QuestionID, map[string]int{
+			"id":          answer.ID,
+			"question_id": answer.QuestionID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return deleted, nil
+}
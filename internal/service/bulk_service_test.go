@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+func matchesAnswerFilter(a entity.Answer, filter entity.AnswerFilter) bool {
+	if len(filter.IDs) > 0 {
+		found := false
+		for _, id := range filter.IDs {
+			found = found || a.ID == id
+		}
+		if !found {
+			return false
+		}
+	}
+	return (filter.UserID == "" || a.UserID == filter.UserID) &&
+		(filter.QuestionID == 0 || a.QuestionID == filter.QuestionID) &&
+		(filter.CreatedFrom == nil || !a.CreatedAt.Before(*filter.CreatedFrom)) &&
+		(filter.CreatedTo == nil || a.CreatedAt.Before(*filter.CreatedTo))
+}
+
+func (r *memoryTransferAnswerRepository) CountByFilter(ctx context.Context, filter entity.AnswerFilter) (int64, error) {
+	var count int64
+	for _, a := range r.store.answers {
+		if matchesAnswerFilter(a, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryTransferAnswerRepository) DeleteByFilter(ctx context.Context, filter entity.AnswerFilter, limit int) ([]entity.Answer, error) {
+	var deleted []entity.Answer
+	kept := r.store.answers[:0:0]
+	for _, a := range r.store.answers {
+		if matchesAnswerFilter(a, filter) && (limit == 0 || len(deleted) < limit) {
+			deleted = append(deleted, a)
+			continue
+		}
+		kept = append(kept, a)
+	}
+	r.store.answers = kept
+	return deleted, nil
+}
+
+func (r *memoryTransferAnswerRepository) GetPublishedByQuestionID(ctx context.Context, questionID int, viewerID string) ([]entity.Answer, error) {
+	return r.GetPublishedByQuestionIDs(ctx, []int{questionID}, viewerID)
+}
+
+type memoryJobRepository struct {
+	repository.JobRepository
+	jobs    []entity.Job
+	updates int
+	failAt  int
+	stealAt int
+}
+
+func (r *memoryJobRepository) Create(ctx context.Context, job *entity.Job) error {
+	job.ID = int64(len(r.jobs) + 1)
+	r.jobs = append(r.jobs, *job)
+	return nil
+}
+
+func (r *memoryJobRepository) GetByID(ctx context.Context, id int64) (*entity.Job, error) {
+	for _, job := range r.jobs {
+		if job.ID == id {
+			return &job, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryJobRepository) ClaimNext(ctx context.Context, now time.Time, lease time.Duration) (*entity.Job, error) {
+	for i := range r.jobs {
+		job := &r.jobs[i]
+		if job.Status == entity.JobPending || (job.Status == entity.JobRunning && job.LeaseUntil.Before(now)) {
+			leaseUntil := now.Add(lease)
+			job.Status = entity.JobRunning
+			job.LeaseUntil = &leaseUntil
+			job.LeaseToken = fmt.Sprintf("token-%d", now.UnixNano())
+			claimed := *job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+// Update возвращает ошибку на failAt-м вызове, чтобы проверить откат пачки,
+// а перед stealAt-м вызовом отдаёт задачу другой реплике
+func (r *memoryJobRepository) Update(ctx context.Context, job *entity.Job) (bool, error) {
+	r.updates++
+	if r.updates == r.failAt {
+		return false, errors.New("connection reset")
+	}
+	if r.updates == r.stealAt {
+		r.jobs[job.ID-1].LeaseToken = "another-worker"
+	}
+	if r.jobs[job.ID-1].LeaseToken != job.LeaseToken {
+		return false, nil
+	}
+	r.jobs[job.ID-1] = *job
+	return true, nil
+}
+
+func newMemoryBulkService(answers int, policy BulkPolicy) (*memoryTransferStore, *memoryJobRepository, *memoryOutboxRepository, BulkService) {
+	store := &memoryTransferStore{}
+	created := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= answers; i++ {
+		userID := "spammer"
+		if i%2 == 0 {
+			userID = "alice"
+		}
+		store.answers = append(store.answers, entity.Answer{ID: i, QuestionID: 1, UserID: userID, CreatedAt: created.Add(time.Duration(i) * time.Minute)})
+	}
+	jobs := &memoryJobRepository{}
+	outbox := &memoryOutboxRepository{}
+	return store, jobs, outbox, NewBulkService(&memoryTransferAnswerRepository{store: store}, jobs, store, outbox, nil, nil, policy)
+}
+
+func TestBulkService_DeleteAnswersSync(t *testing.T) {
+	ctx := context.Background()
+	store, jobs, outbox, svc := newMemoryBulkService(6, BulkPolicy{SyncLimit: 10, BatchSize: 2})
+
+	if _, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{}, false); err != entity.ErrEmptyAnswerFilter {
+		t.Errorf("expected ErrEmptyAnswerFilter, got %v", err)
+	}
+	from, to := time.Date(2025, 12, 2, 0, 0, 0, 0, time.UTC), time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{CreatedFrom: &from, CreatedTo: &to}, false); err != entity.ErrInvalidTimeRange {
+		t.Errorf("expected ErrInvalidTimeRange, got %v", err)
+	}
+
+	filter := entity.AnswerFilter{UserID: "spammer"}
+	preview, err := svc.DeleteAnswers(ctx, "admin", filter, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if preview.Matched != 3 || preview.Deleted != 0 || len(store.answers) != 6 {
+		t.Errorf("expected dry run to match 3 answers and keep all 6, got %+v and %d", preview, len(store.answers))
+	}
+
+	result, err := svc.DeleteAnswers(ctx, "admin", filter, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Deleted != 3 || result.JobID != 0 || len(jobs.jobs) != 0 {
+		t.Errorf("expected 3 answers deleted without a job, got %+v", result)
+	}
+	if len(store.answers) != 3 || len(outbox.messages) != 3 {
+		t.Errorf("expected 3 answers left and 3 events, got %d and %d", len(store.answers), len(outbox.messages))
+	}
+}
+
+func TestBulkService_DeleteAnswersJob(t *testing.T) {
+	ctx := context.Background()
+	store, jobs, _, svc := newMemoryBulkService(10, BulkPolicy{SyncLimit: 2, BatchSize: 2})
+	jobs.failAt = 2
+
+	result, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{UserID: "spammer"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.JobID == 0 || result.Matched != 5 || len(store.answers) != 10 {
+		t.Fatalf("expected a job for 5 answers and nothing deleted yet, got %+v", result)
+	}
+
+	// вторая пачка падает: её удаление откатывается, задача завершается с ошибкой
+	if ran, err := svc.RunNextJob(ctx); !ran || err != nil {
+		t.Fatalf("expected the job to run, got %v, %v", ran, err)
+	}
+	job, err := svc.GetJob(ctx, result.JobID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if job.Status != entity.JobFailed || job.Processed != 2 || job.FinishedAt == nil {
+		t.Errorf("expected failed job with 2 processed, got %+v", job)
+	}
+	if len(store.answers) != 8 {
+		t.Errorf("expected only the first batch deleted, got %d answers left", len(store.answers))
+	}
+
+	// повторный запрос подхватывает оставшиеся ответы
+	result, err = svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{UserID: "spammer"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for {
+		ran, err := svc.RunNextJob(ctx)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !ran {
+			break
+		}
+	}
+	job, _ = svc.GetJob(ctx, result.JobID)
+	if job.Status != entity.JobSucceeded || job.Processed != job.Total || job.Total != 3 {
+		t.Errorf("expected succeeded job with 3/3 processed, got %+v", job)
+	}
+	for _, a := range store.answers {
+		if a.UserID == "spammer" {
+			t.Fatalf("expected all spammer answers deleted, found %+v", a)
+		}
+	}
+
+	if _, err := svc.GetJob(ctx, 99); err != entity.ErrJobNotFound {
+		t.Errorf("expected ErrJobNotFound, got %v", err)
+	}
+}
+
+func TestBulkService_StopsWhenLeaseLost(t *testing.T) {
+	ctx := context.Background()
+	store, jobs, _, svc := newMemoryBulkService(10, BulkPolicy{SyncLimit: 2, BatchSize: 2})
+	jobs.stealAt = 2
+
+	result, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{UserID: "spammer"}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// на второй пачке задачу уже забрала другая реплика: пачка откатывается, итог не пишется
+	if ran, err := svc.RunNextJob(ctx); !ran || err != nil {
+		t.Fatalf("expected the job to run, got %v, %v", ran, err)
+	}
+	job, _ := svc.GetJob(ctx, result.JobID)
+	if job.Status != entity.JobRunning || job.Processed != 2 || job.FinishedAt != nil || job.LeaseToken != "another-worker" {
+		t.Errorf("expected job left to the other worker with 2 processed, got %+v", job)
+	}
+	if len(store.answers) != 8 || jobs.updates != 2 {
+		t.Errorf("expected only the first batch deleted and no final update, got %d answers and %d updates", len(store.answers), jobs.updates)
+	}
+}
+
+func TestBulkService_InvalidatesCachedAnswers(t *testing.T) {
+	ctx := context.Background()
+	store, jobs, outbox, _ := newMemoryBulkService(10, BulkPolicy{})
+	answerRepo := &memoryTransferAnswerRepository{store: store}
+	servicesCache := cache.NewLRU(100)
+	_, answers := NewCachedServices(nil, NewAnswerService(answerRepo, nil, store, nil, nil, nil), servicesCache, time.Minute, time.Second)
+	svc := NewBulkService(answerRepo, jobs, store, outbox, nil, NewCacheInvalidator(servicesCache), BulkPolicy{SyncLimit: 3, BatchSize: 2})
+
+	cachedCount := func() int {
+		list, err := answers.GetAnswersByQuestion(ctx, 1)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return len(list)
+	}
+	if n := cachedCount(); n != 10 {
+		t.Fatalf("expected 10 cached answers, got %d", n)
+	}
+
+	// синхронное удаление сбрасывает кэш ответов вопроса
+	if _, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{IDs: []int{1, 2}}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n := cachedCount(); n != 8 {
+		t.Errorf("expected 8 answers after sync delete, got %d", n)
+	}
+
+	// фоновая задача сбрасывает кэш после каждой пачки
+	if _, err := svc.DeleteAnswers(ctx, "admin", entity.AnswerFilter{UserID: "spammer"}, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ran, err := svc.RunNextJob(ctx); !ran || err != nil {
+		t.Fatalf("expected the job to run, got %v, %v", ran, err)
+	}
+	if n := cachedCount(); n != 4 {
+		t.Errorf("expected 4 answers after the job, got %d", n)
+	}
+}
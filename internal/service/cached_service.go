@@ -0,0 +1,316 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+const allQuestionsCacheKey = "questions:all"
+
+func questionCacheKey(id int) string {
+	return fmt.Sprintf("question:%d", id)
+}
+
+func questionAnswersCacheKey(questionID int) string {
+	return fmt.Sprintf("answers:question:%d", questionID)
+}
+
+// readThrough общая логика чтения через кэш. singleflight объединяет одновременные промахи
+// по одному ключу в один запрос к БД. Загрузка не зависит от отмены контекста первого
+// вызывающего, чтобы его таймаут не ломал ответ остальным ожидающим. Загрузка, во время которой
+// ключ сбросили, могла прочитать данные до изменения и в кэш их не оставляет
+type readThrough struct {
+	cache       cache.Cache
+	ttl         time.Duration
+	loadTimeout time.Duration
+	group       singleflight.Group
+
+	mu sync.Mutex
+	// loads поколения сброса ключей, которые сейчас загружаются
+	loads map[string]*keyLoads
+}
+
+// keyLoads число идущих загрузок ключа и число его сбросов за это время
+type keyLoads struct {
+	running     int
+	invalidated uint64
+}
+
+// startLoad регистрирует загрузку ключа и возвращает текущее поколение сброса
+func (rt *readThrough) startLoad(key string) uint64 {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.loads == nil {
+		rt.loads = make(map[string]*keyLoads)
+	}
+	loads, ok := rt.loads[key]
+	if !ok {
+		loads = &keyLoads{}
+		rt.loads[key] = loads
+	}
+	loads.running++
+	return loads.invalidated
+}
+
+// invalidatedSince сообщает, сбрасывался ли ключ после startLoad
+func (rt *readThrough) invalidatedSince(key string, generation uint64) bool {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return rt.loads[key].invalidated != generation
+}
+
+func (rt *readThrough) finishLoad(key string) {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if loads := rt.loads[key]; loads.running == 1 {
+		delete(rt.loads, key)
+	} else {
+		loads.running--
+	}
+}
+
+// storeLoaded пишет загруженное значение, если ключ не сбрасывали во время загрузки. Сброс
+// между проверкой и записью мог удалить ключ раньше, чем запись дошла до кэша, поэтому
+// после записи поколение проверяется ещё раз
+func (rt *readThrough) storeLoaded(ctx context.Context, key string, data []byte, generation uint64) {
+	if rt.invalidatedSince(key, generation) {
+		return
+	}
+	if err := rt.cache.Set(ctx, key, data, rt.ttl); err != nil {
+		log.Printf("Ошибка записи в кэш %s: %v", key, err)
+	}
+	if rt.invalidatedSince(key, generation) {
+		if err := rt.cache.Delete(ctx, key); err != nil {
+			log.Printf("Ошибка инвалидации кэша %s: %v", key, err)
+		}
+	}
+}
+
+func (rt *readThrough) get(ctx context.Context, key string, dest any, load func(ctx context.Context) (any, error)) error {
+	if data, ok, err := rt.cache.Get(ctx, key); err != nil {
+		log.Printf("Ошибка чтения из кэша %s: %v", key, err)
+	} else if ok {
+		if err := json.Unmarshal(data, dest); err == nil {
+			return nil
+		}
+		log.Printf("Ошибка декодирования значения кэша %s", key)
+	}
+
+	ch := rt.group.DoChan(key, func() (any, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rt.loadTimeout)
+		defer cancel()
+		generation := rt.startLoad(key)
+		defer rt.finishLoad(key)
+
+		value, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		rt.storeLoaded(loadCtx, key, data, generation)
+		return data, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return entity.ErrDatabaseQuery
+	case result := <-ch:
+		if result.Err != nil {
+			return result.Err
+		}
+		if err := json.Unmarshal(result.Val.([]byte), dest); err != nil {
+			return entity.ErrDatabaseQuery
+		}
+		return nil
+	}
+}
+
+// invalidate сбрасывает ключи в кэше и у идущих загрузок; новые промахи не присоединяются
+// к загрузке, начатой до сброса
+func (rt *readThrough) invalidate(ctx context.Context, keys ...string) {
+	rt.mu.Lock()
+	for _, key := range keys {
+		if loads, ok := rt.loads[key]; ok {
+			loads.invalidated++
+		}
+		rt.group.Forget(key)
+	}
+	rt.mu.Unlock()
+
+	if err := rt.cache.Delete(ctx, keys...); err != nil {
+		log.Printf("Ошибка инвалидации кэша %v: %v", keys, err)
+	}
+}
+
+// CacheInvalidator сбрасывает закэшированные вопрос и ответы на него. Нужен сервисам, которые меняют
+// видимость записей в обход кэширующих сервисов (модерация, жалобы); вызывается после коммита
+type CacheInvalidator interface {
+	// InvalidateQuestion сбрасывает вопрос, список вопросов и ответы на вопрос
+	InvalidateQuestion(ctx context.Context, questionID int)
+
+	// InvalidateAnswers сбрасывает ответы на вопрос
+	InvalidateAnswers(ctx context.Context, questionID int)
+}
+
+type cacheInvalidator struct {
+	rt *readThrough
+}
+
+// NewCacheInvalidator c — тот же кэш, что передан в NewCachedServices
+func NewCacheInvalidator(c cache.Cache) CacheInvalidator {
+	return &cacheInvalidator{rt: &readThrough{cache: c}}
+}
+
+func (i *cacheInvalidator) InvalidateQuestion(ctx context.Context, questionID int) {
+	i.rt.invalidate(ctx, allQuestionsCacheKey, questionCacheKey(questionID), questionAnswersCacheKey(questionID))
+}
+
+func (i *cacheInvalidator) InvalidateAnswers(ctx context.Context, questionID int) {
+	i.rt.invalidate(ctx, questionAnswersCacheKey(questionID))
+}
+
+type cachedQuestionService struct {
+	next QuestionService
+	rt   *readThrough
+}
+
+type cachedAnswerService struct {
+	next AnswerService
+	rt   *readThrough
+}
+
+// NewCachedServices оборачивает сервисы кэшем горячих путей чтения: список вопросов,
+// вопрос и ответы на вопрос. Сервисы используют общий кэш, чтобы удаление вопроса
+// сбрасывало и закэшированные ответы на него
+func NewCachedServices(questionService QuestionService, answerService AnswerService, c cache.Cache, ttl, loadTimeout time.Duration) (QuestionService, AnswerService) {
+	rt := &readThrough{cache: c, ttl: ttl, loadTimeout: loadTimeout}
+	return &cachedQuestionService{next: questionService, rt: rt},
+		&cachedAnswerService{next: answerService, rt: rt}
+}
+
+func (s *cachedQuestionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
+	question, err := s.next.CreateQuestion(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	s.rt.invalidate(ctx, allQuestionsCacheKey)
+	return question, nil
+}
+
+func (s *cachedQuestionService) GetQuestion(ctx context.Context, id int) (*entity.Question, error) {
+	var question entity.Question
+	err := s.rt.get(ctx, questionCacheKey(id), &question, func(ctx context.Context) (any, error) {
+		return s.next.GetQuestion(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &question, nil
+}
+
+// GetQuestionsByIDs не кэшируется: набор ID в каждом вызове свой
+func (s *cachedQuestionService) GetQuestionsByIDs(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+	return s.next.GetQuestionsByIDs(ctx, ids)
+}
+
+// GetAllQuestions кэширует только список для анонимных запросов: авторизованный пользователь
+// видит в списке и свои вопросы на проверке, такой список в общий кэш не кладётся
+func (s *cachedQuestionService) GetAllQuestions(ctx context.Context) ([]entity.Question, error) {
+	if AuditMetaFromContext(ctx).Actor != "" {
+		return s.next.GetAllQuestions(ctx)
+	}
+	var questions []entity.Question
+	err := s.rt.get(ctx, allQuestionsCacheKey, &questions, func(ctx context.Context) (any, error) {
+		return s.next.GetAllQuestions(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if questions == nil {
+		return []entity.Question{}, nil
+	}
+	return questions, nil
+}
+
+// GetQuestionsPage не кэшируется: страниц много, и их пришлось бы сбрасывать при каждом изменении
+func (s *cachedQuestionService) GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	return s.next.GetQuestionsPage(ctx, afterID, limit)
+}
+
+func (s *cachedQuestionService) DeleteQuestion(ctx context.Context, id, expectedVersion int) error {
+	if err := s.next.DeleteQuestion(ctx, id, expectedVersion); err != nil {
+		return err
+	}
+	s.rt.invalidate(ctx, allQuestionsCacheKey, questionCacheKey(id), questionAnswersCacheKey(id))
+	return nil
+}
+
+func (s *cachedAnswerService) CreateAnswer(ctx context.Context, questionID int, userID, text string) (*entity.Answer, error) {
+	answer, err := s.next.CreateAnswer(ctx, questionID, userID, text)
+	if err != nil {
+		return nil, err
+	}
+	s.rt.invalidate(ctx, questionAnswersCacheKey(questionID))
+	return answer, nil
+}
+
+func (s *cachedAnswerService) GetAnswer(ctx context.Context, id int) (*entity.Answer, error) {
+	return s.next.GetAnswer(ctx, id)
+}
+
+func (s *cachedAnswerService) GetAnswersByIDs(ctx context.Context, ids []int) ([]entity.Answer, []int, error) {
+	return s.next.GetAnswersByIDs(ctx, ids)
+}
+
+// GetAnswersByQuestion кэширует, как и GetAllQuestions, только ответы для анонимных запросов
+func (s *cachedAnswerService) GetAnswersByQuestion(ctx context.Context, questionID int) ([]entity.Answer, error) {
+	if AuditMetaFromContext(ctx).Actor != "" {
+		return s.next.GetAnswersByQuestion(ctx, questionID)
+	}
+	var answers []entity.Answer
+	err := s.rt.get(ctx, questionAnswersCacheKey(questionID), &answers, func(ctx context.Context) (any, error) {
+		return s.next.GetAnswersByQuestion(ctx, questionID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if answers == nil {
+		return []entity.Answer{}, nil
+	}
+	return answers, nil
+}
+
+// GetAnswersByQuestionIDs не кэшируется: набор вопросов в каждом вызове свой
+func (s *cachedAnswerService) GetAnswersByQuestionIDs(ctx context.Context, questionIDs []int) (map[int][]entity.Answer, error) {
+	return s.next.GetAnswersByQuestionIDs(ctx, questionIDs)
+}
+
+// GetAnswerStats не кэшируется: счётчики меняются с каждым ответом
+func (s *cachedAnswerService) GetAnswerStats(ctx context.Context, questionIDs []int) (map[int]entity.AnswerStats, error) {
+	return s.next.GetAnswerStats(ctx, questionIDs)
+}
+
+func (s *cachedAnswerService) DeleteAnswer(ctx context.Context, id, expectedVersion int) error {
+	// вопрос нужен для инвалидации списка ответов; запись редкая, лишний запрос допустим
+	answer, err := s.next.GetAnswer(ctx, id)
+	if err != nil {
+		return err
+	}
+	if err := s.next.DeleteAnswer(ctx, id, expectedVersion); err != nil {
+		return err
+	}
+	s.rt.invalidate(ctx, questionAnswersCacheKey(answer.QuestionID))
+	return nil
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type countingQuestionService struct {
+	QuestionService
+	getAllCalls atomic.Int32
+	release     chan struct{}
+}
+
+func (s *countingQuestionService) GetAllQuestions(ctx context.Context) ([]entity.Question, error) {
+	s.getAllCalls.Add(1)
+	<-s.release
+	return []entity.Question{{ID: 1, Text: "What is Go?"}}, nil
+}
+
+func (s *countingQuestionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
+	return &entity.Question{ID: 2, Text: text}, nil
+}
+
+func TestCachedQuestionService_SingleflightAndInvalidation(t *testing.T) {
+	inner := &countingQuestionService{release: make(chan struct{})}
+	instrumented := cache.NewInstrumented(cache.NewLRU(100), "test")
+	questionService, _ := NewCachedServices(inner, nil, instrumented, time.Minute, time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			questions, err := questionService.GetAllQuestions(context.Background())
+			if err != nil || len(questions) != 1 {
+				t.Errorf("expected 1 question, got %d (err: %v)", len(questions), err)
+			}
+		}()
+	}
+
+	// даём горутинам встать в ожидание singleflight, затем отпускаем загрузку
+	time.Sleep(50 * time.Millisecond)
+	close(inner.release)
+	wg.Wait()
+
+	if calls := inner.getAllCalls.Load(); calls != 1 {
+		t.Errorf("expected 1 load for concurrent misses, got %d", calls)
+	}
+
+	if _, err := questionService.GetAllQuestions(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls := inner.getAllCalls.Load(); calls != 1 {
+		t.Errorf("expected cached read, got %d loads", calls)
+	}
+	if stats := instrumented.Stats(); stats.Hits < 1 {
+		t.Errorf("expected at least 1 cache hit, got %d", stats.Hits)
+	}
+
+	if _, err := questionService.CreateQuestion(context.Background(), "How to use GORM?"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := questionService.GetAllQuestions(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if calls := inner.getAllCalls.Load(); calls != 2 {
+		t.Errorf("expected reload after invalidation, got %d loads", calls)
+	}
+}
+
+func TestCachedQuestionService_AuthenticatedReadsBypassCache(t *testing.T) {
+	inner := &countingQuestionService{release: make(chan struct{})}
+	close(inner.release)
+	questionService, _ := NewCachedServices(inner, nil, cache.NewLRU(100), time.Minute, time.Second)
+
+	alice := WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "alice"})
+	for i := 0; i < 2; i++ {
+		if _, err := questionService.GetAllQuestions(alice); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls := inner.getAllCalls.Load(); calls != 2 {
+		t.Errorf("expected every authenticated read to skip the cache, got %d loads", calls)
+	}
+
+	// список автора не должен попасть в кэш и достаться анонимному запросу
+	for i := 0; i < 2; i++ {
+		if _, err := questionService.GetAllQuestions(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if calls := inner.getAllCalls.Load(); calls != 3 {
+		t.Errorf("expected one load for anonymous reads, got %d loads in total", calls)
+	}
+}
+
+func TestReadThrough_InvalidationDuringLoad(t *testing.T) {
+	lru := cache.NewLRU(100)
+	rt := &readThrough{cache: lru, ttl: time.Minute, loadTimeout: time.Second}
+	ctx := context.Background()
+
+	var version atomic.Int32
+	version.Store(1)
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var loads atomic.Int32
+	load := func(ctx context.Context) (any, error) {
+		// первая загрузка читает данные до изменения и ждёт, пока их сбросят
+		if loads.Add(1) == 1 {
+			read := version.Load()
+			close(started)
+			<-release
+			return read, nil
+		}
+		return version.Load(), nil
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var got int32
+		if err := rt.get(ctx, "k", &got, load); err != nil || got != 1 {
+			t.Errorf("expected version 1 for the reader that started first, got %d (err: %v)", got, err)
+		}
+	}()
+	<-started
+
+	version.Store(2)
+	rt.invalidate(ctx, "k")
+
+	// новый промах не ждёт загрузку, начатую до сброса
+	var got int32
+	if err := rt.get(ctx, "k", &got, load); err != nil || got != 2 {
+		t.Errorf("expected fresh version 2 after invalidation, got %d (err: %v)", got, err)
+	}
+	close(release)
+	wg.Wait()
+
+	// устаревшая загрузка не перезаписывает кэш
+	got = 0
+	if err := rt.get(ctx, "k", &got, load); err != nil || got != 2 {
+		t.Errorf("expected cached version 2, got %d (err: %v)", got, err)
+	}
+	if calls := loads.Load(); calls != 2 {
+		t.Errorf("expected 2 loads, got %d", calls)
+	}
+
+	// под нагрузкой читатели и сбросы не гоняются за состоянием readThrough (go test -race)
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				var v int32
+				if err := rt.get(ctx, "k", &v, load); err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				rt.invalidate(ctx, "k")
+			}
+		}()
+	}
+	wg.Wait()
+
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if len(rt.loads) != 0 {
+		t.Errorf("expected no loads left in flight, got %v", rt.loads)
+	}
+}
@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// maxCommentLength размер колонки comments.text VARCHAR(1000)
+const maxCommentLength = 1000
+
+// CommentService комментарии к ответам и вопросам: уточнения, которые не являются ответами.
+// itemType — entity.ModerationTypeAnswer или entity.ModerationTypeQuestion
+type CommentService interface {
+	// CreateComment добавляет комментарий от пользователя. Комментировать скрытые модерацией ответы
+	// и вопросы нельзя: для всех, кроме автора, их нет. parentID не nil — ответ на комментарий верхнего
+	// уровня к той же записи
+	CreateComment(ctx context.Context, userID, itemType string, itemID int, parentID *int, text string) (*entity.Comment, error)
+
+	// GetComments возвращает до limit комментариев к записи с ID больше afterID в порядке ID
+	GetComments(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error)
+
+	// CountByAnswerIDs возвращает число комментариев к каждому ответу; ответов без комментариев нет в map
+	CountByAnswerIDs(ctx context.Context, answerIDs []int) (map[int]int, error)
+
+	// UpdateComment меняет текст комментария; менять может только автор
+	UpdateComment(ctx context.Context, userID string, id int, text string) (*entity.Comment, error)
+
+	// DeleteComment удаляет комментарий вместе с ответами на него; удалить может автор или администратор (admin)
+	DeleteComment(ctx context.Context, userID string, id int, admin bool) error
+}
+
+type commentService struct {
+	commentRepo  repository.CommentRepository
+	questionRepo repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	txManager    repository.TxManager
+	outbox       repository.OutboxRepository
+	audit        repository.AuditRepository
+	moderator    moderation.Moderator
+}
+
+func NewCommentService(
+	commentRepo repository.CommentRepository,
+	questionRepo repository.QuestionRepository,
+	answerRepo repository.AnswerRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+	moderator moderation.Moderator,
+) CommentService {
+	return &commentService{
+		commentRepo:  commentRepo,
+		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		txManager:    txManager,
+		outbox:       outbox,
+		audit:        audit,
+		moderator:    moderator,
+	}
+}
+
+func ValidateComment(userID, text string) error {
+	if strings.TrimSpace(userID) == "" {
+		return entity.ErrInvalidUserID
+	}
+	if userID == entity.ErasedUserID {
+		return entity.ErrReservedUserID
+	}
+	if strings.TrimSpace(text) == "" {
+		return entity.ErrInvalidCommentText
+	}
+	if utf8.RuneCountInString(text) > maxCommentLength {
+		return entity.ErrCommentTooLong
+	}
+	return nil
+}
+
+// checkComment проверяет текст комментария и прогоняет его через цепочку модерации. У комментариев нет
+// очереди модерации, поэтому текст, который ответ или вопрос отправил бы на проверку, отклоняется
+func (s *commentService) checkComment(ctx context.Context, userID, text string) error {
+	if err := ValidateComment(userID, text); err != nil {
+		return err
+	}
+	return moderate(ctx, s.moderator, moderation.Content{Kind: moderation.KindComment, UserID: userID, Text: text})
+}
+
+func (s *commentService) CreateComment(ctx context.Context, userID, itemType string, itemID int, parentID *int, text string) (*entity.Comment, error) {
+	ctx, span := startSpan(ctx, "CommentService.CreateComment")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := s.checkComment(ctx, userID, text); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	comment := &entity.Comment{UserID: userID, Text: text, ParentID: parentID}
+	if itemType == entity.ModerationTypeQuestion {
+		comment.QuestionID = &itemID
+	} else {
+		comment.AnswerID = &itemID
+	}
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := getModerationItem(ctx, s.questionRepo, s.answerRepo, itemType, itemID)
+		if err != nil {
+			return err
+		}
+		if !entity.Published(item.Status) {
+			return notFoundError(itemType)
+		}
+		if parentID != nil {
+			if err := s.checkParent(ctx, comment, *parentID); err != nil {
+				return err
+			}
+		}
+		if err := s.commentRepo.Create(ctx, comment); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionCreate, entity.AuditEntityComment, comment.ID, nil, comment); err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, events.TypeCommentCreated, commentQuestionID(item), comment)
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, spanError(span, customErr)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return comment, nil
+}
+
+func (s *commentService) GetComments(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error) {
+	ctx, span := startSpan(ctx, "CommentService.GetComments")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+	item, err := getModerationItem(ctx, s.questionRepo, s.answerRepo, itemType, itemID)
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, spanError(span, customErr)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	// комментарии к скрытой записи видит только её автор, как и саму запись
+	if !visibleTo(ctx, item.Status, item.AuthorID) {
+		return nil, spanError(span, notFoundError(itemType))
+	}
+
+	comments, err := s.commentRepo.GetPage(ctx, itemType, itemID, afterID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return comments, nil
+}
+
+func (s *commentService) CountByAnswerIDs(ctx context.Context, answerIDs []int) (map[int]int, error) {
+	ctx, span := startSpan(ctx, "CommentService.CountByAnswerIDs")
+	defer span.End()
+
+	counts, err := s.commentRepo.CountByAnswerIDs(ctx, answerIDs)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return counts, nil
+}
+
+func (s *commentService) UpdateComment(ctx context.Context, userID string, id int, text string) (*entity.Comment, error) {
+	ctx, span := startSpan(ctx, "CommentService.UpdateComment")
+	defer span.End()
+
+	if err := s.checkComment(ctx, userID, text); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	var updated *entity.Comment
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		comment, err := s.getComment(ctx, id)
+		if err != nil {
+			return err
+		}
+		if comment.UserID != userID {
+			return entity.ErrForbidden
+		}
+		ok, err := s.commentRepo.Update(ctx, id, comment.Version, text)
+		if err != nil {
+			return err
+		}
+		// комментарий изменили или удалили параллельно
+		if !ok {
+			return entity.ErrPreconditionFailed
+		}
+		after := *comment
+		after.Text = text
+		after.Version++
+		updated = &after
+		return recordAudit(ctx, s.audit, entity.AuditActionUpdate, entity.AuditEntityComment, id, comment, updated)
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, spanError(span, customErr)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return updated, nil
+}
+
+func (s *commentService) DeleteComment(ctx context.Context, userID string, id int, admin bool) error {
+	ctx, span := startSpan(ctx, "CommentService.DeleteComment")
+	defer span.End()
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		comment, err := s.getComment(ctx, id)
+		if err != nil {
+			return err
+		}
+		if comment.UserID != userID && !admin {
+			return entity.ErrForbidden
+		}
+		if err := s.commentRepo.Delete(ctx, id); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityComment, id, comment, nil); err != nil {
+			return err
+		}
+		return s.recordDeleted(ctx, comment)
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return spanError(span, customErr)
+		}
+		return spanError(span, entity.ErrDatabaseQuery)
+	}
+	return nil
+}
+
+// checkParent проверяет, что parentID — комментарий верхнего уровня к той же записи, что и comment
+func (s *commentService) checkParent(ctx context.Context, comment *entity.Comment, parentID int) error {
+	parent, err := s.getComment(ctx, parentID)
+	if err != nil {
+		return err
+	}
+	if parent.ParentID != nil || !sameIntPtr(parent.AnswerID, comment.AnswerID) || !sameIntPtr(parent.QuestionID, comment.QuestionID) {
+		return entity.ErrInvalidCommentParent
+	}
+	return nil
+}
+
+// recordDeleted пишет comment.deleted, если запись комментария опубликована: о комментариях к скрытой
+// записи подписчики не знают
+func (s *commentService) recordDeleted(ctx context.Context, comment *entity.Comment) error {
+	itemType, itemID := entity.ModerationTypeAnswer, comment.AnswerID
+	if comment.QuestionID != nil {
+		itemType, itemID = entity.ModerationTypeQuestion, comment.QuestionID
+	}
+	item, err := getModerationItem(ctx, s.questionRepo, s.answerRepo, itemType, *itemID)
+	if err != nil {
+		return err
+	}
+	if !entity.Published(item.Status) {
+		return nil
+	}
+	return recordEvent(ctx, s.outbox, events.TypeCommentDeleted, commentQuestionID(item), map[string]int{
+		"id":          comment.ID,
+		"question_id": commentQuestionID(item),
+	})
+}
+
+// commentQuestionID вопрос, к которому относится комментарий: сам вопрос или вопрос ответа
+func commentQuestionID(item entity.ModerationItem) int {
+	if item.Type == entity.ModerationTypeQuestion {
+		return item.ID
+	}
+	return item.QuestionID
+}
+
+func sameIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func (s *commentService) getComment(ctx context.Context, id int) (*entity.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrCommentNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
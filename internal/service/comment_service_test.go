@@ -0,0 +1,272 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type memoryCommentRepository struct {
+	repository.CommentRepository
+	comments []entity.Comment
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func (r *memoryCommentRepository) Create(ctx context.Context, comment *entity.Comment) error {
+	comment.ID = len(r.comments) + 1
+	comment.Version = 1
+	r.comments = append(r.comments, *comment)
+	return nil
+}
+
+func (r *memoryCommentRepository) GetByID(ctx context.Context, id int) (*entity.Comment, error) {
+	for _, c := range r.comments {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryCommentRepository) GetPage(ctx context.Context, itemType string, itemID, afterID, limit int) ([]entity.Comment, error) {
+	page := []entity.Comment{}
+	for _, c := range r.comments {
+		target := c.AnswerID
+		if itemType == entity.ModerationTypeQuestion {
+			target = c.QuestionID
+		}
+		if target != nil && *target == itemID && c.ID > afterID && len(page) < limit {
+			page = append(page, c)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryCommentRepository) Update(ctx context.Context, id, version int, text string) (bool, error) {
+	for i, c := range r.comments {
+		if c.ID == id && c.Version == version {
+			r.comments[i].Text = text
+			r.comments[i].Version++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Delete удаляет и ответы на комментарий, как ON DELETE CASCADE
+func (r *memoryCommentRepository) Delete(ctx context.Context, id int) error {
+	kept := r.comments[:0]
+	for _, c := range r.comments {
+		if c.ID != id && (c.ParentID == nil || *c.ParentID != id) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == len(r.comments) {
+		return entity.ErrCommentNotFound
+	}
+	r.comments = kept
+	return nil
+}
+
+func (r *memoryCommentRepository) GetPageByUser(ctx context.Context, userID string, afterID, limit int) ([]entity.Comment, error) {
+	var page []entity.Comment
+	for _, c := range r.comments {
+		if c.UserID == userID && c.ID > afterID && len(page) < limit {
+			page = append(page, c)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryCommentRepository) ReassignUser(ctx context.Context, userID, newUserID string) (int64, error) {
+	var reassigned int64
+	for i := range r.comments {
+		if r.comments[i].UserID == userID {
+			r.comments[i].UserID = newUserID
+			reassigned++
+		}
+	}
+	return reassigned, nil
+}
+
+func (r *memoryCommentRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	kept := r.comments[:0]
+	for _, c := range r.comments {
+		if c.UserID != userID {
+			kept = append(kept, c)
+		}
+	}
+	deleted := int64(len(r.comments) - len(kept))
+	r.comments = kept
+	return deleted, nil
+}
+
+func newMemoryCommentService() (*memoryCommentRepository, *memoryOutboxRepository, *memoryAuditRepository, CommentService) {
+	store := &memoryTransferStore{
+		questions: []entity.Question{{ID: 1, Text: "Вопрос", ModerationStatus: entity.ModerationApproved}},
+		answers: []entity.Answer{
+			{ID: 1, QuestionID: 1, UserID: "bob", Text: "Ответ", ModerationStatus: entity.ModerationApproved},
+			{ID: 2, QuestionID: 1, UserID: "carol", Text: "Ответ на проверке", ModerationStatus: entity.ModerationPending},
+		},
+	}
+	comments := &memoryCommentRepository{}
+	outbox := &memoryOutboxRepository{}
+	audit := &memoryAuditRepository{}
+	svc := NewCommentService(comments, &memoryTransferQuestionRepository{store: store}, &memoryTransferAnswerRepository{store: store},
+		store, outbox, audit, nil)
+	return comments, outbox, audit, svc
+}
+
+func TestCommentService_CreateAndList(t *testing.T) {
+	comments, _, audit, svc := newMemoryCommentService()
+	ctx := context.Background()
+
+	if _, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 1, nil, "  "); !errors.Is(err, entity.ErrInvalidCommentText) {
+		t.Errorf("expected ErrInvalidCommentText, got %v", err)
+	}
+	if _, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 2, nil, "Уточните"); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Errorf("expected pending answer to be closed for comments, got %v", err)
+	}
+	if _, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 9, nil, "Уточните"); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Errorf("expected ErrAnswerNotFound, got %v", err)
+	}
+
+	for _, text := range []string{"Какая версия Go?", "А с дженериками?"} {
+		if _, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 1, nil, text); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if _, err := svc.CreateComment(ctx, "bob", entity.ModerationTypeQuestion, 1, nil, "Уточните вопрос"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments.comments) != 3 || len(audit.events) != 3 || audit.events[0].EntityType != entity.AuditEntityComment {
+		t.Errorf("expected 3 comments with audit events, got %+v and %+v", comments.comments, audit.events)
+	}
+
+	page, err := svc.GetComments(ctx, entity.ModerationTypeAnswer, 1, 0, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Text != "Какая версия Go?" {
+		t.Errorf("expected first comment of answer, got %+v", page)
+	}
+	page, err = svc.GetComments(ctx, entity.ModerationTypeAnswer, 1, page[0].ID, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(page) != 1 || page[0].Text != "А с дженериками?" {
+		t.Errorf("expected second comment of answer, got %+v", page)
+	}
+
+	if _, err := svc.GetComments(ctx, entity.ModerationTypeAnswer, 2, 0, 10); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Errorf("expected comments of pending answer to be hidden, got %v", err)
+	}
+	authorCtx := WithAuditMeta(ctx, entity.AuditMeta{Actor: "carol"})
+	if _, err := svc.GetComments(authorCtx, entity.ModerationTypeAnswer, 2, 0, 10); err != nil {
+		t.Errorf("expected author to see comments of own pending answer, got %v", err)
+	}
+}
+
+func TestCommentService_UpdateAndDelete(t *testing.T) {
+	comments, _, audit, svc := newMemoryCommentService()
+	ctx := context.Background()
+
+	comment, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 1, nil, "Какая версия Go?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := svc.UpdateComment(ctx, "bob", comment.ID, "Чужая правка"); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another user, got %v", err)
+	}
+	updated, err := svc.UpdateComment(ctx, "alice", comment.ID, "Какая версия Go и ОС?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.Text != "Какая версия Go и ОС?" || updated.Version != 2 || comments.comments[0].Version != 2 {
+		t.Errorf("expected comment updated to version 2, got %+v", updated)
+	}
+	if _, err := svc.UpdateComment(ctx, "alice", 99, "Текст"); !errors.Is(err, entity.ErrCommentNotFound) {
+		t.Errorf("expected ErrCommentNotFound, got %v", err)
+	}
+
+	if err := svc.DeleteComment(ctx, "bob", comment.ID, false); !errors.Is(err, entity.ErrForbidden) {
+		t.Errorf("expected ErrForbidden for another user, got %v", err)
+	}
+	if err := svc.DeleteComment(ctx, "admin", comment.ID, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments.comments) != 0 {
+		t.Errorf("expected comment deleted by admin, got %+v", comments.comments)
+	}
+	last := audit.events[len(audit.events)-1]
+	if last.Action != entity.AuditActionDelete || last.Before == nil || last.After != nil {
+		t.Errorf("expected delete audit event with snapshot, got %+v", last)
+	}
+}
+
+func TestCommentService_RepliesAndEvents(t *testing.T) {
+	comments, outbox, _, svc := newMemoryCommentService()
+	ctx := context.Background()
+
+	top, err := svc.CreateComment(ctx, "alice", entity.ModerationTypeAnswer, 1, nil, "Какая версия Go?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	onQuestion, err := svc.CreateComment(ctx, "bob", entity.ModerationTypeQuestion, 1, nil, "Уточните вопрос")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	reply, err := svc.CreateComment(ctx, "bob", entity.ModerationTypeAnswer, 1, &top.ID, "1.24")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if reply.ParentID == nil || *reply.ParentID != top.ID {
+		t.Errorf("expected reply to comment %d, got %+v", top.ID, reply)
+	}
+
+	// отвечать можно только на комментарий верхнего уровня к той же записи
+	tests := []struct {
+		name     string
+		itemType string
+		parentID int
+		want     error
+	}{
+		{"reply to reply", entity.ModerationTypeAnswer, reply.ID, entity.ErrInvalidCommentParent},
+		{"other item", entity.ModerationTypeAnswer, onQuestion.ID, entity.ErrInvalidCommentParent},
+		{"missing parent", entity.ModerationTypeAnswer, 99, entity.ErrCommentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateComment(ctx, "carol", tt.itemType, 1, &tt.parentID, "Ответ"); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if err := svc.DeleteComment(ctx, "alice", top.ID, false); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(comments.comments) != 1 || comments.comments[0].ID != onQuestion.ID {
+		t.Errorf("expected reply to be deleted with its parent, got %+v", comments.comments)
+	}
+
+	wantTypes := []string{events.TypeCommentCreated, events.TypeCommentCreated, events.TypeCommentCreated, events.TypeCommentDeleted}
+	if len(outbox.messages) != len(wantTypes) {
+		t.Fatalf("expected %d events, got %d", len(wantTypes), len(outbox.messages))
+	}
+	for i, message := range outbox.messages {
+		if message.EventType != wantTypes[i] || message.AggregateID != 1 {
+			t.Errorf("expected %s for question 1, got %s for %d", wantTypes[i], message.EventType, message.AggregateID)
+		}
+	}
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+const (
+	// maxFlagCommentLength размер колонки flags.comment VARCHAR(1000)
+	maxFlagCommentLength = 1000
+
+	// flagsUpheldReason причина отклонения по жалобам, если модератор не написал свою
+	flagsUpheldReason = "Отклонено по жалобам пользователей"
+)
+
+// FlagService жалобы пользователей на вопросы и ответы и их разбор модераторами
+type FlagService interface {
+	// Flag записывает жалобу пользователя. На один вопрос или ответ у пользователя может быть
+	// только одна открытая жалоба. Запись, набравшая порог открытых жалоб, скрывается до проверки
+	Flag(ctx context.Context, userID, itemType string, itemID int, reason, comment string) (*entity.Flag, error)
+
+	// ListFlagged возвращает до limit вопросов или ответов с открытыми жалобами и ID больше afterID в порядке ID
+	ListFlagged(ctx context.Context, itemType string, afterID, limit int) ([]entity.FlagSummary, error)
+
+	// Resolve закрывает открытые жалобы на запись: entity.FlagActionDismiss возвращает скрытую по жалобам
+	// запись в публикацию, entity.FlagActionUphold отклоняет её. Решение пишется в журнал аудита
+	Resolve(ctx context.Context, moderatorID, itemType string, itemID int, action, comment string) (*entity.FlagResolution, error)
+}
+
+type flagService struct {
+	flagRepo         repository.FlagRepository
+	questionRepo     repository.QuestionRepository
+	answerRepo       repository.AnswerRepository
+	notificationRepo repository.NotificationRepository
+	txManager        repository.TxManager
+	outbox           repository.OutboxRepository
+	audit            repository.AuditRepository
+	invalidator      CacheInvalidator
+	hideThreshold    int
+	now              func() time.Time
+}
+
+// NewFlagService hideThreshold — число открытых жалоб, после которого запись скрывается; 0 — не скрывать.
+// invalidator nil, если кэш выключен
+func NewFlagService(
+	flagRepo repository.FlagRepository,
+	questionRepo repository.QuestionRepository,
+	answerRepo repository.AnswerRepository,
+	notificationRepo repository.NotificationRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+	invalidator CacheInvalidator,
+	hideThreshold int,
+) FlagService {
+	return &flagService{
+		flagRepo:         flagRepo,
+		questionRepo:     questionRepo,
+		answerRepo:       answerRepo,
+		notificationRepo: notificationRepo,
+		txManager:        txManager,
+		outbox:           outbox,
+		audit:            audit,
+		invalidator:      invalidator,
+		hideThreshold:    hideThreshold,
+		now:              time.Now,
+	}
+}
+
+func ValidateFlag(reason, comment string) error {
+	if !slices.Contains(entity.FlagReasons, reason) {
+		return entity.ErrInvalidFlagReason
+	}
+	if utf8.RuneCountInString(comment) > maxFlagCommentLength {
+		return entity.ErrFlagCommentTooLong
+	}
+	return nil
+}
+
+func ValidateFlagResolution(action, comment string) error {
+	if action != entity.FlagActionDismiss && action != entity.FlagActionUphold {
+		return entity.ErrInvalidFlagAction
+	}
+	if utf8.RuneCountInString(comment) > maxModerationReasonLength {
+		return entity.ErrModerationReasonTooLong
+	}
+	return nil
+}
+
+func (s *flagService) Flag(ctx context.Context, userID, itemType string, itemID int, reason, comment string) (*entity.Flag, error) {
+	ctx, span := startSpan(ctx, "FlagService.Flag")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+	comment = strings.TrimSpace(comment)
+	if err := ValidateFlag(reason, comment); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	flag := &entity.Flag{ItemType: itemType, ItemID: itemID, UserID: userID, Reason: reason, Comment: comment, Status: entity.FlagOpen}
+	var hidden *entity.ModerationItem
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := getModerationItem(ctx, s.questionRepo, s.answerRepo, itemType, itemID)
+		if err != nil {
+			return err
+		}
+		// на скрытое жаловаться нельзя: для всех, кроме автора, его нет
+		if !entity.Published(item.Status) {
+			return notFoundError(itemType)
+		}
+		if err := s.flagRepo.Create(ctx, flag); err != nil {
+			return err
+		}
+		hidden, err = s.hideIfFlagged(ctx, item)
+		return err
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, spanError(span, customErr)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	if hidden != nil {
+		invalidateModerationItem(ctx, s.invalidator, *hidden)
+	}
+	return flag, nil
+}
+
+// hideIfFlagged скрывает запись, набравшую порог открытых жалоб, и возвращает её, если скрыла. Жалобы,
+// вставленные параллельно, не видят друг друга до фиксации, поэтому порог сравнивается через >=:
+// запись скроет следующая жалоба
+func (s *flagService) hideIfFlagged(ctx context.Context, item entity.ModerationItem) (*entity.ModerationItem, error) {
+	if s.hideThreshold <= 0 {
+		return nil, nil
+	}
+	count, err := s.flagRepo.CountOpen(ctx, item.Type, item.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count < int64(s.hideThreshold) {
+		return nil, nil
+	}
+	updated, err := setModerationStatus(ctx, s.questionRepo, s.answerRepo, item.Type, item.ID, item.Status, entity.ModerationPending, entity.FlagsHiddenReason)
+	if err != nil || !updated {
+		return nil, err
+	}
+	after := item
+	after.Status = entity.ModerationPending
+	after.Reason = entity.FlagsHiddenReason
+	if err := recordAudit(ctx, s.audit, entity.AuditActionHide, item.Type, item.ID, item, after); err != nil {
+		return nil, err
+	}
+	if err := s.recordVisibilityEvent(ctx, after); err != nil {
+		return nil, err
+	}
+	return &after, nil
+}
+
+// recordVisibilityEvent сообщает подписчикам SSE, WebSocket и вебхуков, что запись снята с публикации
+// или возвращена в неё. Возвращённая запись приходит как созданная, как после одобрения модератором
+func (s *flagService) recordVisibilityEvent(ctx context.Context, item entity.ModerationItem) error {
+	if !entity.Published(item.Status) {
+		if item.Type == entity.ModerationTypeQuestion {
+			return recordEvent(ctx, s.outbox, events.TypeQuestionHidden, item.ID, map[string]int{"id": item.ID})
+		}
+		return recordEvent(ctx, s.outbox, events.TypeAnswerHidden, item.QuestionID, map[string]int{
+			"id":          item.ID,
+			"question_id": item.QuestionID,
+		})
+	}
+	if item.Type == entity.ModerationTypeQuestion {
+		question, err := s.questionRepo.GetByID(ctx, item.ID)
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, s.outbox, events.TypeQuestionCreated, item.ID, question)
+	}
+	answer, err := s.answerRepo.GetByID(ctx, item.ID)
+	if err != nil {
+		return err
+	}
+	return recordEvent(ctx, s.outbox, events.TypeAnswerCreated, item.QuestionID, answer)
+}
+
+func (s *flagService) ListFlagged(ctx context.Context, itemType string, afterID, limit int) ([]entity.FlagSummary, error) {
+	ctx, span := startSpan(ctx, "FlagService.ListFlagged")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+	ids, err := s.flagRepo.ListOpenItemIDs(ctx, itemType, afterID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	flags, err := s.flagRepo.GetOpenByItems(ctx, itemType, ids)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	items, err := s.getItems(ctx, itemType, ids)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+
+	byItem := make(map[int][]entity.Flag, len(ids))
+	for _, flag := range flags {
+		byItem[flag.ItemID] = append(byItem[flag.ItemID], flag)
+	}
+	summaries := make([]entity.FlagSummary, 0, len(ids))
+	for _, id := range ids {
+		item, ok := items[id]
+		// запись удалена между запросами
+		if !ok || len(byItem[id]) == 0 {
+			continue
+		}
+		summaries = append(summaries, summarizeFlags(item, byItem[id]))
+	}
+	return summaries, nil
+}
+
+func (s *flagService) Resolve(ctx context.Context, moderatorID, itemType string, itemID int, action, comment string) (*entity.FlagResolution, error) {
+	ctx, span := startSpan(ctx, "FlagService.Resolve")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+	comment = strings.TrimSpace(comment)
+	if err := ValidateFlagResolution(action, comment); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	result := &entity.FlagResolution{ItemType: itemType, ItemID: itemID, Action: action, Comment: comment}
+	var changed *entity.ModerationItem
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := getModerationItem(ctx, s.questionRepo, s.answerRepo, itemType, itemID)
+		if err != nil {
+			return err
+		}
+		flags, err := s.flagRepo.GetOpenByItems(ctx, itemType, []int{itemID})
+		if err != nil {
+			return err
+		}
+		if len(flags) == 0 {
+			return entity.ErrNoOpenFlags
+		}
+
+		status := entity.FlagDismissed
+		if action == entity.FlagActionUphold {
+			status = entity.FlagUpheld
+		}
+		resolved, err := s.flagRepo.Resolve(ctx, itemType, itemID, status, moderatorID, s.now())
+		if err != nil {
+			return err
+		}
+		result.Resolved = resolved
+
+		after := item
+		switch {
+		case action == entity.FlagActionDismiss && item.Status == entity.ModerationPending && item.Reason == entity.FlagsHiddenReason:
+			// запись скрыта жалобами, а не цепочкой модерации: жалобы отклонены, публикуем её снова
+			after.Status, after.Reason = entity.ModerationApproved, ""
+		case action == entity.FlagActionUphold && item.Status != entity.ModerationRejected:
+			after.Status, after.Reason = entity.ModerationRejected, comment
+			if after.Reason == "" {
+				after.Reason = flagsUpheldReason
+			}
+		}
+		result.ModerationStatus = after.Status
+		if after.Status != item.Status {
+			updated, err := setModerationStatus(ctx, s.questionRepo, s.answerRepo, itemType, itemID, item.Status, after.Status, after.Reason)
+			if err != nil {
+				return err
+			}
+			if !updated {
+				return entity.ErrAlreadyModerated
+			}
+			changed = &after
+			// скрытая жалобами запись уже снята с публикации, о ней подписчикам сообщили при скрытии
+			if entity.Published(item.Status) != entity.Published(after.Status) {
+				if err := s.recordVisibilityEvent(ctx, after); err != nil {
+					return err
+				}
+			}
+			if after.Status == entity.ModerationRejected {
+				if err := notifyModerationDecision(ctx, s.notificationRepo, after); err != nil {
+					return err
+				}
+			}
+		}
+		return recordAudit(ctx, s.audit, entity.AuditActionResolveFlags, itemType, itemID, summarizeFlags(item, flags), result)
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, spanError(span, customErr)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	if changed != nil {
+		invalidateModerationItem(ctx, s.invalidator, *changed)
+	}
+	return result, nil
+}
+
+func (s *flagService) getItems(ctx context.Context, itemType string, ids []int) (map[int]entity.ModerationItem, error) {
+	items := make(map[int]entity.ModerationItem, len(ids))
+	if itemType == entity.ModerationTypeQuestion {
+		questions, err := s.questionRepo.GetByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		for _, question := range questions {
+			items[question.ID] = questionModerationItem(question)
+		}
+		return items, nil
+	}
+	answers, err := s.answerRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, answer := range answers {
+		items[answer.ID] = answerModerationItem(answer)
+	}
+	return items, nil
+}
+
+func summarizeFlags(item entity.ModerationItem, flags []entity.Flag) entity.FlagSummary {
+	summary := entity.FlagSummary{Item: item, Count: len(flags), Reasons: map[string]int{}, Flags: flags}
+	for _, flag := range flags {
+		summary.Reasons[flag.Reason]++
+	}
+	return summary
+}
+
+func notFoundError(itemType string) error {
+	if itemType == entity.ModerationTypeQuestion {
+		return entity.ErrQuestionNotFound
+	}
+	return entity.ErrAnswerNotFound
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type memoryFlagRepository struct {
+	repository.FlagRepository
+	flags []entity.Flag
+}
+
+func (r *memoryFlagRepository) Create(ctx context.Context, flag *entity.Flag) error {
+	for _, f := range r.flags {
+		if f.ItemType == flag.ItemType && f.ItemID == flag.ItemID && f.UserID == flag.UserID && f.Status == entity.FlagOpen {
+			return entity.ErrFlagAlreadyExists
+		}
+	}
+	flag.ID = int64(len(r.flags) + 1)
+	r.flags = append(r.flags, *flag)
+	return nil
+}
+
+func (r *memoryFlagRepository) CountOpen(ctx context.Context, itemType string, itemID int) (int64, error) {
+	flags, _ := r.GetOpenByItems(ctx, itemType, []int{itemID})
+	return int64(len(flags)), nil
+}
+
+func (r *memoryFlagRepository) ListOpenItemIDs(ctx context.Context, itemType string, afterID, limit int) ([]int, error) {
+	ids := []int{}
+	for _, f := range r.flags {
+		if f.ItemType == itemType && f.Status == entity.FlagOpen && f.ItemID > afterID && !slices.Contains(ids, f.ItemID) {
+			ids = append(ids, f.ItemID)
+		}
+	}
+	slices.Sort(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+	return ids, nil
+}
+
+func (r *memoryFlagRepository) GetOpenByItems(ctx context.Context, itemType string, itemIDs []int) ([]entity.Flag, error) {
+	var flags []entity.Flag
+	for _, f := range r.flags {
+		if f.ItemType == itemType && f.Status == entity.FlagOpen && slices.Contains(itemIDs, f.ItemID) {
+			flags = append(flags, f)
+		}
+	}
+	return flags, nil
+}
+
+func (r *memoryFlagRepository) Resolve(ctx context.Context, itemType string, itemID int, status, resolvedBy string, resolvedAt time.Time) (int64, error) {
+	var resolved int64
+	for i, f := range r.flags {
+		if f.ItemType == itemType && f.ItemID == itemID && f.Status == entity.FlagOpen {
+			r.flags[i].Status = status
+			r.flags[i].ResolvedBy = resolvedBy
+			r.flags[i].ResolvedAt = &resolvedAt
+			resolved++
+		}
+	}
+	return resolved, nil
+}
+
+func (r *memoryFlagRepository) GetPageByUser(ctx context.Context, userID string, afterID int64, limit int) ([]entity.Flag, error) {
+	var page []entity.Flag
+	for _, f := range r.flags {
+		if f.UserID == userID && f.ID > afterID && len(page) < limit {
+			page = append(page, f)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryFlagRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	kept := r.flags[:0]
+	for _, f := range r.flags {
+		if f.UserID != userID {
+			kept = append(kept, f)
+		}
+	}
+	deleted := int64(len(r.flags) - len(kept))
+	r.flags = kept
+	return deleted, nil
+}
+
+func (r *memoryTransferAnswerRepository) GetByIDs(ctx context.Context, ids []int) ([]entity.Answer, error) {
+	var result []entity.Answer
+	for _, a := range r.store.answers {
+		if slices.Contains(ids, a.ID) {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+// recordingInvalidator запоминает вопросы, кэш которых сброшен
+type recordingInvalidator struct {
+	questions []int
+	answers   []int
+}
+
+func (i *recordingInvalidator) InvalidateQuestion(ctx context.Context, questionID int) {
+	i.questions = append(i.questions, questionID)
+}
+
+func (i *recordingInvalidator) InvalidateAnswers(ctx context.Context, questionID int) {
+	i.answers = append(i.answers, questionID)
+}
+
+// flagFixture хранилища сервиса жалоб, которые проверяют тесты
+type flagFixture struct {
+	store         *memoryTransferStore
+	flags         *memoryFlagRepository
+	notifications *memoryNotificationRepository
+	audit         *memoryAuditRepository
+	outbox        *memoryOutboxRepository
+	invalidator   *recordingInvalidator
+}
+
+func newMemoryFlagService(hideThreshold int) (*flagFixture, FlagService) {
+	store := &memoryTransferStore{
+		questions: []entity.Question{{ID: 1, Text: "Вопрос", ModerationStatus: entity.ModerationApproved}},
+		answers: []entity.Answer{
+			{ID: 1, QuestionID: 1, UserID: "bob", Text: "КУПИТЕ КУРСЫ", ModerationStatus: entity.ModerationApproved},
+			{ID: 2, QuestionID: 1, UserID: "carol", Text: "Ответ", ModerationStatus: entity.ModerationPending},
+		},
+	}
+	f := &flagFixture{
+		store:         store,
+		flags:         &memoryFlagRepository{},
+		notifications: &memoryNotificationRepository{},
+		audit:         &memoryAuditRepository{},
+		outbox:        &memoryOutboxRepository{},
+		invalidator:   &recordingInvalidator{},
+	}
+	svc := NewFlagService(f.flags, &memoryTransferQuestionRepository{store: store}, &memoryTransferAnswerRepository{store: store},
+		f.notifications, store, f.outbox, f.audit, f.invalidator, hideThreshold)
+	return f, svc
+}
+
+func TestFlagService_FlagHidesAtThreshold(t *testing.T) {
+	f, svc := newMemoryFlagService(2)
+	store, flags, audit := f.store, f.flags, f.audit
+	ctx := context.Background()
+
+	if _, err := svc.Flag(ctx, "alice", entity.ModerationTypeAnswer, 1, "rude", ""); !errors.Is(err, entity.ErrInvalidFlagReason) {
+		t.Errorf("expected ErrInvalidFlagReason, got %v", err)
+	}
+	if _, err := svc.Flag(ctx, "alice", entity.ModerationTypeAnswer, 2, entity.FlagReasonSpam, ""); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Errorf("expected pending answer to be unflaggable, got %v", err)
+	}
+
+	flag, err := svc.Flag(ctx, "alice", entity.ModerationTypeAnswer, 1, entity.FlagReasonSpam, " реклама ")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if flag.Status != entity.FlagOpen || flag.Comment != "реклама" || store.answers[0].ModerationStatus != entity.ModerationApproved {
+		t.Errorf("expected open flag and answer still published, got %+v and %+v", flag, store.answers[0])
+	}
+	if _, err := svc.Flag(ctx, "alice", entity.ModerationTypeAnswer, 1, entity.FlagReasonOther, ""); !errors.Is(err, entity.ErrFlagAlreadyExists) {
+		t.Errorf("expected ErrFlagAlreadyExists, got %v", err)
+	}
+
+	if _, err := svc.Flag(ctx, "dave", entity.ModerationTypeAnswer, 1, entity.FlagReasonOffensive, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if store.answers[0].ModerationStatus != entity.ModerationPending || store.answers[0].ModerationReason != entity.FlagsHiddenReason {
+		t.Errorf("expected answer hidden after second flag, got %+v", store.answers[0])
+	}
+	if len(audit.events) != 1 || audit.events[0].Action != entity.AuditActionHide {
+		t.Errorf("expected hide audit event, got %+v", audit.events)
+	}
+	if len(f.outbox.messages) != 1 || f.outbox.messages[0].EventType != events.TypeAnswerHidden || f.outbox.messages[0].AggregateID != 1 {
+		t.Errorf("expected answer.hidden event for question 1, got %+v", f.outbox.messages)
+	}
+	if !slices.Equal(f.invalidator.answers, []int{1}) {
+		t.Errorf("expected answers of question 1 invalidated once, got %v", f.invalidator.answers)
+	}
+
+	summaries, err := svc.ListFlagged(ctx, entity.ModerationTypeAnswer, 0, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Count != 2 || summaries[0].Reasons[entity.FlagReasonSpam] != 1 ||
+		summaries[0].Item.AuthorID != "bob" || len(flags.flags) != 2 {
+		t.Errorf("expected one answer with 2 flags, got %+v", summaries)
+	}
+}
+
+func TestFlagService_Resolve(t *testing.T) {
+	tests := []struct {
+		action            string
+		wantStatus        string
+		wantFlagStatus    string
+		wantNotifications int
+		// hidden и затем created после возврата в публикацию; отклонение скрытой записи событий не добавляет
+		wantEvents []string
+	}{
+		{entity.FlagActionDismiss, entity.ModerationApproved, entity.FlagDismissed, 0, []string{events.TypeAnswerHidden, events.TypeAnswerCreated}},
+		{entity.FlagActionUphold, entity.ModerationRejected, entity.FlagUpheld, 1, []string{events.TypeAnswerHidden}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.action, func(t *testing.T) {
+			f, svc := newMemoryFlagService(1)
+			store, flags, notifications, audit := f.store, f.flags, f.notifications, f.audit
+			ctx := context.Background()
+
+			if _, err := svc.Resolve(ctx, "admin", entity.ModerationTypeAnswer, 1, tt.action, ""); !errors.Is(err, entity.ErrNoOpenFlags) {
+				t.Errorf("expected ErrNoOpenFlags, got %v", err)
+			}
+			if _, err := svc.Flag(ctx, "alice", entity.ModerationTypeAnswer, 1, entity.FlagReasonSpam, ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := svc.Resolve(ctx, "admin", entity.ModerationTypeAnswer, 1, tt.action, "")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Resolved != 1 || result.ModerationStatus != tt.wantStatus || store.answers[0].ModerationStatus != tt.wantStatus {
+				t.Errorf("expected answer %s after %s, got %+v and %+v", tt.wantStatus, tt.action, result, store.answers[0])
+			}
+			if flags.flags[0].Status != tt.wantFlagStatus || flags.flags[0].ResolvedBy != "admin" {
+				t.Errorf("expected flag %s by admin, got %+v", tt.wantFlagStatus, flags.flags[0])
+			}
+			if len(notifications.notifications) != tt.wantNotifications {
+				t.Errorf("expected %d notifications, got %+v", tt.wantNotifications, notifications.notifications)
+			}
+			last := audit.events[len(audit.events)-1]
+			if last.Action != entity.AuditActionResolveFlags || last.Before == nil || last.After == nil {
+				t.Errorf("expected resolve_flags audit event with snapshots, got %+v", last)
+			}
+			var eventTypes []string
+			for _, m := range f.outbox.messages {
+				eventTypes = append(eventTypes, m.EventType)
+			}
+			if !slices.Equal(eventTypes, tt.wantEvents) {
+				t.Errorf("expected events %v, got %v", tt.wantEvents, eventTypes)
+			}
+			// кэш сброшен при скрытии и при решении
+			if !slices.Equal(f.invalidator.answers, []int{1, 1}) {
+				t.Errorf("expected answers of question 1 invalidated twice, got %v", f.invalidator.answers)
+			}
+		})
+	}
+}
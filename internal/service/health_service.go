@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type HealthService interface {
+	Liveness(ctx context.Context) entity.HealthReport
+
+	Readiness(ctx context.Context) entity.HealthReport
+
+	// StartDraining переводит readiness в down перед остановкой сервера
+	StartDraining()
+}
+
+type healthService struct {
+	repo         repository.HealthRepository
+	checkTimeout time.Duration
+	draining     atomic.Bool
+}
+
+func NewHealthService(repo repository.HealthRepository, checkTimeout time.Duration) HealthService {
+	return &healthService{
+		repo:         repo,
+		checkTimeout: checkTimeout,
+	}
+}
+
+func (s *healthService) Liveness(ctx context.Context) entity.HealthReport {
+	return buildReport(map[string]entity.ComponentHealth{
+		"process": {Status: entity.HealthStatusUp},
+	})
+}
+
+func (s *healthService) Readiness(ctx context.Context) entity.HealthReport {
+	ctx, cancel := context.WithTimeout(ctx, s.checkTimeout)
+	defer cancel()
+
+	components := map[string]entity.ComponentHealth{
+		"server":     s.checkServer(),
+		"database":   componentFromError("database", s.repo.Ping(ctx)),
+		"migrations": componentFromError("migrations", s.checkMigrations(ctx)),
+	}
+	return buildReport(components)
+}
+
+func (s *healthService) StartDraining() {
+	s.draining.Store(true)
+}
+
+func (s *healthService) checkServer() entity.ComponentHealth {
+	if s.draining.Load() {
+		return entity.ComponentHealth{Status: entity.HealthStatusDown, Error: "сервер останавливается"}
+	}
+	return entity.ComponentHealth{Status: entity.HealthStatusUp}
+}
+
+func (s *healthService) checkMigrations(ctx context.Context) error {
+	latest, err := s.repo.LatestMigrationVersion()
+	if err != nil {
+		return fmt.Errorf("не удалось прочитать миграции: %w", err)
+	}
+	applied, err := s.repo.AppliedMigrationVersion(ctx)
+	if err != nil {
+		return fmt.Errorf("не удалось получить версию миграций: %w", err)
+	}
+	if applied < latest {
+		return fmt.Errorf("миграции не применены: текущая версия %d, ожидается %d", applied, latest)
+	}
+	return nil
+}
+
+// componentFromError пишет причину сбоя только в лог: /readyz публичный, и текст ошибки
+// с адресами и версиями инфраструктуры наружу не отдаётся
+func componentFromError(name string, err error) entity.ComponentHealth {
+	if err != nil {
+		log.Printf("Readiness: компонент %s недоступен: %v", name, err)
+		return entity.ComponentHealth{Status: entity.HealthStatusDown}
+	}
+	return entity.ComponentHealth{Status: entity.HealthStatusUp}
+}
+
+func buildReport(components map[string]entity.ComponentHealth) entity.HealthReport {
+	status := entity.HealthStatusUp
+	for _, c := range components {
+		if c.Status != entity.HealthStatusUp {
+			status = entity.HealthStatusDown
+			break
+		}
+	}
+	return entity.HealthReport{Status: status, Components: components}
+}
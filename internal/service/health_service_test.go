@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type failingHealthRepository struct{}
+
+func (failingHealthRepository) Ping(ctx context.Context) error {
+	return errors.New("dial tcp 10.0.0.5:5432: connection refused")
+}
+
+func (failingHealthRepository) AppliedMigrationVersion(ctx context.Context) (int64, error) {
+	return 0, errors.New("pq: relation \"goose_db_version\" does not exist")
+}
+
+func (failingHealthRepository) LatestMigrationVersion() (int64, error) {
+	return 20251223100000, nil
+}
+
+func TestHealthService_ReadinessHidesErrors(t *testing.T) {
+	report := NewHealthService(failingHealthRepository{}, time.Second).Readiness(context.Background())
+
+	if report.IsUp() {
+		t.Fatalf("expected readiness down, got %+v", report)
+	}
+	for _, name := range []string{"database", "migrations"} {
+		component := report.Components[name]
+		if component.Status != entity.HealthStatusDown || component.Error != "" {
+			t.Errorf("expected %s down without error text, got %+v", name, component)
+		}
+	}
+}
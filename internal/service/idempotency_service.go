@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type IdempotencyService interface {
+	// Begin резервирует ключ за запросом. Если запрос с тем же ключом и телом уже
+	// выполнен, возвращает сохранённую запись для повтора ответа
+	Begin(ctx context.Context, scope, key, fingerprint string) (*entity.IdempotencyRecord, error)
+
+	// Complete сохраняет ответ для повторов: статус, заголовки из headers и тело
+	Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte) error
+
+	// Abort освобождает ключ, чтобы клиент мог повторить неудавшийся запрос
+	Abort(ctx context.Context, scope, key string) error
+
+	PurgeExpired(ctx context.Context) (int64, error)
+}
+
+type idempotencyService struct {
+	repo  repository.IdempotencyRepository
+	ttl   time.Duration
+	lease time.Duration
+	now   func() time.Time
+}
+
+// NewIdempotencyService ttl — сколько хранится выполненный запрос, lease — сколько ключ занят незавершённым
+// запросом (порядка таймаута запроса): после падения реплики повтор с тем же ключом перехватит резерв
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl, lease time.Duration) IdempotencyService {
+	return &idempotencyService{
+		repo:  repo,
+		ttl:   ttl,
+		lease: lease,
+		now:   time.Now,
+	}
+}
+
+func (s *idempotencyService) Begin(ctx context.Context, scope, key, fingerprint string) (*entity.IdempotencyRecord, error) {
+	if key == "" || len(key) > 255 {
+		return nil, entity.ErrInvalidIdempotencyKey
+	}
+
+	// вторая попытка нужна, если найденная запись оказалась просроченной (в том числе резерв
+	// с истёкшей арендой) и была удалена
+	for attempt := 0; attempt < 2; attempt++ {
+		now := s.now()
+		reserved, err := s.repo.Reserve(ctx, &entity.IdempotencyRecord{
+			Scope:       scope,
+			Key:         key,
+			Fingerprint: fingerprint,
+			Status:      entity.IdempotencyStatusInProgress,
+			ExpiresAt:   now.Add(s.lease),
+		})
+		if err != nil {
+			return nil, entity.ErrDatabaseQuery
+		}
+		if reserved {
+			return nil, nil
+		}
+
+		existing, err := s.repo.Get(ctx, scope, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, entity.ErrDatabaseQuery
+		}
+
+		if !existing.ExpiresAt.After(now) {
+			if err := s.repo.DeleteIfExpired(ctx, scope, key, now); err != nil {
+				return nil, entity.ErrDatabaseQuery
+			}
+			continue
+		}
+
+		if existing.Fingerprint != fingerprint {
+			return nil, entity.ErrIdempotencyKeyMismatch
+		}
+		if existing.Status != entity.IdempotencyStatusCompleted {
+			return nil, entity.ErrIdempotencyRequestInProgress
+		}
+		return existing, nil
+	}
+
+	return nil, entity.ErrIdempotencyRequestInProgress
+}
+
+func (s *idempotencyService) Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte) error {
+	if err := s.repo.Complete(ctx, scope, key, status, headers, body, s.now().Add(s.ttl)); err != nil {
+		return entity.ErrDatabaseQuery
+	}
+	return nil
+}
+
+func (s *idempotencyService) Abort(ctx context.Context, scope, key string) error {
+	if err := s.repo.Delete(ctx, scope, key); err != nil {
+		return entity.ErrDatabaseQuery
+	}
+	return nil
+}
+
+func (s *idempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	deleted, err := s.repo.DeleteExpired(ctx, s.now())
+	if err != nil {
+		return 0, entity.ErrDatabaseQuery
+	}
+	return deleted, nil
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+// memoryIdempotencyRepository хранит записи ключей в памяти по scope и ключу
+type memoryIdempotencyRepository struct {
+	records map[string]entity.IdempotencyRecord
+}
+
+func (r *memoryIdempotencyRepository) Reserve(ctx context.Context, record *entity.IdempotencyRecord) (bool, error) {
+	if _, ok := r.records[record.Scope+"/"+record.Key]; ok {
+		return false, nil
+	}
+	r.records[record.Scope+"/"+record.Key] = *record
+	return true, nil
+}
+
+func (r *memoryIdempotencyRepository) Get(ctx context.Context, scope, key string) (*entity.IdempotencyRecord, error) {
+	record, ok := r.records[scope+"/"+key]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &record, nil
+}
+
+func (r *memoryIdempotencyRepository) Complete(ctx context.Context, scope, key string, status int, headers map[string]string, body []byte, expiresAt time.Time) error {
+	record := r.records[scope+"/"+key]
+	record.Status = entity.IdempotencyStatusCompleted
+	record.ResponseStatus = status
+	record.ResponseHeaders = headers
+	record.ResponseBody = body
+	record.ExpiresAt = expiresAt
+	r.records[scope+"/"+key] = record
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) Delete(ctx context.Context, scope, key string) error {
+	delete(r.records, scope+"/"+key)
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteIfExpired(ctx context.Context, scope, key string, now time.Time) error {
+	if record, ok := r.records[scope+"/"+key]; ok && !record.ExpiresAt.After(now) {
+		delete(r.records, scope+"/"+key)
+	}
+	return nil
+}
+
+func (r *memoryIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	var deleted int64
+	for id, record := range r.records {
+		if !record.ExpiresAt.After(now) {
+			delete(r.records, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func TestIdempotencyService_TakesOverExpiredLease(t *testing.T) {
+	repo := &memoryIdempotencyRepository{records: map[string]entity.IdempotencyRecord{}}
+	svc := NewIdempotencyService(repo, 24*time.Hour, 30*time.Second).(*idempotencyService)
+	now := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if record, err := svc.Begin(ctx, "alice", "k1", "f1"); err != nil || record != nil {
+		t.Fatalf("expected key to be reserved, got %+v, %v", record, err)
+	}
+	if _, err := svc.Begin(ctx, "alice", "k1", "f1"); !errors.Is(err, entity.ErrIdempotencyRequestInProgress) {
+		t.Errorf("expected in-progress error within the lease, got %v", err)
+	}
+
+	// реплика упала, не освободив ключ: после аренды повтор занимает его заново, а не ждёт суточного TTL
+	now = now.Add(31 * time.Second)
+	if record, err := svc.Begin(ctx, "alice", "k1", "f1"); err != nil || record != nil {
+		t.Fatalf("expected expired lease to be taken over, got %+v, %v", record, err)
+	}
+
+	// выполненный запрос хранится весь TTL
+	if err := svc.Complete(ctx, "alice", "k1", 201, map[string]string{"ETag": `"a1-v1"`}, []byte(`{}`)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	now = now.Add(time.Hour)
+	record, err := svc.Begin(ctx, "alice", "k1", "f1")
+	if err != nil || record == nil || record.ResponseStatus != 201 {
+		t.Errorf("expected completed response to be replayed, got %+v, %v", record, err)
+	}
+}
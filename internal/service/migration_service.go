@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type MigrationService interface {
+	Status(ctx context.Context) ([]entity.Migration, error)
+
+	// Up применяет все неприменённые миграции по возрастанию версии и возвращает применённые.
+	// Миграции, которые успел применить другой процесс, пропускаются. dryRun только возвращает список
+	Up(ctx context.Context, dryRun bool) ([]entity.Migration, error)
+
+	// Down откатывает steps последних применённых миграций, начиная с новейшей
+	Down(ctx context.Context, steps int, dryRun bool) ([]entity.Migration, error)
+}
+
+type migrationService struct {
+	repo repository.MigrationRepository
+}
+
+func NewMigrationService(repo repository.MigrationRepository) MigrationService {
+	return &migrationService{repo: repo}
+}
+
+func (s *migrationService) Status(ctx context.Context) ([]entity.Migration, error) {
+	ctx, span := startSpan(ctx, "MigrationService.Status")
+	defer span.End()
+
+	migrations, err := s.repo.Status(ctx)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return migrations, nil
+}
+
+func (s *migrationService) Up(ctx context.Context, dryRun bool) ([]entity.Migration, error) {
+	ctx, span := startSpan(ctx, "MigrationService.Up")
+	defer span.End()
+
+	migrations, err := s.repo.Status(ctx)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	pending := make([]entity.Migration, 0)
+	for _, migration := range migrations {
+		if !migration.Applied {
+			pending = append(pending, migration)
+		}
+	}
+	if dryRun {
+		return pending, nil
+	}
+
+	applied := make([]entity.Migration, 0, len(pending))
+	for _, migration := range pending {
+		ran, err := s.repo.Up(ctx, migration.Version)
+		if err != nil {
+			return applied, spanError(span, err)
+		}
+		if ran {
+			applied = append(applied, migration)
+		}
+	}
+	return applied, nil
+}
+
+func (s *migrationService) Down(ctx context.Context, steps int, dryRun bool) ([]entity.Migration, error) {
+	ctx, span := startSpan(ctx, "MigrationService.Down")
+	defer span.End()
+
+	migrations, err := s.repo.Status(ctx)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+
+	rollback := make([]entity.Migration, 0, steps)
+	for i := len(migrations) - 1; i >= 0 && len(rollback) < steps; i-- {
+		if migrations[i].Applied {
+			rollback = append(rollback, migrations[i])
+		}
+	}
+	if dryRun {
+		return rollback, nil
+	}
+
+	rolledBack := make([]entity.Migration, 0, len(rollback))
+	for _, migration := range rollback {
+		ran, err := s.repo.Down(ctx, migration.Version)
+		if err != nil {
+			return rolledBack, spanError(span, err)
+		}
+		if ran {
+			rolledBack = append(rolledBack, migration)
+		}
+	}
+	return rolledBack, nil
+}
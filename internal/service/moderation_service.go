@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// maxModerationReasonLength размер колонки moderation_reason VARCHAR(1000)
+const maxModerationReasonLength = 1000
+
+// ModerationService очередь ручной проверки: вопросы и ответы, которые цепочка модерации отложила
+type ModerationService interface {
+	// ListPending возвращает до limit ожидающих проверки вопросов или ответов с ID больше afterID в порядке ID
+	ListPending(ctx context.Context, itemType string, afterID, limit int) ([]entity.ModerationItem, error)
+
+	// Approve публикует вопрос или ответ: подписчики получают событие о создании, автор — уведомление.
+	// Причина необязательна. entity.ErrAlreadyModerated, если решение уже принято
+	Approve(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error)
+
+	// Reject отклоняет вопрос или ответ: он остаётся скрытым, автор получает уведомление с причиной
+	Reject(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error)
+}
+
+type moderationService struct {
+	questionRepo     repository.QuestionRepository
+	answerRepo       repository.AnswerRepository
+	notificationRepo repository.NotificationRepository
+	txManager        repository.TxManager
+	outbox           repository.OutboxRepository
+	audit            repository.AuditRepository
+	invalidator      CacheInvalidator
+}
+
+// NewModerationService invalidator nil, если кэш выключен
+func NewModerationService(
+	questionRepo repository.QuestionRepository,
+	answerRepo repository.AnswerRepository,
+	notificationRepo repository.NotificationRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+	invalidator CacheInvalidator,
+) ModerationService {
+	return &moderationService{
+		questionRepo:     questionRepo,
+		answerRepo:       answerRepo,
+		notificationRepo: notificationRepo,
+		txManager:        txManager,
+		outbox:           outbox,
+		audit:            audit,
+		invalidator:      invalidator,
+	}
+}
+
+func ValidateModerationType(itemType string) error {
+	if itemType != entity.ModerationTypeQuestion && itemType != entity.ModerationTypeAnswer {
+		return entity.ErrInvalidModerationType
+	}
+	return nil
+}
+
+// ValidateModerationReason причина обязательна при отклонении: автор должен понять, что исправить
+func ValidateModerationReason(status, reason string) error {
+	if status == entity.ModerationRejected && strings.TrimSpace(reason) == "" {
+		return entity.ErrEmptyModerationReason
+	}
+	if utf8.RuneCountInString(reason) > maxModerationReasonLength {
+		return entity.ErrModerationReasonTooLong
+	}
+	return nil
+}
+
+func (s *moderationService) ListPending(ctx context.Context, itemType string, afterID, limit int) ([]entity.ModerationItem, error) {
+	ctx, span := startSpan(ctx, "ModerationService.ListPending")
+	defer span.End()
+
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	items := []entity.ModerationItem{}
+	if itemType == entity.ModerationTypeQuestion {
+		questions, err := s.questionRepo.GetPageByModerationStatus(ctx, entity.ModerationPending, afterID, limit)
+		if err != nil {
+			return nil, spanError(span, entity.ErrDatabaseQuery)
+		}
+		for _, question := range questions {
+			items = append(items, questionModerationItem(question))
+		}
+		return items, nil
+	}
+
+	answers, err := s.answerRepo.GetPageByModerationStatus(ctx, entity.ModerationPending, afterID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	for _, answer := range answers {
+		items = append(items, answerModerationItem(answer))
+	}
+	return items, nil
+}
+
+func (s *moderationService) Approve(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error) {
+	ctx, span := startSpan(ctx, "ModerationService.Approve")
+	defer span.End()
+
+	item, err := s.decide(ctx, itemType, id, entity.ModerationApproved, reason)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return item, nil
+}
+
+func (s *moderationService) Reject(ctx context.Context, itemType string, id int, reason string) (*entity.ModerationItem, error) {
+	ctx, span := startSpan(ctx, "ModerationService.Reject")
+	defer span.End()
+
+	item, err := s.decide(ctx, itemType, id, entity.ModerationRejected, reason)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return item, nil
+}
+
+// decide переводит ожидающий проверки вопрос или ответ в status. Статус меняется условным UPDATE,
+// поэтому из двух модераторов, одновременно принявших решение, одному вернётся ErrAlreadyModerated
+func (s *moderationService) decide(ctx context.Context, itemType string, id int, status, reason string) (*entity.ModerationItem, error) {
+	if err := ValidateModerationType(itemType); err != nil {
+		return nil, err
+	}
+	if err := ValidateModerationReason(status, reason); err != nil {
+		return nil, err
+	}
+
+	var item entity.ModerationItem
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if itemType == entity.ModerationTypeQuestion {
+			item, err = s.decideQuestion(ctx, id, status, reason)
+		} else {
+			item, err = s.decideAnswer(ctx, id, status, reason)
+		}
+		if err != nil {
+			return err
+		}
+
+		return notifyModerationDecision(ctx, s.notificationRepo, item)
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return nil, customErr
+		}
+		return nil, entity.ErrDatabaseQuery
+	}
+	invalidateModerationItem(ctx, s.invalidator, item)
+	return &item, nil
+}
+
+func (s *moderationService) decideQuestion(ctx context.Context, id int, status, reason string) (entity.ModerationItem, error) {
+	question, err := s.questionRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ModerationItem{}, entity.ErrQuestionNotFound
+		}
+		return entity.ModerationItem{}, err
+	}
+	if question.ModerationStatus != entity.ModerationPending {
+		return entity.ModerationItem{}, entity.ErrAlreadyModerated
+	}
+	updated, err := s.questionRepo.SetModerationStatus(ctx, id, entity.ModerationPending, status, reason)
+	if err != nil {
+		return entity.ModerationItem{}, err
+	}
+	if !updated {
+		return entity.ModerationItem{}, entity.ErrAlreadyModerated
+	}
+
+	after := *question
+	after.ModerationStatus = status
+	after.ModerationReason = reason
+	after.Version++
+	if err := recordAudit(ctx, s.audit, moderationAuditAction(status), entity.AuditEntityQuestion, id, question, &after); err != nil {
+		return entity.ModerationItem{}, err
+	}
+	if status == entity.ModerationApproved {
+		if err := recordEvent(ctx, s.outbox, events.TypeQuestionCreated, id, &after); err != nil {
+			return entity.ModerationItem{}, err
+		}
+	}
+	return questionModerationItem(after), nil
+}
+
+func (s *moderationService) decideAnswer(ctx context.Context, id int, status, reason string) (entity.ModerationItem, error) {
+	answer, err := s.answerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ModerationItem{}, entity.ErrAnswerNotFound
+		}
+		return entity.ModerationItem{}, err
+	}
+	if answer.ModerationStatus != entity.ModerationPending {
+		return entity.ModerationItem{}, entity.ErrAlreadyModerated
+	}
+	updated, err := s.answerRepo.SetModerationStatus(ctx, id, entity.ModerationPending, status, reason)
+	if err != nil {
+		return entity.ModerationItem{}, err
+	}
+	if !updated {
+		return entity.ModerationItem{}, entity.ErrAlreadyModerated
+	}
+
+	after := *answer
+	after.ModerationStatus = status
+	after.ModerationReason = reason
+	after.Version++
+	if err := recordAudit(ctx, s.audit, moderationAuditAction(status), entity.AuditEntityAnswer, id, answer, &after); err != nil {
+		return entity.ModerationItem{}, err
+	}
+	if status == entity.ModerationApproved {
+		if err := recordEvent(ctx, s.outbox, events.TypeAnswerCreated, after.QuestionID, &after); err != nil {
+			return entity.ModerationItem{}, err
+		}
+	}
+	return answerModerationItem(after), nil
+}
+
+// notifyModerationDecision сообщает автору, что его вопрос или ответ одобрен или отклонён
+func notifyModerationDecision(ctx context.Context, repo repository.NotificationRepository, item entity.ModerationItem) error {
+	notificationType := entity.NotificationModerationApproved
+	if item.Status == entity.ModerationRejected {
+		notificationType = entity.NotificationModerationRejected
+	}
+	return recordNotification(ctx, repo, item.AuthorID, notificationType, map[string]any{
+		"type":        item.Type,
+		"id":          item.ID,
+		"question_id": item.QuestionID,
+		"reason":      item.Reason,
+	})
+}
+
+// invalidateModerationItem сбрасывает кэш вопроса или ответа после коммита смены его статуса,
+// иначе до истечения TTL все видели бы прежний статус
+func invalidateModerationItem(ctx context.Context, invalidator CacheInvalidator, item entity.ModerationItem) {
+	if invalidator == nil {
+		return
+	}
+	if item.Type == entity.ModerationTypeQuestion {
+		invalidator.InvalidateQuestion(ctx, item.ID)
+		return
+	}
+	invalidator.InvalidateAnswers(ctx, item.QuestionID)
+}
+
+// getModerationItem читает вопрос или ответ как элемент модерации
+func getModerationItem(ctx context.Context, questionRepo repository.QuestionRepository, answerRepo repository.AnswerRepository, itemType string, id int) (entity.ModerationItem, error) {
+	if itemType == entity.ModerationTypeQuestion {
+		question, err := questionRepo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ModerationItem{}, entity.ErrQuestionNotFound
+			}
+			return entity.ModerationItem{}, err
+		}
+		return questionModerationItem(*question), nil
+	}
+	answer, err := answerRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return entity.ModerationItem{}, entity.ErrAnswerNotFound
+		}
+		return entity.ModerationItem{}, err
+	}
+	return answerModerationItem(*answer), nil
+}
+
+// setModerationStatus меняет статус вопроса или ответа с from на to; false, если статус уже не from
+func setModerationStatus(ctx context.Context, questionRepo repository.QuestionRepository, answerRepo repository.AnswerRepository, itemType string, id int, from, to, reason string) (bool, error) {
+	if itemType == entity.ModerationTypeQuestion {
+		return questionRepo.SetModerationStatus(ctx, id, from, to, reason)
+	}
+	return answerRepo.SetModerationStatus(ctx, id, from, to, reason)
+}
+
+func moderationAuditAction(status string) string {
+	if status == entity.ModerationRejected {
+		return entity.AuditActionReject
+	}
+	return entity.AuditActionApprove
+}
+
+func questionModerationItem(question entity.Question) entity.ModerationItem {
+	return entity.ModerationItem{
+		Type:      entity.ModerationTypeQuestion,
+		ID:        question.ID,
+		AuthorID:  question.AuthorID,
+		Text:      question.Text,
+		Status:    question.ModerationStatus,
+		Reason:    question.ModerationReason,
+		CreatedAt: question.CreatedAt,
+	}
+}
+
+func answerModerationItem(answer entity.Answer) entity.ModerationItem {
+	return entity.ModerationItem{
+		Type:       entity.ModerationTypeAnswer,
+		ID:         answer.ID,
+		QuestionID: answer.QuestionID,
+		AuthorID:   answer.UserID,
+		Text:       answer.Text,
+		Status:     answer.ModerationStatus,
+		Reason:     answer.ModerationReason,
+		CreatedAt:  answer.CreatedAt,
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/cache"
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type memoryNotificationRepository struct {
+	repository.NotificationRepository
+	notifications []entity.Notification
+}
+
+func (r *memoryNotificationRepository) Add(ctx context.Context, notification *entity.Notification) error {
+	notification.ID = int64(len(r.notifications) + 1)
+	r.notifications = append(r.notifications, *notification)
+	return nil
+}
+
+func (r *memoryNotificationRepository) ListByUser(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.Notification, error) {
+	var result []entity.Notification
+	for i := len(r.notifications) - 1; i >= 0 && len(result) < limit; i-- {
+		n := r.notifications[i]
+		if n.UserID == userID && (beforeID == 0 || n.ID < beforeID) {
+			result = append(result, n)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryNotificationRepository) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	kept := r.notifications[:0]
+	for _, n := range r.notifications {
+		if n.UserID != userID {
+			kept = append(kept, n)
+		}
+	}
+	deleted := int64(len(r.notifications) - len(kept))
+	r.notifications = kept
+	return deleted, nil
+}
+
+func (r *memoryTransferQuestionRepository) GetPageByModerationStatus(ctx context.Context, status string, afterID, limit int) ([]entity.Question, error) {
+	var page []entity.Question
+	for _, q := range r.store.questions {
+		if q.ModerationStatus == status && q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryTransferQuestionRepository) SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error) {
+	for i, q := range r.store.questions {
+		if q.ID == id && q.ModerationStatus == from {
+			r.store.questions[i].ModerationStatus = to
+			r.store.questions[i].ModerationReason = reason
+			r.store.questions[i].Version++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memoryTransferQuestionRepository) GetPageByAuthor(ctx context.Context, authorID string, afterID, limit int) ([]entity.Question, error) {
+	var page []entity.Question
+	for _, q := range r.store.questions {
+		if q.AuthorID == authorID && q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryTransferQuestionRepository) ClearAuthor(ctx context.Context, authorID string) ([]entity.Question, error) {
+	var cleared []entity.Question
+	for i := range r.store.questions {
+		if r.store.questions[i].AuthorID == authorID {
+			r.store.questions[i].AuthorID = ""
+			r.store.questions[i].Version++
+			cleared = append(cleared, r.store.questions[i])
+		}
+	}
+	return cleared, nil
+}
+
+func (r *memoryTransferAnswerRepository) GetByID(ctx context.Context, id int) (*entity.Answer, error) {
+	for _, a := range r.store.answers {
+		if a.ID == id {
+			return &a, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryTransferAnswerRepository) SetModerationStatus(ctx context.Context, id int, from, to, reason string) (bool, error) {
+	for i, a := range r.store.answers {
+		if a.ID == id && a.ModerationStatus == from {
+			r.store.answers[i].ModerationStatus = to
+			r.store.answers[i].ModerationReason = reason
+			r.store.answers[i].Version++
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestModerationService_ApproveHeldQuestion(t *testing.T) {
+	store := &memoryTransferStore{}
+	questionRepo := &memoryTransferQuestionRepository{store: store}
+	outbox := &memoryOutboxRepository{}
+	audit := &memoryAuditRepository{}
+	notifications := &memoryNotificationRepository{}
+	// любая ссылка откладывает текст на проверку
+	moderator := moderation.NewChain(moderation.Spam{MaxLinks: 0})
+	// кэш под проверкой видимости, как в main: решение модератора должно сбросить закэшированный статус
+	servicesCache := cache.NewLRU(100)
+	cached, _ := NewCachedServices(NewQuestionService(questionRepo, store, outbox, audit, moderator), nil, servicesCache, time.Minute, time.Second)
+	questions, _ := NewVisibleServices(cached, nil)
+	svc := NewModerationService(questionRepo, &memoryTransferAnswerRepository{store: store}, notifications, store, outbox, audit,
+		NewCacheInvalidator(servicesCache))
+
+	alice := WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "alice"})
+	bob := WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "bob"})
+
+	question, err := questions.CreateQuestion(alice, "Почему не работает https://example.com?")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if question.ModerationStatus != entity.ModerationPending || question.AuthorID != "alice" || len(outbox.messages) != 0 {
+		t.Fatalf("expected pending question of alice without events, got %+v and %d events", question, len(outbox.messages))
+	}
+
+	if _, err := questions.GetQuestion(bob, question.ID); !errors.Is(err, entity.ErrQuestionNotFound) {
+		t.Errorf("expected pending question to be hidden from bob, got %v", err)
+	}
+	if _, err := questions.GetQuestion(alice, question.ID); err != nil {
+		t.Errorf("expected pending question to be visible to its author, got %v", err)
+	}
+	if page, err := questions.GetQuestionsPage(alice, 0, 10); err != nil || len(page) != 1 {
+		t.Errorf("expected pending question in the author's list, got %+v, %v", page, err)
+	}
+	if page, err := questions.GetQuestionsPage(bob, 0, 10); err != nil || len(page) != 0 {
+		t.Errorf("expected pending question to be hidden from bob's list, got %+v, %v", page, err)
+	}
+
+	pending, err := svc.ListPending(context.Background(), entity.ModerationTypeQuestion, 0, 10)
+	if err != nil || len(pending) != 1 || pending[0].ID != question.ID {
+		t.Fatalf("expected question in the queue, got %+v, %v", pending, err)
+	}
+
+	if _, err := svc.Reject(context.Background(), entity.ModerationTypeQuestion, question.ID, " "); !errors.Is(err, entity.ErrEmptyModerationReason) {
+		t.Errorf("expected ErrEmptyModerationReason, got %v", err)
+	}
+
+	item, err := svc.Approve(context.Background(), entity.ModerationTypeQuestion, question.ID, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != entity.ModerationApproved {
+		t.Errorf("expected approved item, got %+v", item)
+	}
+	if len(outbox.messages) != 1 || outbox.messages[0].EventType != events.TypeQuestionCreated {
+		t.Errorf("expected question.created after approval, got %+v", outbox.messages)
+	}
+	if _, err := questions.GetQuestion(bob, question.ID); err != nil {
+		t.Errorf("expected approved question to be visible, got %v", err)
+	}
+	if len(notifications.notifications) != 1 || notifications.notifications[0].UserID != "alice" ||
+		notifications.notifications[0].Type != entity.NotificationModerationApproved {
+		t.Errorf("expected approval notification to alice, got %+v", notifications.notifications)
+	}
+	last := audit.events[len(audit.events)-1]
+	if last.Action != entity.AuditActionApprove || last.Before == nil || last.After == nil {
+		t.Errorf("expected approve audit event with snapshots, got %+v", last)
+	}
+
+	if _, err := svc.Approve(context.Background(), entity.ModerationTypeQuestion, question.ID, ""); !errors.Is(err, entity.ErrAlreadyModerated) {
+		t.Errorf("expected ErrAlreadyModerated, got %v", err)
+	}
+}
+
+func TestModerationService_RejectAnswer(t *testing.T) {
+	store := &memoryTransferStore{
+		questions: []entity.Question{{ID: 1, Text: "Вопрос", ModerationStatus: entity.ModerationApproved}},
+		answers: []entity.Answer{
+			{ID: 1, QuestionID: 1, UserID: "bob", Text: "КУПИТЕ КУРСЫ", ModerationStatus: entity.ModerationPending},
+		},
+	}
+	outbox := &memoryOutboxRepository{}
+	notifications := &memoryNotificationRepository{}
+	svc := NewModerationService(&memoryTransferQuestionRepository{store: store}, &memoryTransferAnswerRepository{store: store}, notifications, store, outbox, nil, nil)
+
+	if _, err := svc.ListPending(context.Background(), "comment", 0, 10); !errors.Is(err, entity.ErrInvalidModerationType) {
+		t.Errorf("expected ErrInvalidModerationType, got %v", err)
+	}
+
+	item, err := svc.Reject(context.Background(), entity.ModerationTypeAnswer, 1, "Реклама")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if item.Status != entity.ModerationRejected || store.answers[0].ModerationReason != "Реклама" || len(outbox.messages) != 0 {
+		t.Errorf("expected rejected answer without events, got %+v and %d events", store.answers[0], len(outbox.messages))
+	}
+
+	if len(notifications.notifications) != 1 {
+		t.Fatalf("expected one notification, got %+v", notifications.notifications)
+	}
+	notification := notifications.notifications[0]
+	var data struct {
+		Type       string `json:"type"`
+		QuestionID int    `json:"question_id"`
+		Reason     string `json:"reason"`
+	}
+	if err := json.Unmarshal(notification.Data, &data); err != nil {
+		t.Fatalf("notification data is not valid JSON: %v", err)
+	}
+	if notification.UserID != "bob" || notification.Type != entity.NotificationModerationRejected ||
+		data.Type != entity.ModerationTypeAnswer || data.QuestionID != 1 || data.Reason != "Реклама" {
+		t.Errorf("expected rejection notification to bob with reason, got %+v %+v", notification, data)
+	}
+
+	if _, err := svc.Reject(context.Background(), entity.ModerationTypeAnswer, 2, "Реклама"); !errors.Is(err, entity.ErrAnswerNotFound) {
+		t.Errorf("expected ErrAnswerNotFound, got %v", err)
+	}
+}
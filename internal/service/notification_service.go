@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// NotificationService уведомления пользователя о решениях по его вопросам и ответам
+type NotificationService interface {
+	// List возвращает до limit уведомлений пользователя с ID меньше beforeID (0 — с самого нового), новые первыми
+	List(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.Notification, error)
+}
+
+type notificationService struct {
+	repo repository.NotificationRepository
+}
+
+func NewNotificationService(repo repository.NotificationRepository) NotificationService {
+	return &notificationService{repo: repo}
+}
+
+func (s *notificationService) List(ctx context.Context, userID string, beforeID int64, limit int) ([]entity.Notification, error) {
+	ctx, span := startSpan(ctx, "NotificationService.List")
+	defer span.End()
+
+	notifications, err := s.repo.ListByUser(ctx, userID, beforeID, limit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return notifications, nil
+}
+
+// recordNotification записывает уведомление пользователю. Как и recordEvent, вызывается внутри WithinTx.
+// Пустой userID (анонимный автор) и nil репозиторий — ничего не делает
+func recordNotification(ctx context.Context, repo repository.NotificationRepository, userID, notificationType string, data any) error {
+	if repo == nil || userID == "" {
+		return nil
+	}
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return repo.Add(ctx, &entity.Notification{UserID: userID, Type: notificationType, Data: payload})
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// recordEvent записывает событие в outbox. Вызывается внутри WithinTx вместе с изменением данных,
+// поэтому событие сохраняется тогда и только тогда, когда фиксируется само изменение
+func recordEvent(ctx context.Context, outbox repository.OutboxRepository, eventType string, questionID int, data any) error {
+	if outbox == nil {
+		return nil
+	}
+	event, err := events.NewEvent(eventType, questionID, data)
+	if err != nil {
+		return err
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return outbox.Add(ctx, &entity.OutboxMessage{
+		EventID:       event.ID,
+		EventType:     event.Type,
+		AggregateType: entity.AggregateQuestion,
+		AggregateID:   questionID,
+		Payload:       payload,
+	})
+}
+
+// OutboxEventLoader загружает событие из outbox для уведомлений, пришедших без данных
+func OutboxEventLoader(outbox repository.OutboxRepository) events.EventLoader {
+	return func(ctx context.Context, eventID string) (events.Event, error) {
+		var event events.Event
+		message, err := outbox.GetByEventID(ctx, eventID)
+		if err != nil {
+			return event, err
+		}
+		err = json.Unmarshal(message.Payload, &event)
+		return event, err
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// OutboxRelay публикует записанные в outbox события в sink (шину событий, вебхуки, файл).
+// Доставка at-least-once: событие может быть отправлено повторно, если реплика упала
+// до фиксации отметки о публикации, поэтому получатели дедуплицируют по ID события.
+// Событие, которое не удалось опубликовать MaxAttempts раз, откладывается (parked_at);
+// вернуть его в очередь можно, сбросив parked_at и attempts
+type OutboxRelay interface {
+	// Relay публикует пачку неопубликованных событий и возвращает число опубликованных
+	Relay(ctx context.Context) (int, error)
+
+	// PurgePublished удаляет опубликованные события старше retention
+	PurgePublished(ctx context.Context, retention time.Duration) (int64, error)
+}
+
+// OutboxSink получатель событий relay. Name сохраняется в outbox.delivered_sinks,
+// поэтому должен быть стабильным между запусками
+type OutboxSink struct {
+	Name      string
+	Publisher events.Publisher
+}
+
+// OutboxRelayPolicy MaxAttempts — неудачных попыток, после которых событие откладывается; 0 — без предела
+type OutboxRelayPolicy struct {
+	BatchSize   int
+	MaxAttempts int
+}
+
+type outboxRelay struct {
+	repo      repository.OutboxRepository
+	txManager repository.TxManager
+	sinks     []OutboxSink
+	policy    OutboxRelayPolicy
+}
+
+func NewOutboxRelay(repo repository.OutboxRepository, txManager repository.TxManager, sinks []OutboxSink, policy OutboxRelayPolicy) OutboxRelay {
+	return &outboxRelay{
+		repo:      repo,
+		txManager: txManager,
+		sinks:     sinks,
+		policy:    policy,
+	}
+}
+
+func (r *outboxRelay) Relay(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "OutboxRelay.Relay")
+	defer span.End()
+
+	published := 0
+	err := r.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := r.repo.TryLockRelay(ctx)
+		if err != nil || !locked {
+			return err
+		}
+
+		messages, err := r.repo.FetchUnpublished(ctx, r.policy.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		// после неудачи остальные события агрегата ждут следующего прохода, чтобы не нарушить порядок
+		blocked := make(map[string]struct{})
+		ids := make([]int64, 0, len(messages))
+		for _, message := range messages {
+			aggregate := message.AggregateType + ":" + strconv.Itoa(message.AggregateID)
+			if _, ok := blocked[aggregate]; ok {
+				continue
+			}
+
+			delivered, err := r.publish(ctx, message)
+			if err != nil {
+				blocked[aggregate] = struct{}{}
+				if r.policy.MaxAttempts > 0 && message.Attempts+1 >= r.policy.MaxAttempts {
+					log.Printf("Событие %s из outbox отложено после %d попыток: %v", message.EventID, message.Attempts+1, err)
+				} else {
+					log.Printf("Ошибка публикации события %s из outbox: %v", message.EventID, err)
+				}
+				if err := r.repo.MarkFailed(ctx, message.ID, delivered, truncate(err.Error(), 1000), r.policy.MaxAttempts); err != nil {
+					return err
+				}
+				continue
+			}
+			ids = append(ids, message.ID)
+		}
+
+		if err := r.repo.MarkPublished(ctx, ids, time.Now()); err != nil {
+			return err
+		}
+		published = len(ids)
+		return nil
+	})
+	if err != nil {
+		return 0, spanError(span, err)
+	}
+	return published, nil
+}
+
+// publish отправляет событие в sink, которые его ещё не получили, и возвращает список получивших.
+// Каждый sink публикуется независимо: сбой одного не повторяет доставку в остальные
+func (r *outboxRelay) publish(ctx context.Context, message entity.OutboxMessage) ([]string, error) {
+	delivered := slices.Clone(message.DeliveredSinks)
+	var event events.Event
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return delivered, err
+	}
+
+	var errs []error
+	for _, sink := range r.sinks {
+		if slices.Contains(delivered, sink.Name) {
+			continue
+		}
+		// savepoint: ошибка sink, пишущего в БД, не должна прерывать всю транзакцию relay
+		err := r.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			return sink.Publisher.Publish(ctx, event)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name, err))
+			continue
+		}
+		delivered = append(delivered, sink.Name)
+	}
+	return delivered, errors.Join(errs...)
+}
+
+func (r *outboxRelay) PurgePublished(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := startSpan(ctx, "OutboxRelay.PurgePublished")
+	defer span.End()
+
+	deleted, err := r.repo.DeletePublishedBefore(ctx, time.Now().Add(-retention))
+	if err != nil {
+		return 0, spanError(span, err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+type noTxManager struct{}
+
+func (noTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type memoryOutboxRepository struct {
+	repository.OutboxRepository
+	messages []*entity.OutboxMessage
+}
+
+func (r *memoryOutboxRepository) Add(ctx context.Context, message *entity.OutboxMessage) error {
+	message.ID = int64(len(r.messages) + 1)
+	r.messages = append(r.messages, message)
+	return nil
+}
+
+func (r *memoryOutboxRepository) TryLockRelay(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+func (r *memoryOutboxRepository) FetchUnpublished(ctx context.Context, limit int) ([]entity.OutboxMessage, error) {
+	var result []entity.OutboxMessage
+	for _, m := range r.messages {
+		if m.PublishedAt == nil && m.ParkedAt == nil && len(result) < limit {
+			result = append(result, *m)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryOutboxRepository) MarkPublished(ctx context.Context, ids []int64, publishedAt time.Time) error {
+	for _, id := range ids {
+		r.messages[id-1].PublishedAt = &publishedAt
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) MarkFailed(ctx context.Context, id int64, deliveredSinks []string, lastError string, maxAttempts int) error {
+	m := r.messages[id-1]
+	m.Attempts++
+	m.LastError = lastError
+	m.DeliveredSinks = deliveredSinks
+	if maxAttempts > 0 && m.Attempts >= maxAttempts {
+		now := time.Now()
+		m.ParkedAt = &now
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) GetByEventID(ctx context.Context, eventID string) (*entity.OutboxMessage, error) {
+	for _, m := range r.messages {
+		if m.EventID == eventID {
+			return m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+type recordingPublisher struct {
+	failOnce   map[string]bool
+	failAlways bool
+	received   []events.Event
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event events.Event) error {
+	if p.failOnce[event.ID] || p.failAlways {
+		delete(p.failOnce, event.ID)
+		return errors.New("sink unavailable")
+	}
+	p.received = append(p.received, event)
+	return nil
+}
+
+func TestOutboxRelay_PreservesOrderPerAggregate(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	ctx := context.Background()
+
+	for _, e := range []struct {
+		eventType  string
+		questionID int
+	}{
+		{events.TypeQuestionCreated, 1},
+		{events.TypeAnswerCreated, 1},
+		{events.TypeQuestionCreated, 2},
+		{events.TypeQuestionDeleted, 1},
+	} {
+		if err := recordEvent(ctx, repo, e.eventType, e.questionID, map[string]int{"id": e.questionID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var second events.Event
+	if err := json.Unmarshal(repo.messages[1].Payload, &second); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sink := &recordingPublisher{failOnce: map[string]bool{second.ID: true}}
+	relay := NewOutboxRelay(repo, noTxManager{}, []OutboxSink{{Name: "events", Publisher: sink}}, OutboxRelayPolicy{BatchSize: 10})
+
+	published, err := relay.Relay(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// событие вопроса 2 не зависит от сбоя, события вопроса 1 после сбоя ждут
+	if published != 2 {
+		t.Errorf("expected 2 published events, got %d", published)
+	}
+	if repo.messages[1].Attempts != 1 || repo.messages[3].PublishedAt != nil {
+		t.Errorf("expected failed event to block later events of the same question")
+	}
+
+	published, err = relay.Relay(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if published != 2 {
+		t.Errorf("expected 2 published events on retry, got %d", published)
+	}
+
+	var order []string
+	for _, e := range sink.received {
+		if e.QuestionID == 1 {
+			order = append(order, e.Type)
+		}
+	}
+	want := []string{events.TypeQuestionCreated, events.TypeAnswerCreated, events.TypeQuestionDeleted}
+	if len(order) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, order)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("expected events %v, got %v", want, order)
+			break
+		}
+	}
+}
+
+func TestOutboxRelay_PerSinkDeliveryAndParking(t *testing.T) {
+	repo := &memoryOutboxRepository{}
+	ctx := context.Background()
+	for _, questionID := range []int{1, 1} {
+		if err := recordEvent(ctx, repo, events.TypeAnswerCreated, questionID, map[string]int{"id": questionID}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	healthy := &recordingPublisher{}
+	broken := &recordingPublisher{failAlways: true}
+	relay := NewOutboxRelay(repo, noTxManager{}, []OutboxSink{
+		{Name: "events", Publisher: healthy},
+		{Name: "webhooks", Publisher: broken},
+	}, OutboxRelayPolicy{BatchSize: 10, MaxAttempts: 2})
+
+	for i := 0; i < 2; i++ {
+		if _, err := relay.Relay(ctx); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// исправный sink получил первое событие один раз, несмотря на повтор из-за сломанного
+	if len(healthy.received) != 1 || repo.messages[0].Attempts != 2 || repo.messages[0].ParkedAt == nil {
+		t.Fatalf("expected single delivery to healthy sink and parked event, got %d deliveries, %+v", len(healthy.received), repo.messages[0])
+	}
+	if !slices.Equal(repo.messages[0].DeliveredSinks, []string{"events"}) {
+		t.Errorf("expected delivered sinks [events], got %v", repo.messages[0].DeliveredSinks)
+	}
+
+	// отложенное событие больше не задерживает следующее событие вопроса
+	broken.failAlways = false
+	if published, err := relay.Relay(ctx); err != nil || published != 1 {
+		t.Fatalf("expected next event to be published, got %d, %v", published, err)
+	}
+	if repo.messages[1].PublishedAt == nil || repo.messages[0].PublishedAt != nil {
+		t.Errorf("expected second event published and parked one skipped")
+	}
+}
+
+func TestOutboxEventLoader(t *testing.T) {
+	outbox := &memoryOutboxRepository{}
+	if err := recordEvent(context.Background(), outbox, events.TypeAnswerCreated, 1, map[string]string{"text": "Ответ"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	load := OutboxEventLoader(outbox)
+
+	event, err := load(context.Background(), outbox.messages[0].EventID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if event.Type != events.TypeAnswerCreated || string(event.Data) != `{"text":"Ответ"}` {
+		t.Errorf("expected event with data, got %+v", event)
+	}
+	if _, err := load(context.Background(), "missing"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("expected ErrRecordNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,403 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// PrivacyService запросы пользователей на выгрузку и удаление своих данных (GDPR)
+type PrivacyService interface {
+	// ExportUserData пишет в w JSON архив данных пользователя: его ответы, вопросы и комментарии, вебхуки,
+	// уведомления, жалобы и события аудита, где он автор. Архив пишется потоком, страницами по exportBatchSize
+	ExportUserData(ctx context.Context, userID string, w io.Writer) error
+
+	// EraseUserData обезличивает (entity.ErasureAnonymize, с событием answer.updated) или удаляет
+	// (entity.ErasureDelete, с событием answer.deleted) ответы и комментарии пользователя, убирает его из авторов вопросов
+	// и удаляет его вебхуки, уведомления и жалобы в одной транзакции, записывая запрос в журнал аудита.
+	// После коммита сбрасывается кэш затронутых вопросов и ответов. В той же транзакции пользователь заменяется на entity.ErasedUserID
+	// в журнале аудита (вместе с IP), в событиях outbox и в доставках вебхуков. Вопросы не удаляются ни в одном режиме: на них отвечали другие.
+	// Повторный запрос ничего не меняет и возвращает нули
+	EraseUserData(ctx context.Context, userID, mode string) (*entity.ErasureResult, error)
+}
+
+type privacyService struct {
+	answerRepo       repository.AnswerRepository
+	questionRepo     repository.QuestionRepository
+	webhookRepo      repository.WebhookRepository
+	notificationRepo repository.NotificationRepository
+	flagRepo         repository.FlagRepository
+	commentRepo      repository.CommentRepository
+	txManager        repository.TxManager
+	outbox           repository.OutboxRepository
+	audit            repository.AuditRepository
+	invalidator      CacheInvalidator
+	now              func() time.Time
+}
+
+// NewPrivacyService invalidator nil, если кэш выключен
+func NewPrivacyService(
+	answerRepo repository.AnswerRepository,
+	questionRepo repository.QuestionRepository,
+	webhookRepo repository.WebhookRepository,
+	notificationRepo repository.NotificationRepository,
+	flagRepo repository.FlagRepository,
+	commentRepo repository.CommentRepository,
+	txManager repository.TxManager,
+	outbox repository.OutboxRepository,
+	audit repository.AuditRepository,
+	invalidator CacheInvalidator,
+) PrivacyService {
+	return &privacyService{
+		answerRepo:       answerRepo,
+		questionRepo:     questionRepo,
+		webhookRepo:      webhookRepo,
+		notificationRepo: notificationRepo,
+		flagRepo:         flagRepo,
+		commentRepo:      commentRepo,
+		txManager:        txManager,
+		outbox:           outbox,
+		audit:            audit,
+		invalidator:      invalidator,
+		now:              time.Now,
+	}
+}
+
+func ValidateErasureMode(mode string) error {
+	if mode != entity.ErasureAnonymize && mode != entity.ErasureDelete {
+		return entity.ErrInvalidErasureMode
+	}
+	return nil
+}
+
+func validateDataSubject(userID string) error {
+	if strings.TrimSpace(userID) == "" {
+		return entity.ErrInvalidUserID
+	}
+	if userID == entity.ErasedUserID {
+		return entity.ErrReservedUserID
+	}
+	return nil
+}
+
+func (s *privacyService) ExportUserData(ctx context.Context, userID string, w io.Writer) error {
+	ctx, span := startSpan(ctx, "PrivacyService.ExportUserData")
+	defer span.End()
+
+	if err := validateDataSubject(userID); err != nil {
+		return spanError(span, err)
+	}
+
+	archive := &archiveWriter{w: bufio.NewWriter(w)}
+	archive.raw(`{"user_id":`)
+	archive.value(userID)
+	archive.raw(`,"exported_at":`)
+	archive.value(s.now().UTC())
+
+	archive.openArray("answers")
+	afterID := 0
+	for {
+		answers, err := s.answerRepo.GetPageByUserID(ctx, userID, afterID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(answers) == 0 {
+			break
+		}
+		for _, answer := range answers {
+			archive.item(answer)
+		}
+		afterID = answers[len(answers)-1].ID
+	}
+	archive.raw("]")
+
+	archive.openArray("questions")
+	afterID = 0
+	for {
+		questions, err := s.questionRepo.GetPageByAuthor(ctx, userID, afterID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(questions) == 0 {
+			break
+		}
+		for _, question := range questions {
+			archive.item(question)
+		}
+		afterID = questions[len(questions)-1].ID
+	}
+	archive.raw("]")
+
+	archive.openArray("comments")
+	afterID = 0
+	for {
+		comments, err := s.commentRepo.GetPageByUser(ctx, userID, afterID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(comments) == 0 {
+			break
+		}
+		for _, comment := range comments {
+			archive.item(comment)
+		}
+		afterID = comments[len(comments)-1].ID
+	}
+	archive.raw("]")
+
+	webhooks, err := s.webhookRepo.GetByOwner(ctx, userID)
+	if err != nil {
+		return spanError(span, entity.ErrDatabaseQuery)
+	}
+	archive.openArray("webhooks")
+	for _, webhook := range webhooks {
+		archive.item(webhook)
+	}
+	archive.raw("]")
+
+	archive.openArray("notifications")
+	var beforeID int64
+	for {
+		notifications, err := s.notificationRepo.ListByUser(ctx, userID, beforeID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(notifications) == 0 {
+			break
+		}
+		for _, notification := range notifications {
+			archive.item(notification)
+		}
+		beforeID = notifications[len(notifications)-1].ID
+	}
+	archive.raw("]")
+
+	archive.openArray("flags")
+	var afterFlagID int64
+	for {
+		flags, err := s.flagRepo.GetPageByUser(ctx, userID, afterFlagID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(flags) == 0 {
+			break
+		}
+		for _, flag := range flags {
+			archive.item(flag)
+		}
+		afterFlagID = flags[len(flags)-1].ID
+	}
+	archive.raw("]")
+
+	archive.openArray("audit_events")
+	beforeID = 0
+	for s.audit != nil {
+		auditEvents, err := s.audit.List(ctx, entity.AuditFilter{Actor: userID}, beforeID, exportBatchSize)
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(auditEvents) == 0 {
+			break
+		}
+		for _, event := range auditEvents {
+			archive.item(event)
+		}
+		beforeID = auditEvents[len(auditEvents)-1].ID
+	}
+	archive.raw("]}\n")
+
+	if err := archive.flush(); err != nil {
+		return spanError(span, err)
+	}
+	return nil
+}
+
+func (s *privacyService) EraseUserData(ctx context.Context, userID, mode string) (*entity.ErasureResult, error) {
+	ctx, span := startSpan(ctx, "PrivacyService.EraseUserData")
+	defer span.End()
+
+	if err := validateDataSubject(userID); err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := ValidateErasureMode(mode); err != nil {
+		return nil, spanError(span, err)
+	}
+
+	result := &entity.ErasureResult{UserID: userID, Mode: mode}
+	var answers []entity.Answer
+	var questions []entity.Question
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if mode == entity.ErasureAnonymize {
+			comments, err := s.commentRepo.ReassignUser(ctx, userID, entity.ErasedUserID)
+			if err != nil {
+				return err
+			}
+			result.Comments = comments
+
+			answers, err = s.answerRepo.ReassignUser(ctx, userID, entity.ErasedUserID)
+			if err != nil {
+				return err
+			}
+			// об ответах на модерации подписчики не знают, обновлять им нечего
+			for _, answer := range answers {
+				if !entity.Published(answer.ModerationStatus) {
+					continue
+				}
+				if err := recordEvent(ctx, s.outbox, events.TypeAnswerUpdated, answer.QuestionID, answer); err != nil {
+					return err
+				}
+			}
+			result.Answers = int64(len(answers))
+		} else {
+			// комментарии до ответов: удаление ответа каскадом удаляет и комментарии пользователя к нему,
+			// и они не попали бы в счётчик
+			comments, err := s.commentRepo.DeleteByUser(ctx, userID)
+			if err != nil {
+				return err
+			}
+			result.Comments = comments
+
+			answers, err = s.answerRepo.DeleteByFilter(ctx, entity.AnswerFilter{UserID: userID}, 0)
+			if err != nil {
+				return err
+			}
+			for _, answer := range answers {
+				err := recordEvent(ctx, s.outbox, events.TypeAnswerDeleted, answer.QuestionID, map[string]int{
+					"id":          answer.ID,
+					"question_id": answer.QuestionID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+			result.Answers = int64(len(answers))
+		}
+
+		var err error
+		questions, err = s.questionRepo.ClearAuthor(ctx, userID)
+		if err != nil {
+			return err
+		}
+		result.Questions = int64(len(questions))
+
+		webhooks, err := s.webhookRepo.DeleteByOwner(ctx, userID)
+		if err != nil {
+			return err
+		}
+		result.Webhooks = webhooks
+
+		notifications, err := s.notificationRepo.DeleteByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		result.Notifications = notifications
+
+		flags, err := s.flagRepo.DeleteByUser(ctx, userID)
+		if err != nil {
+			return err
+		}
+		result.Flags = flags
+
+		// пользователь остаётся и в истории: в журнале аудита, в событиях outbox и в доставках вебхуков.
+		// В режиме delete из его снимков убирается и текст удалённых ответов и комментариев
+		dropText := mode == entity.ErasureDelete
+		if err := s.webhookRepo.EraseUserFromDeliveries(ctx, userID, dropText); err != nil {
+			return err
+		}
+		if s.outbox != nil {
+			if err := s.outbox.EraseUser(ctx, userID, dropText); err != nil {
+				return err
+			}
+		}
+		if s.audit != nil {
+			if err := s.audit.EraseUser(ctx, userID, dropText); err != nil {
+				return err
+			}
+		}
+
+		// снимки удалённых ответов в журнал не пишутся, иначе удалённые данные остались бы в нём;
+		// по той же причине в событии нет ID пользователя, даже если он удаляет свои данные сам
+		erased := *result
+		erased.UserID = entity.ErasedUserID
+		if meta := AuditMetaFromContext(ctx); meta.Actor == userID {
+			meta.Actor, meta.IP = entity.ErasedUserID, ""
+			ctx = WithAuditMeta(ctx, meta)
+		}
+		return recordAudit(ctx, s.audit, entity.AuditActionErase, entity.AuditEntityUser, 0, nil, erased)
+	})
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	s.invalidate(ctx, questions, answers)
+	return result, nil
+}
+
+// invalidate сбрасывает кэш вопросов, у которых убран автор, и ответов на вопросы,
+// где ответы пользователя обезличены или удалены
+func (s *privacyService) invalidate(ctx context.Context, questions []entity.Question, answers []entity.Answer) {
+	if s.invalidator == nil {
+		return
+	}
+	for _, question := range questions {
+		s.invalidator.InvalidateQuestion(ctx, question.ID)
+	}
+	invalidated := make(map[int]bool)
+	for _, answer := range answers {
+		if !invalidated[answer.QuestionID] {
+			invalidated[answer.QuestionID] = true
+			s.invalidator.InvalidateAnswers(ctx, answer.QuestionID)
+		}
+	}
+}
+
+// archiveWriter пишет JSON архив по частям; первая ошибка записи запоминается и возвращается из flush
+type archiveWriter struct {
+	w     *bufio.Writer
+	items int
+	err   error
+}
+
+func (a *archiveWriter) raw(s string) {
+	if a.err == nil {
+		_, a.err = a.w.WriteString(s)
+	}
+}
+
+func (a *archiveWriter) value(v any) {
+	if a.err != nil {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		a.err = err
+		return
+	}
+	_, a.err = a.w.Write(data)
+}
+
+func (a *archiveWriter) openArray(name string) {
+	a.raw(`,"` + name + `":[`)
+	a.items = 0
+}
+
+func (a *archiveWriter) item(v any) {
+	if a.items > 0 {
+		a.raw(",")
+	}
+	a.items++
+	a.value(v)
+}
+
+func (a *archiveWriter) flush() error {
+	if a.err == nil {
+		a.err = a.w.Flush()
+	}
+	return a.err
+}
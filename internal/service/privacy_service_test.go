@@ -0,0 +1,291 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+)
+
+func (r *memoryTransferAnswerRepository) GetPageByUserID(ctx context.Context, userID string, afterID, limit int) ([]entity.Answer, error) {
+	var page []entity.Answer
+	for _, a := range r.store.answers {
+		if a.UserID == userID && a.ID > afterID && len(page) < limit {
+			page = append(page, a)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryTransferAnswerRepository) ReassignUser(ctx context.Context, userID, newUserID string) ([]entity.Answer, error) {
+	var reassigned []entity.Answer
+	for i := range r.store.answers {
+		if r.store.answers[i].UserID == userID {
+			r.store.answers[i].UserID = newUserID
+			r.store.answers[i].Version++
+			reassigned = append(reassigned, r.store.answers[i])
+		}
+	}
+	return reassigned, nil
+}
+
+func (r *memoryWebhookRepository) GetByOwner(ctx context.Context, ownerID string) ([]entity.Webhook, error) {
+	var result []entity.Webhook
+	for _, w := range r.webhooks {
+		if w.OwnerID == ownerID {
+			result = append(result, *w)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryWebhookRepository) DeleteByOwner(ctx context.Context, ownerID string) (int64, error) {
+	var deleted int64
+	for id, w := range r.webhooks {
+		if w.OwnerID == ownerID {
+			delete(r.webhooks, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *memoryAuditRepository) List(ctx context.Context, filter entity.AuditFilter, beforeID int64, limit int) ([]entity.AuditEvent, error) {
+	var result []entity.AuditEvent
+	for i := len(r.events) - 1; i >= 0 && len(result) < limit; i-- {
+		event := r.events[i]
+		if (beforeID == 0 || event.ID < beforeID) && (filter.Actor == "" || event.Actor == filter.Actor) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+// scrubUserJSON повторяет в памяти обезличивание JSON из репозиториев: ID пользователя в ключах автора
+// заменяется на entity.ErasedUserID, с dropText убирается и текст. field — вложенный объект, как data в событии
+func scrubUserJSON(data []byte, field, userID string, dropText bool) []byte {
+	var doc map[string]any
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return data
+	}
+	object := doc
+	if field != "" {
+		nested, ok := doc[field].(map[string]any)
+		if !ok {
+			return data
+		}
+		object = nested
+	}
+	changed := false
+	for _, key := range []string{"user_id", "author_id", "owner_id"} {
+		if object[key] == userID {
+			object[key] = entity.ErasedUserID
+			changed = true
+		}
+	}
+	if !changed {
+		return data
+	}
+	if dropText {
+		delete(object, "text")
+	}
+	scrubbed, _ := json.Marshal(doc)
+	return scrubbed
+}
+
+func (r *memoryWebhookRepository) EraseUserFromDeliveries(ctx context.Context, userID string, dropText bool) error {
+	for _, d := range r.deliveries {
+		d.Payload = scrubUserJSON(d.Payload, "data", userID, dropText)
+	}
+	return nil
+}
+
+func (r *memoryOutboxRepository) EraseUser(ctx context.Context, userID string, dropText bool) error {
+	for _, m := range r.messages {
+		m.Payload = scrubUserJSON(m.Payload, "data", userID, dropText)
+	}
+	return nil
+}
+
+func (r *memoryAuditRepository) EraseUser(ctx context.Context, userID string, dropText bool) error {
+	for i := range r.events {
+		event := &r.events[i]
+		if event.Actor == userID {
+			event.Actor = entity.ErasedUserID
+			event.IP = ""
+		}
+		if event.Before != nil {
+			event.Before = scrubUserJSON(event.Before, "", userID, dropText)
+		}
+		if event.After != nil {
+			event.After = scrubUserJSON(event.After, "", userID, dropText)
+		}
+	}
+	return nil
+}
+
+func newMemoryPrivacyService(invalidator CacheInvalidator) (*memoryTransferStore, *memoryWebhookRepository, *memoryOutboxRepository, *memoryAuditRepository, PrivacyService) {
+	store := &memoryTransferStore{questions: []entity.Question{
+		{ID: 1, Text: "Вопрос alice", AuthorID: "alice"},
+		{ID: 2, Text: "Вопрос bob", AuthorID: "bob"},
+	}, answers: []entity.Answer{
+		{ID: 1, QuestionID: 1, UserID: "alice", Text: "first"},
+		{ID: 2, QuestionID: 1, UserID: "bob", Text: "second"},
+		{ID: 3, QuestionID: 2, UserID: "alice", Text: "third"},
+	}}
+	aliceAnswer := `{"id":1,"question_id":1,"user_id":"alice","text":"first"}`
+	aliceEvent := []byte(`{"id":"e1","type":"answer.created","question_id":1,"data":` + aliceAnswer + `}`)
+	webhooks := &memoryWebhookRepository{webhooks: map[int]*entity.Webhook{
+		1: {ID: 1, OwnerID: "alice", URL: "https://alice.example/hook", Secret: "s3cret"},
+		2: {ID: 2, OwnerID: "bob", URL: "https://bob.example/hook"},
+	}, deliveries: []*entity.WebhookDelivery{
+		{ID: 1, WebhookID: 2, EventID: "e1", EventType: "answer.created", Payload: aliceEvent},
+	}}
+	outbox := &memoryOutboxRepository{messages: []*entity.OutboxMessage{
+		{ID: 1, EventID: "e1", EventType: "answer.created", Payload: aliceEvent},
+	}}
+	audit := &memoryAuditRepository{events: []entity.AuditEvent{
+		{ID: 1, Actor: "alice", Action: entity.AuditActionCreate, EntityType: entity.AuditEntityAnswer, EntityID: 1,
+			After: json.RawMessage(aliceAnswer), IP: "203.0.113.7"},
+		{ID: 2, Actor: "bob", Action: entity.AuditActionCreate, EntityType: entity.AuditEntityAnswer, EntityID: 2},
+	}}
+	return store, webhooks, outbox, audit, NewPrivacyService(
+		&memoryTransferAnswerRepository{store: store},
+		&memoryTransferQuestionRepository{store: store},
+		webhooks,
+		&memoryNotificationRepository{notifications: []entity.Notification{
+			{ID: 1, UserID: "alice", Type: entity.NotificationModerationApproved, Data: []byte(`{}`)},
+			{ID: 2, UserID: "bob", Type: entity.NotificationModerationRejected, Data: []byte(`{}`)},
+		}},
+		&memoryFlagRepository{flags: []entity.Flag{
+			{ID: 1, ItemType: entity.ModerationTypeAnswer, ItemID: 2, UserID: "alice", Reason: entity.FlagReasonSpam, Status: entity.FlagOpen},
+			{ID: 2, ItemType: entity.ModerationTypeAnswer, ItemID: 1, UserID: "bob", Reason: entity.FlagReasonOther, Status: entity.FlagOpen},
+		}},
+		&memoryCommentRepository{comments: []entity.Comment{
+			{ID: 1, AnswerID: intPtr(2), UserID: "alice", Text: "Уточнение"},
+			{ID: 2, AnswerID: intPtr(1), UserID: "bob", Text: "Спасибо"},
+		}},
+		store,
+		outbox,
+		audit,
+		invalidator,
+	)
+}
+
+func TestPrivacyService_ExportUserData(t *testing.T) {
+	_, _, _, _, svc := newMemoryPrivacyService(nil)
+
+	var buf bytes.Buffer
+	if err := svc.ExportUserData(context.Background(), "alice", &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var archive struct {
+		UserID        string                `json:"user_id"`
+		Answers       []entity.Answer       `json:"answers"`
+		Questions     []entity.Question     `json:"questions"`
+		Webhooks      []map[string]any      `json:"webhooks"`
+		Notifications []entity.Notification `json:"notifications"`
+		Flags         []entity.Flag         `json:"flags"`
+		Comments      []entity.Comment      `json:"comments"`
+		AuditEvents   []entity.AuditEvent   `json:"audit_events"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &archive); err != nil {
+		t.Fatalf("archive is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if archive.UserID != "alice" || len(archive.Answers) != 2 || len(archive.Questions) != 1 || len(archive.Webhooks) != 1 ||
+		len(archive.Notifications) != 1 || len(archive.Flags) != 1 || len(archive.Comments) != 1 || len(archive.AuditEvents) != 1 {
+		t.Errorf("expected 2 answers, 1 question, 1 webhook, 1 notification, 1 flag, 1 comment and 1 audit event of alice, got %+v", archive)
+	}
+	if _, ok := archive.Webhooks[0]["secret"]; ok {
+		t.Errorf("expected webhook secret to be omitted from the archive")
+	}
+}
+
+func TestPrivacyService_EraseUserData(t *testing.T) {
+	tests := []struct {
+		mode        string
+		wantAnswers int
+		wantEvents  int
+	}{
+		{entity.ErasureAnonymize, 3, 3},
+		{entity.ErasureDelete, 1, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			invalidator := &recordingInvalidator{}
+			store, webhooks, outbox, audit, svc := newMemoryPrivacyService(invalidator)
+			ctx := WithAuditMeta(context.Background(), entity.AuditMeta{Actor: "alice", IP: "203.0.113.7"})
+
+			result, err := svc.EraseUserData(ctx, "alice", tt.mode)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.Answers != 2 || result.Questions != 1 || result.Webhooks != 1 || result.Notifications != 1 || result.Flags != 1 ||
+				result.Comments != 1 {
+				t.Errorf("expected 2 answers, 1 question, 1 webhook, 1 notification, 1 flag and 1 comment erased, got %+v", result)
+			}
+			if store.questions[0].AuthorID != "" || len(store.questions) != 2 {
+				t.Errorf("expected question of alice to stay without author, got %+v", store.questions)
+			}
+			if len(store.answers) != tt.wantAnswers || len(outbox.messages) != tt.wantEvents || len(webhooks.webhooks) != 1 {
+				t.Errorf("expected %d answers and %d events left, got %d answers, %d events, %d webhooks",
+					tt.wantAnswers, tt.wantEvents, len(store.answers), len(outbox.messages), len(webhooks.webhooks))
+			}
+			for _, a := range store.answers {
+				if a.UserID == "alice" {
+					t.Errorf("expected no answers of alice left, got %+v", a)
+				}
+			}
+			if tt.mode == entity.ErasureAnonymize && outbox.messages[1].EventType != events.TypeAnswerUpdated {
+				t.Errorf("expected answer.updated for anonymized answer, got %+v", outbox.messages[1])
+			}
+			if !reflect.DeepEqual(invalidator.questions, []int{1}) || !reflect.DeepEqual(invalidator.answers, []int{1, 2}) {
+				t.Errorf("expected cache of question 1 and answers of questions 1, 2 invalidated, got %v and %v",
+					invalidator.questions, invalidator.answers)
+			}
+
+			last := audit.events[len(audit.events)-1]
+			if last.Action != entity.AuditActionErase || last.EntityType != entity.AuditEntityUser || last.Before != nil {
+				t.Errorf("expected erase audit event without snapshot, got %+v", last)
+			}
+
+			// из истории пользователь тоже убран, а в режиме delete — и текст его ответа
+			history := [][]byte{outbox.messages[0].Payload, webhooks.deliveries[0].Payload, audit.events[0].After, last.After}
+			for _, data := range history {
+				if bytes.Contains(data, []byte(`"alice"`)) {
+					t.Errorf("expected alice to be erased from history, got %s", data)
+				}
+				if tt.mode == entity.ErasureDelete && bytes.Contains(data, []byte(`"first"`)) {
+					t.Errorf("expected text of deleted answer to be erased from history, got %s", data)
+				}
+			}
+			if audit.events[0].Actor != entity.ErasedUserID || audit.events[0].IP != "" || last.Actor != entity.ErasedUserID {
+				t.Errorf("expected actor and IP of alice to be erased, got %+v", audit.events[0])
+			}
+
+			// повторный запрос ничего не меняет
+			result, err = svc.EraseUserData(ctx, "alice", tt.mode)
+			if err != nil {
+				t.Fatalf("unexpected error on repeat: %v", err)
+			}
+			if result.Answers != 0 || result.Questions != 0 || result.Webhooks != 0 || result.Notifications != 0 || result.Flags != 0 || result.Comments != 0 || len(store.answers) != tt.wantAnswers {
+				t.Errorf("expected repeat to be a no-op, got %+v", result)
+			}
+		})
+	}
+
+	_, _, _, _, svc := newMemoryPrivacyService(nil)
+	if _, err := svc.EraseUserData(context.Background(), "alice", "forget"); !errors.Is(err, entity.ErrInvalidErasureMode) {
+		t.Errorf("expected ErrInvalidErasureMode, got %v", err)
+	}
+	if _, err := svc.EraseUserData(context.Background(), entity.ErasedUserID, entity.ErasureDelete); !errors.Is(err, entity.ErrReservedUserID) {
+		t.Errorf("expected ErrReservedUserID, got %v", err)
+	}
+}
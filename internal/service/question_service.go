@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
+
+	"gorm.io/gorm"
 
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
 	"github.com/andrey-samosuk/answer-questions/internal/repository"
 )
 
@@ -12,50 +17,120 @@ type QuestionService interface {
 
 	GetQuestion(ctx context.Context, id int) (*entity.Question, error)
 
+	// GetQuestionsByIDs возвращает вопросы в порядке запрошенных ID одним запросом.
+	// Ненайденные ID возвращаются в missing, а не ошибкой; повторы ID игнорируются
+	GetQuestionsByIDs(ctx context.Context, ids []int) (questions []entity.Question, missing []int, err error)
+
+	// GetAllQuestions возвращает одобренные модератором вопросы; остальные вопросы видит в списке
+	// только их автор
 	GetAllQuestions(ctx context.Context) ([]entity.Question, error)
 
-	DeleteQuestion(ctx context.Context, id int) error
+	// GetQuestionsPage возвращает до limit вопросов с ID больше afterID в порядке ID;
+	// неодобренные видит только автор
+	GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error)
+
+	// DeleteQuestion удаляет вопрос. expectedVersion 0 удаляет любую версию, иначе вопрос удаляется,
+	// только если его версия всё ещё expectedVersion, а если нет — entity.ErrPreconditionFailed
+	DeleteQuestion(ctx context.Context, id, expectedVersion int) error
 }
 
 type questionService struct {
-	repo repository.QuestionRepository
+	repo      repository.QuestionRepository
+	txManager repository.TxManager
+	outbox    repository.OutboxRepository
+	audit     repository.AuditRepository
+	moderator moderation.Moderator
 }
 
-func NewQuestionService(repo repository.QuestionRepository) QuestionService {
-	return &questionService{repo: repo}
+func NewQuestionService(repo repository.QuestionRepository, txManager repository.TxManager, outbox repository.OutboxRepository, audit repository.AuditRepository, moderator moderation.Moderator) QuestionService {
+	return &questionService{
+		repo:      repo,
+		txManager: txManager,
+		outbox:    outbox,
+		audit:     audit,
+		moderator: moderator,
+	}
 }
 
 func (s *questionService) CreateQuestion(ctx context.Context, text string) (*entity.Question, error) {
+	ctx, span := startSpan(ctx, "QuestionService.CreateQuestion")
+	defer span.End()
+
 	if err := ValidateQuestion(text); err != nil {
-		return nil, err
+		return nil, spanError(span, err)
+	}
+	status, reason, err := moderationStatus(ctx, s.moderator, moderation.Content{Kind: moderation.KindQuestion, Text: text})
+	if err != nil {
+		return nil, spanError(span, err)
 	}
 
-	_, err := s.repo.GetByText(ctx, text)
+	_, err = s.repo.GetByText(ctx, text)
 	if err == nil {
-		return nil, entity.ErrQuestionAlreadyExists
+		return nil, spanError(span, entity.ErrQuestionAlreadyExists)
 	}
 
-	question := &entity.Question{Text: text}
-	if err := s.repo.Create(ctx, question); err != nil {
-		return nil, entity.ErrDatabaseQuery
+	question := &entity.Question{
+		Text:             text,
+		AuthorID:         AuditMetaFromContext(ctx).Actor,
+		ModerationStatus: status,
+		ModerationReason: reason,
+	}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.Create(ctx, question); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionCreate, entity.AuditEntityQuestion, question.ID, nil, question); err != nil {
+			return err
+		}
+		// подписчики узнают о вопросе, ожидающем проверки, только после одобрения
+		if !entity.Published(question.ModerationStatus) {
+			return nil
+		}
+		return recordEvent(ctx, s.outbox, events.TypeQuestionCreated, question.ID, question)
+	})
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
 	}
 
 	return question, nil
 }
 
 func (s *questionService) GetQuestion(ctx context.Context, id int) (*entity.Question, error) {
+	ctx, span := startSpan(ctx, "QuestionService.GetQuestion")
+	defer span.End()
+
 	question, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return nil, entity.ErrQuestionNotFound
+		return nil, spanError(span, entity.ErrQuestionNotFound)
 	}
 
 	return question, nil
 }
 
+func (s *questionService) GetQuestionsByIDs(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+	ctx, span := startSpan(ctx, "QuestionService.GetQuestionsByIDs")
+	defer span.End()
+
+	ids, err := uniqueIDs(ids)
+	if err != nil {
+		return nil, nil, spanError(span, err)
+	}
+
+	questions, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	questions, missing := orderByIDs(ids, questions, func(q entity.Question) int { return q.ID })
+	return questions, missing, nil
+}
+
 func (s *questionService) GetAllQuestions(ctx context.Context) ([]entity.Question, error) {
-	questions, err := s.repo.GetAll(ctx)
+	ctx, span := startSpan(ctx, "QuestionService.GetAllQuestions")
+	defer span.End()
+
+	questions, err := s.repo.GetAll(ctx, AuditMetaFromContext(ctx).Actor)
 	if err != nil {
-		return nil, entity.ErrDatabaseQuery
+		return nil, spanError(span, entity.ErrDatabaseQuery)
 	}
 	if questions == nil {
 		return []entity.Question{}, nil
@@ -63,12 +138,67 @@ func (s *questionService) GetAllQuestions(ctx context.Context) ([]entity.Questio
 	return questions, nil
 }
 
-func (s *questionService) DeleteQuestion(ctx context.Context, id int) error {
-	if err := s.repo.Delete(ctx, id); err != nil {
-		if err == entity.ErrQuestionNotFound {
-			return entity.ErrQuestionNotFound
+func (s *questionService) GetQuestionsPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	ctx, span := startSpan(ctx, "QuestionService.GetQuestionsPage")
+	defer span.End()
+
+	questions, err := s.repo.GetPublishedPage(ctx, afterID, limit, AuditMetaFromContext(ctx).Actor)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	if questions == nil {
+		return []entity.Question{}, nil
+	}
+	return questions, nil
+}
+
+func (s *questionService) DeleteQuestion(ctx context.Context, id, expectedVersion int) error {
+	ctx, span := startSpan(ctx, "QuestionService.DeleteQuestion")
+	defer span.End()
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		// снимок для журнала аудита читается в той же транзакции, что и удаление
+		question, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return entity.ErrQuestionNotFound
+			}
+			return err
+		}
+		if err := deleteVersion(ctx, id, expectedVersion, question.Version, s.repo.Delete, s.repo.DeleteVersion); err != nil {
+			return err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionDelete, entity.AuditEntityQuestion, id, question, nil); err != nil {
+			return err
 		}
-		return entity.ErrDatabaseQuery
+		return recordEvent(ctx, s.outbox, events.TypeQuestionDeleted, id, map[string]int{"id": id})
+	})
+	if err != nil {
+		var customErr entity.CustomError
+		if errors.As(err, &customErr) {
+			return spanError(span, customErr)
+		}
+		return spanError(span, entity.ErrDatabaseQuery)
+	}
+	return nil
+}
+
+// deleteVersion удаляет запись условно, если передана ожидаемая версия: проверка в обработчике
+// и удаление не атомарны, и запись могли изменить между ними. current — версия, прочитанная перед удалением
+func deleteVersion(ctx context.Context, id, expected, current int, remove func(ctx context.Context, id int) error,
+	removeVersion func(ctx context.Context, id, version int) (bool, error)) error {
+	if expected == 0 {
+		return remove(ctx, id)
+	}
+	if current != expected {
+		return entity.ErrPreconditionFailed
+	}
+	ok, err := removeVersion(ctx, id, expected)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return entity.ErrPreconditionFailed
 	}
 	return nil
 }
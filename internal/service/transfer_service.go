@@ -0,0 +1,649 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+const (
+	TransferFormatJSONL = "jsonl"
+	TransferFormatCSV   = "csv"
+
+	exportBatchSize        = 500
+	defaultImportBatchSize = 500
+	importMaxLineSize      = 1 << 20
+	importMaxReportErrors  = 1000
+)
+
+var csvHeader = []string{
+	"question_id", "question_text", "question_created_at",
+	"answer_id", "answer_user_id", "answer_text", "answer_created_at",
+}
+
+// TransferRecord вопрос с ответами — одна строка JSONL. В CSV вопрос занимает
+// по строке на каждый ответ, вопрос без ответов — одну строку с пустыми полями ответа
+type TransferRecord struct {
+	ID        int              `json:"id,omitempty"`
+	Text      string           `json:"text"`
+	CreatedAt time.Time        `json:"created_at,omitempty"`
+	Answers   []TransferAnswer `json:"answers"`
+}
+
+type TransferAnswer struct {
+	ID        int       `json:"id,omitempty"`
+	UserID    string    `json:"user_id"`
+	Text      string    `json:"text"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// ImportOptions DryRun выполняет импорт в транзакциях и откатывает их.
+// Upsert сопоставляет вопросы по тексту и добавляет к ним только новые ответы
+type ImportOptions struct {
+	DryRun    bool
+	Upsert    bool
+	BatchSize int
+}
+
+type ImportError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportReport struct {
+	DryRun           bool          `json:"dry_run"`
+	Records          int           `json:"records"`
+	Failed           int           `json:"failed"`
+	QuestionsCreated int           `json:"questions_created"`
+	QuestionsMatched int           `json:"questions_matched"`
+	AnswersCreated   int           `json:"answers_created"`
+	AnswersSkipped   int           `json:"answers_skipped"`
+	Errors           []ImportError `json:"errors"`
+}
+
+type TransferService interface {
+	// Export пишет опубликованные вопросы с опубликованными ответами в w по мере чтения из БД.
+	// Записи на модерации и отклонённые не выгружаются: выгрузка публичная
+	Export(ctx context.Context, format string, w io.Writer) error
+
+	// Import загружает записи пачками по BatchSize, каждая пачка — отдельная транзакция.
+	// Ошибки отдельных записей попадают в отчёт и не прерывают импорт
+	Import(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*ImportReport, error)
+}
+
+type transferService struct {
+	questionRepo repository.QuestionRepository
+	answerRepo   repository.AnswerRepository
+	txManager    repository.TxManager
+	outbox       repository.OutboxRepository
+	audit        repository.AuditRepository
+	moderator    moderation.Moderator
+	invalidator  CacheInvalidator
+}
+
+// NewTransferService invalidator nil, если кэш выключен
+func NewTransferService(questionRepo repository.QuestionRepository, answerRepo repository.AnswerRepository, txManager repository.TxManager, outbox repository.OutboxRepository, audit repository.AuditRepository, moderator moderation.Moderator, invalidator CacheInvalidator) TransferService {
+	return &transferService{
+		questionRepo: questionRepo,
+		answerRepo:   answerRepo,
+		txManager:    txManager,
+		outbox:       outbox,
+		audit:        audit,
+		moderator:    moderator,
+		invalidator:  invalidator,
+	}
+}
+
+func ValidateTransferFormat(format string) error {
+	if format != TransferFormatJSONL && format != TransferFormatCSV {
+		return entity.ErrInvalidTransferFormat
+	}
+	return nil
+}
+
+func (s *transferService) Export(ctx context.Context, format string, w io.Writer) error {
+	ctx, span := startSpan(ctx, "TransferService.Export")
+	defer span.End()
+
+	if err := ValidateTransferFormat(format); err != nil {
+		return spanError(span, err)
+	}
+
+	writer := newRecordWriter(format, w)
+	afterID := 0
+	for {
+		questions, err := s.questionRepo.GetPublishedPage(ctx, afterID, exportBatchSize, "")
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		if len(questions) == 0 {
+			break
+		}
+
+		ids := make([]int, len(questions))
+		for i, q := range questions {
+			ids[i] = q.ID
+		}
+		answers, err := s.answerRepo.GetPublishedByQuestionIDs(ctx, ids, "")
+		if err != nil {
+			return spanError(span, entity.ErrDatabaseQuery)
+		}
+		byQuestion := make(map[int][]TransferAnswer, len(questions))
+		for _, a := range answers {
+			byQuestion[a.QuestionID] = append(byQuestion[a.QuestionID], TransferAnswer{
+				ID:        a.ID,
+				UserID:    a.UserID,
+				Text:      a.Text,
+				CreatedAt: a.CreatedAt,
+			})
+		}
+
+		for _, q := range questions {
+			record := TransferRecord{ID: q.ID, Text: q.Text, CreatedAt: q.CreatedAt, Answers: byQuestion[q.ID]}
+			if record.Answers == nil {
+				record.Answers = []TransferAnswer{}
+			}
+			if err := writer.Write(record); err != nil {
+				return spanError(span, err)
+			}
+		}
+		afterID = questions[len(questions)-1].ID
+	}
+
+	if err := writer.Flush(); err != nil {
+		return spanError(span, err)
+	}
+	return nil
+}
+
+// errDryRun откатывает транзакцию пачки в режиме dry-run
+var errDryRun = errors.New("dry run")
+
+// numberedRecord запись с номером строки; err — ошибка разбора, которая попадёт в отчёт по порядку строк
+type numberedRecord struct {
+	line   int
+	record TransferRecord
+	err    error
+}
+
+func (s *transferService) Import(ctx context.Context, format string, r io.Reader, opts ImportOptions) (*ImportReport, error) {
+	ctx, span := startSpan(ctx, "TransferService.Import")
+	defer span.End()
+
+	if err := ValidateTransferFormat(format); err != nil {
+		return nil, spanError(span, err)
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = defaultImportBatchSize
+	}
+
+	report := &ImportReport{DryRun: opts.DryRun, Errors: []ImportError{}}
+	reader := newRecordReader(format, r)
+	batch := make([]numberedRecord, 0, opts.BatchSize)
+
+	for {
+		record, line, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		report.Records++
+		var formatErr *recordFormatError
+		if err != nil && !errors.As(err, &formatErr) {
+			return report, spanError(span, err)
+		}
+
+		batch = append(batch, numberedRecord{line: line, record: record, err: err})
+		if len(batch) == opts.BatchSize {
+			if err := s.importBatch(ctx, batch, opts, report); err != nil {
+				return report, spanError(span, err)
+			}
+			batch = batch[:0]
+		}
+	}
+
+	if err := s.importBatch(ctx, batch, opts, report); err != nil {
+		return report, spanError(span, err)
+	}
+	return report, nil
+}
+
+// importBatch импортирует пачку в одной транзакции. Каждая запись выполняется в savepoint,
+// поэтому ошибочная запись откатывается без потери остальных. После коммита сбрасывается кэш
+// созданных вопросов и вопросов, к которым добавлены ответы
+func (s *transferService) importBatch(ctx context.Context, batch []numberedRecord, opts ImportOptions, report *ImportReport) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	batchReport := &ImportReport{}
+	var createdQuestions, answeredQuestions []int
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		createdQuestions, answeredQuestions = nil, nil
+		for _, item := range batch {
+			if item.err != nil {
+				batchReport.addError(item.line, item.err)
+				continue
+			}
+			recordReport := &ImportReport{}
+			var questionID int
+			err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				var err error
+				questionID, err = s.importRecord(ctx, item.record, opts.Upsert, recordReport)
+				return err
+			})
+			if err != nil {
+				batchReport.addError(item.line, err)
+				continue
+			}
+			batchReport.merge(recordReport)
+			switch {
+			case recordReport.QuestionsCreated > 0:
+				createdQuestions = append(createdQuestions, questionID)
+			case recordReport.AnswersCreated > 0:
+				answeredQuestions = append(answeredQuestions, questionID)
+			}
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		log.Printf("Ошибка импорта пачки: %v", err)
+		return entity.ErrDatabaseQuery
+	}
+
+	report.merge(batchReport)
+	if err == nil && s.invalidator != nil {
+		for _, id := range createdQuestions {
+			s.invalidator.InvalidateQuestion(ctx, id)
+		}
+		for _, id := range answeredQuestions {
+			s.invalidator.InvalidateAnswers(ctx, id)
+		}
+	}
+	return nil
+}
+
+// importRecord сохраняет вопрос и ответы со статусом модерации, как при создании через API: отклонённая запись
+// попадает в отчёт, задержанная сохраняется на модерации. Возвращает ID созданного или найденного вопроса
+func (s *transferService) importRecord(ctx context.Context, record TransferRecord, upsert bool, report *ImportReport) (int, error) {
+	if err := ValidateQuestion(record.Text); err != nil {
+		return 0, err
+	}
+	questionStatus, questionReason, err := moderationStatus(ctx, s.moderator, moderation.Content{Kind: moderation.KindQuestion, Text: record.Text})
+	if err != nil {
+		return 0, err
+	}
+	answerStatuses := make([]string, len(record.Answers))
+	answerReasons := make([]string, len(record.Answers))
+	for i, a := range record.Answers {
+		if err := ValidateAnswer(a.UserID, a.Text); err != nil {
+			return 0, fmt.Errorf("ответ %d: %w", i+1, err)
+		}
+		answerStatuses[i], answerReasons[i], err = moderationStatus(ctx, s.moderator, moderation.Content{Kind: moderation.KindAnswer, UserID: a.UserID, Text: a.Text})
+		if err != nil {
+			return 0, fmt.Errorf("ответ %d: %w", i+1, err)
+		}
+	}
+
+	question, err := s.questionRepo.GetByText(ctx, record.Text)
+	existing := map[string]struct{}{}
+	switch {
+	case err == nil && !upsert:
+		return 0, entity.ErrQuestionAlreadyExists
+	case err == nil:
+		// вопрос на модерации или отклонённый скрыт от всех, кроме автора, как и при ответе через API
+		if !entity.Published(question.ModerationStatus) {
+			return 0, entity.ErrImportQuestionNotPublished
+		}
+		report.QuestionsMatched++
+		answers, err := s.answerRepo.GetByQuestionID(ctx, question.ID)
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range answers {
+			existing[a.UserID+"\x00"+a.Text] = struct{}{}
+		}
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		question = &entity.Question{
+			Text:             record.Text,
+			CreatedAt:        record.CreatedAt,
+			ModerationStatus: questionStatus,
+			ModerationReason: questionReason,
+		}
+		if err := s.questionRepo.Create(ctx, question); err != nil {
+			return 0, err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionCreate, entity.AuditEntityQuestion, question.ID, nil, question); err != nil {
+			return 0, err
+		}
+		// подписчики узнают о записях на модерации только после одобрения
+		if entity.Published(question.ModerationStatus) {
+			if err := recordEvent(ctx, s.outbox, events.TypeQuestionCreated, question.ID, question); err != nil {
+				return 0, err
+			}
+		}
+		report.QuestionsCreated++
+	default:
+		return 0, err
+	}
+
+	for i, a := range record.Answers {
+		key := a.UserID + "\x00" + a.Text
+		if _, ok := existing[key]; ok {
+			report.AnswersSkipped++
+			continue
+		}
+		existing[key] = struct{}{}
+
+		answer, err := s.answerRepo.Create(ctx, &entity.Answer{
+			QuestionID:       question.ID,
+			UserID:           a.UserID,
+			Text:             a.Text,
+			CreatedAt:        a.CreatedAt,
+			ModerationStatus: answerStatuses[i],
+			ModerationReason: answerReasons[i],
+		})
+		if err != nil {
+			return 0, err
+		}
+		if err := recordAudit(ctx, s.audit, entity.AuditActionCreate, entity.AuditEntityAnswer, answer.ID, nil, answer); err != nil {
+			return 0, err
+		}
+		if entity.Published(answer.ModerationStatus) {
+			if err := recordEvent(ctx, s.outbox, events.TypeAnswerCreated, question.ID, answer); err != nil {
+				return 0, err
+			}
+		}
+		report.AnswersCreated++
+	}
+	return question.ID, nil
+}
+
+func (r *ImportReport) merge(other *ImportReport) {
+	r.QuestionsCreated += other.QuestionsCreated
+	r.QuestionsMatched += other.QuestionsMatched
+	r.AnswersCreated += other.AnswersCreated
+	r.AnswersSkipped += other.AnswersSkipped
+	r.Failed += other.Failed
+	for _, e := range other.Errors {
+		if len(r.Errors) < importMaxReportErrors {
+			r.Errors = append(r.Errors, e)
+		}
+	}
+}
+
+// addError добавляет ошибку записи; внутренние ошибки БД не раскрываются в отчёте
+func (r *ImportReport) addError(line int, err error) {
+	r.Failed++
+	if len(r.Errors) >= importMaxReportErrors {
+		return
+	}
+
+	message := err.Error()
+	var customErr entity.CustomError
+	var formatErr *recordFormatError
+	if !errors.As(err, &customErr) && !errors.As(err, &formatErr) {
+		log.Printf("Ошибка импорта строки %d: %v", line, err)
+		message = entity.ErrDatabaseQuery.Message
+	}
+	r.Errors = append(r.Errors, ImportError{Line: line, Error: message})
+}
+
+// recordFormatError ошибка разбора отдельной записи; импорт продолжается со следующей
+type recordFormatError struct {
+	message string
+}
+
+func (e *recordFormatError) Error() string {
+	return e.message
+}
+
+type recordWriter interface {
+	Write(record TransferRecord) error
+	Flush() error
+}
+
+func newRecordWriter(format string, w io.Writer) recordWriter {
+	if format == TransferFormatCSV {
+		return &csvRecordWriter{w: csv.NewWriter(w)}
+	}
+	return &jsonlRecordWriter{enc: json.NewEncoder(w)}
+}
+
+type jsonlRecordWriter struct {
+	enc *json.Encoder
+}
+
+func (w *jsonlRecordWriter) Write(record TransferRecord) error {
+	return w.enc.Encode(record)
+}
+
+func (w *jsonlRecordWriter) Flush() error {
+	return nil
+}
+
+type csvRecordWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (w *csvRecordWriter) Write(record TransferRecord) error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvHeader); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	question := []string{strconv.Itoa(record.ID), record.Text, formatTime(record.CreatedAt)}
+	if len(record.Answers) == 0 {
+		return w.w.Write(append(question, "", "", "", ""))
+	}
+	for _, a := range record.Answers {
+		row := append(question[:3:3], strconv.Itoa(a.ID), a.UserID, a.Text, formatTime(a.CreatedAt))
+		if err := w.w.Write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *csvRecordWriter) Flush() error {
+	if !w.headerWritten {
+		if err := w.w.Write(csvHeader); err != nil {
+			return err
+		}
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// recordReader возвращает записи с номером строки, где запись начинается, и io.EOF в конце
+type recordReader interface {
+	Next() (TransferRecord, int, error)
+}
+
+func newRecordReader(format string, r io.Reader) recordReader {
+	if format == TransferFormatCSV {
+		reader := csv.NewReader(r)
+		reader.FieldsPerRecord = -1
+		return &csvRecordReader{r: reader}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), importMaxLineSize)
+	return &jsonlRecordReader{scanner: scanner}
+}
+
+type jsonlRecordReader struct {
+	scanner *bufio.Scanner
+	line    int
+	failed  bool
+}
+
+func (r *jsonlRecordReader) Next() (TransferRecord, int, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := r.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var record TransferRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return TransferRecord{}, r.line, &recordFormatError{message: "Некорректный формат JSON: " + err.Error()}
+		}
+		return record, r.line, nil
+	}
+	if err := r.scanner.Err(); err != nil && !r.failed {
+		r.failed = true
+		if errors.Is(err, bufio.ErrTooLong) {
+			return TransferRecord{}, r.line + 1, &recordFormatError{message: fmt.Sprintf("Строка длиннее %d байт", importMaxLineSize)}
+		}
+		return TransferRecord{}, r.line + 1, err
+	}
+	return TransferRecord{}, 0, io.EOF
+}
+
+// csvRecordReader собирает подряд идущие строки одного вопроса в одну запись.
+// Строки относятся к одному вопросу, если у них совпадают question_id и question_text
+type csvRecordReader struct {
+	r       *csv.Reader
+	columns map[string]int
+	pending *csvRow
+}
+
+type csvRow struct {
+	fields []string
+	line   int
+	err    error
+}
+
+func (r *csvRecordReader) Next() (TransferRecord, int, error) {
+	if r.columns == nil {
+		header, err := r.r.Read()
+		if err == io.EOF {
+			return TransferRecord{}, 0, io.EOF
+		}
+		if err != nil {
+			return TransferRecord{}, 1, err
+		}
+		r.columns = make(map[string]int, len(header))
+		for i, name := range header {
+			r.columns[name] = i
+		}
+		if _, ok := r.columns["question_text"]; !ok {
+			return TransferRecord{}, 1, entity.ErrInvalidCSVHeader
+		}
+	}
+
+	row := r.nextRow()
+	if row.err != nil {
+		return TransferRecord{}, row.line, row.err
+	}
+
+	key := r.questionKey(row)
+	record := TransferRecord{Text: r.field(row, "question_text"), Answers: []TransferAnswer{}}
+	var err error
+	if record.CreatedAt, err = r.time(row, "question_created_at"); err != nil {
+		r.skipQuestion(key)
+		return TransferRecord{}, row.line, err
+	}
+
+	for {
+		if r.field(row, "answer_user_id") != "" || r.field(row, "answer_text") != "" {
+			answer := TransferAnswer{UserID: r.field(row, "answer_user_id"), Text: r.field(row, "answer_text")}
+			if answer.CreatedAt, err = r.time(row, "answer_created_at"); err != nil {
+				r.skipQuestion(key)
+				return TransferRecord{}, row.line, err
+			}
+			record.Answers = append(record.Answers, answer)
+		}
+
+		next := r.nextRow()
+		if next.err == io.EOF {
+			return record, row.line, nil
+		}
+		if next.err != nil || r.questionKey(next) != key {
+			// строка относится к следующей записи или содержит ошибку — вернём её при следующем вызове
+			r.pending = next
+			return record, row.line, nil
+		}
+		row = next
+	}
+}
+
+func (r *csvRecordReader) nextRow() *csvRow {
+	if r.pending != nil {
+		row := r.pending
+		r.pending = nil
+		return row
+	}
+
+	fields, err := r.r.Read()
+	if err == io.EOF {
+		return &csvRow{err: io.EOF}
+	}
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &csvRow{line: parseErr.StartLine, err: &recordFormatError{message: "Некорректная строка CSV: " + parseErr.Err.Error()}}
+	}
+	line, _ := r.r.FieldPos(0)
+	return &csvRow{fields: fields, line: line, err: err}
+}
+
+// skipQuestion пропускает оставшиеся строки вопроса с ошибкой
+func (r *csvRecordReader) skipQuestion(key string) {
+	for {
+		row := r.nextRow()
+		if row.err != nil || r.questionKey(row) != key {
+			r.pending = row
+			return
+		}
+	}
+}
+
+func (r *csvRecordReader) questionKey(row *csvRow) string {
+	return r.field(row, "question_id") + "\x00" + r.field(row, "question_text")
+}
+
+func (r *csvRecordReader) field(row *csvRow, name string) string {
+	i, ok := r.columns[name]
+	if !ok || i >= len(row.fields) {
+		return ""
+	}
+	return row.fields[i]
+}
+
+func (r *csvRecordReader) time(row *csvRow, name string) (time.Time, error) {
+	value := r.field(row, name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, value)
+	if err != nil {
+		return time.Time{}, &recordFormatError{message: fmt.Sprintf("Некорректная дата в колонке %s: %s", name, value)}
+	}
+	return t, nil
+}
@@ -0,0 +1,277 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// memoryTransferStore хранит вопросы и ответы в памяти; WithinTx откатывает изменения при ошибке
+type memoryTransferStore struct {
+	questions []entity.Question
+	answers   []entity.Answer
+}
+
+func (s *memoryTransferStore) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	questions := append([]entity.Question(nil), s.questions...)
+	answers := append([]entity.Answer(nil), s.answers...)
+	if err := fn(ctx); err != nil {
+		s.questions, s.answers = questions, answers
+		return err
+	}
+	return nil
+}
+
+type memoryTransferQuestionRepository struct {
+	repository.QuestionRepository
+	store *memoryTransferStore
+}
+
+func (r *memoryTransferQuestionRepository) Create(ctx context.Context, question *entity.Question) error {
+	question.ID = len(r.store.questions) + 1
+	r.store.questions = append(r.store.questions, *question)
+	return nil
+}
+
+func (r *memoryTransferQuestionRepository) GetByText(ctx context.Context, text string) (*entity.Question, error) {
+	for _, q := range r.store.questions {
+		if q.Text == text {
+			return &q, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memoryTransferQuestionRepository) GetPage(ctx context.Context, afterID, limit int) ([]entity.Question, error) {
+	var page []entity.Question
+	for _, q := range r.store.questions {
+		if q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+func (r *memoryTransferQuestionRepository) GetPublishedPage(ctx context.Context, afterID, limit int, viewerID string) ([]entity.Question, error) {
+	var page []entity.Question
+	for _, q := range r.store.questions {
+		if (entity.Published(q.ModerationStatus) || q.AuthorID == viewerID && viewerID != "") && q.ID > afterID && len(page) < limit {
+			page = append(page, q)
+		}
+	}
+	return page, nil
+}
+
+type memoryTransferAnswerRepository struct {
+	repository.AnswerRepository
+	store *memoryTransferStore
+}
+
+func (r *memoryTransferAnswerRepository) Create(ctx context.Context, answer *entity.Answer) (*entity.Answer, error) {
+	answer.ID = len(r.store.answers) + 1
+	r.store.answers = append(r.store.answers, *answer)
+	return answer, nil
+}
+
+func (r *memoryTransferAnswerRepository) GetByQuestionID(ctx context.Context, questionID int) ([]entity.Answer, error) {
+	return r.GetByQuestionIDs(ctx, []int{questionID})
+}
+
+func (r *memoryTransferAnswerRepository) GetByQuestionIDs(ctx context.Context, questionIDs []int) ([]entity.Answer, error) {
+	var result []entity.Answer
+	for _, a := range r.store.answers {
+		for _, id := range questionIDs {
+			if a.QuestionID == id {
+				result = append(result, a)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryTransferAnswerRepository) GetPublishedByQuestionIDs(ctx context.Context, questionIDs []int, viewerID string) ([]entity.Answer, error) {
+	answers, _ := r.GetByQuestionIDs(ctx, questionIDs)
+	var result []entity.Answer
+	for _, a := range answers {
+		if entity.Published(a.ModerationStatus) || a.UserID == viewerID && viewerID != "" {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func newMemoryTransferService() (*memoryTransferStore, TransferService) {
+	store := &memoryTransferStore{}
+	return store, NewTransferService(
+		&memoryTransferQuestionRepository{store: store},
+		&memoryTransferAnswerRepository{store: store},
+		store,
+		nil,
+		nil,
+		nil,
+		nil,
+	)
+}
+
+func TestTransferService_ImportJSONLReportsLineErrors(t *testing.T) {
+	store, svc := newMemoryTransferService()
+	input := strings.Join([]string{
+		`{"text": "What is Go?", "answers": [{"user_id": "u1", "text": "A language"}]}`,
+		``,
+		`{"text": "   "}`,
+		`{"text": "What is GORM?", "answers": [{"user_id": "", "text": "An ORM"}]}`,
+		`not json`,
+		`{"text": "What is Go?"}`,
+	}, "\n")
+
+	report, err := svc.Import(context.Background(), TransferFormatJSONL, strings.NewReader(input), ImportOptions{BatchSize: 2})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Records != 5 || report.Failed != 4 || report.QuestionsCreated != 1 || report.AnswersCreated != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	wantLines := []int{3, 4, 5, 6}
+	if len(report.Errors) != len(wantLines) {
+		t.Fatalf("expected %d errors, got %+v", len(wantLines), report.Errors)
+	}
+	for i, line := range wantLines {
+		if report.Errors[i].Line != line {
+			t.Errorf("expected error on line %d, got %+v", line, report.Errors[i])
+		}
+	}
+	if report.Errors[1].Error != "ответ 1: "+entity.ErrInvalidUserID.Message {
+		t.Errorf("unexpected error message: %s", report.Errors[1].Error)
+	}
+	// запись с ошибкой ответа откатывается целиком
+	if len(store.questions) != 1 || len(store.answers) != 1 {
+		t.Errorf("expected 1 question and 1 answer, got %d and %d", len(store.questions), len(store.answers))
+	}
+}
+
+func TestTransferService_ImportCSVUpsertAndDryRun(t *testing.T) {
+	store, svc := newMemoryTransferService()
+	input := "question_id,question_text,question_created_at,answer_id,answer_user_id,answer_text,answer_created_at\n" +
+		"1,What is Go?,2025-01-01T00:00:00Z,1,u1,A language,2025-01-02T00:00:00Z\n" +
+		"1,What is Go?,2025-01-01T00:00:00Z,2,u2,From Google,\n" +
+		"2,What is GORM?,,,,,\n"
+
+	report, err := svc.Import(context.Background(), TransferFormatCSV, strings.NewReader(input), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Records != 2 || report.QuestionsCreated != 2 || report.AnswersCreated != 2 {
+		t.Errorf("unexpected dry-run report: %+v", report)
+	}
+	if len(store.questions) != 0 {
+		t.Errorf("expected dry-run to roll back, got %d questions", len(store.questions))
+	}
+
+	if _, err := svc.Import(context.Background(), TransferFormatCSV, strings.NewReader(input), ImportOptions{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := store.questions[0].CreatedAt.Format("2006-01-02"); got != "2025-01-01" {
+		t.Errorf("expected original created_at, got %s", got)
+	}
+
+	report, err = svc.Import(context.Background(), TransferFormatCSV, strings.NewReader(input), ImportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed != 2 {
+		t.Errorf("expected duplicates to fail without upsert, got %+v", report)
+	}
+
+	report, err = svc.Import(context.Background(), TransferFormatCSV, strings.NewReader(input), ImportOptions{Upsert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.QuestionsMatched != 2 || report.AnswersSkipped != 2 || report.AnswersCreated != 0 {
+		t.Errorf("unexpected upsert report: %+v", report)
+	}
+}
+
+func TestTransferService_ImportModeratesAndInvalidatesCache(t *testing.T) {
+	store := &memoryTransferStore{}
+	invalidator := &recordingInvalidator{}
+	moderator := moderation.NewDefaultChain(moderation.Policy{BannedWords: []string{"дурак"}, MaxLinks: 1, MaxRepeatedChars: 5})
+	svc := NewTransferService(&memoryTransferQuestionRepository{store: store}, &memoryTransferAnswerRepository{store: store}, store, nil, nil, moderator, invalidator)
+
+	input := strings.Join([]string{
+		`{"text": "Что почитать про Go?", "answers": [{"user_id": "u1", "text": "Смотри https://a.example и https://b.example"}]}`,
+		`{"text": "Смотри https://a.example и https://b.example"}`,
+		`{"text": "Спроси у дураков"}`,
+	}, "\n")
+	report, err := svc.Import(context.Background(), TransferFormatJSONL, strings.NewReader(input), ImportOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.QuestionsCreated != 2 || report.Failed != 1 || len(store.questions) != 2 {
+		t.Fatalf("expected held records imported and rejected one reported, got %+v", report)
+	}
+	if store.answers[0].ModerationStatus != entity.ModerationPending || store.questions[1].ModerationStatus != entity.ModerationPending {
+		t.Errorf("expected held answer and question pending, got %+v and %+v", store.answers[0], store.questions[1])
+	}
+	if len(invalidator.questions) != 2 {
+		t.Errorf("expected cache of both created questions invalidated, got %v", invalidator.questions)
+	}
+
+	// к вопросу на модерации ответы не добавляются, к опубликованному — добавляются со сбросом кэша ответов
+	input = strings.Join([]string{
+		`{"text": "Смотри https://a.example и https://b.example", "answers": [{"user_id": "u2", "text": "Ответ"}]}`,
+		`{"text": "Что почитать про Go?", "answers": [{"user_id": "u2", "text": "Effective Go"}]}`,
+	}, "\n")
+	report, err = svc.Import(context.Background(), TransferFormatJSONL, strings.NewReader(input), ImportOptions{Upsert: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed != 1 || report.Errors[0].Error != entity.ErrImportQuestionNotPublished.Message || report.AnswersCreated != 1 {
+		t.Errorf("expected pending question reported and one answer added, got %+v", report)
+	}
+	if len(invalidator.answers) != 1 || invalidator.answers[0] != store.questions[0].ID {
+		t.Errorf("expected answers of question %d invalidated, got %v", store.questions[0].ID, invalidator.answers)
+	}
+}
+
+func TestTransferService_ExportRoundTrip(t *testing.T) {
+	for _, format := range []string{TransferFormatJSONL, TransferFormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			source, sourceSvc := newMemoryTransferService()
+			source.questions = []entity.Question{
+				{ID: 1, Text: "What is Go?"},
+				{ID: 2, Text: "Multi\nline, \"quoted\""},
+				{ID: 3, Text: "Pending", ModerationStatus: entity.ModerationPending},
+			}
+			source.answers = []entity.Answer{
+				{ID: 1, QuestionID: 1, UserID: "u1", Text: "A language"},
+				{ID: 2, QuestionID: 1, UserID: "u2", Text: "From Google"},
+				{ID: 3, QuestionID: 1, UserID: "u3", Text: "Rejected", ModerationStatus: entity.ModerationRejected},
+			}
+
+			var buf bytes.Buffer
+			if err := sourceSvc.Export(context.Background(), format, &buf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			target, targetSvc := newMemoryTransferService()
+			report, err := targetSvc.Import(context.Background(), format, &buf, ImportOptions{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if report.Failed != 0 || len(target.questions) != 2 || len(target.answers) != 2 {
+				t.Errorf("expected round trip of published records only, got report %+v", report)
+			}
+			if target.questions[1].Text != source.questions[1].Text {
+				t.Errorf("expected text %q, got %q", source.questions[1].Text, target.questions[1].Text)
+			}
+		})
+	}
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
+	"log"
 	"strings"
 
 	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/moderation"
 )
 
 func ValidateQuestion(text string) error {
@@ -14,7 +17,15 @@ func ValidateQuestion(text string) error {
 }
 
 func ValidateAnswer(userID, text string) error {
-	// TODO: реализовать валидацию (userID не пустой, текст не пустой)
+	if strings.TrimSpace(userID) == "" {
+		return entity.ErrInvalidUserID
+	}
+	if userID == entity.ErasedUserID {
+		return entity.ErrReservedUserID
+	}
+	if strings.TrimSpace(text) == "" {
+		return entity.ErrInvalidAnswerText
+	}
 	return nil
 }
 
@@ -24,3 +35,38 @@ func ValidateQuestionNotNil(question *entity.Question) error {
 	}
 	return nil
 }
+
+// runModeration прогоняет текст через цепочку модерации; moderator nil — модерация выключена
+func runModeration(ctx context.Context, moderator moderation.Moderator, content moderation.Content) moderation.Decision {
+	if moderator == nil {
+		return moderation.Decision{Action: moderation.Allow}
+	}
+	decision := moderator.Moderate(ctx, content)
+	if decision.Action != moderation.Allow {
+		log.Printf("Модерация: %s %s проверкой %s: %s", content.Kind, decision.Action, decision.Check, decision.Err.Message)
+	}
+	return decision
+}
+
+// moderate отклоняет и отклонённый текст, и отложенный на проверку. Используется при импорте:
+// у импортируемых записей нет автора, которому модератор мог бы сообщить решение
+func moderate(ctx context.Context, moderator moderation.Moderator, content moderation.Content) error {
+	decision := runModeration(ctx, moderator, content)
+	if decision.Action == moderation.Allow {
+		return nil
+	}
+	return decision.Err
+}
+
+// moderationStatus возвращает статус, с которым сохраняется текст: одобренный сразу или ожидающий
+// ручной проверки с причиной. Отклонённый текст не сохраняется, автору возвращается ошибка
+func moderationStatus(ctx context.Context, moderator moderation.Moderator, content moderation.Content) (status, reason string, err error) {
+	decision := runModeration(ctx, moderator, content)
+	switch decision.Action {
+	case moderation.Allow:
+		return entity.ModerationApproved, "", nil
+	case moderation.Hold:
+		return entity.ModerationPending, decision.Err.Message, nil
+	}
+	return "", "", decision.Err
+}
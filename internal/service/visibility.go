@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+)
+
+type visibleQuestionService struct {
+	QuestionService
+}
+
+type visibleAnswerService struct {
+	AnswerService
+}
+
+// NewVisibleServices скрывает вопросы и ответы, не одобренные модератором, от всех, кроме автора:
+// для остальных их нет. Оборачивает сервисы поверх кэша, потому что результат зависит от того,
+// кто спрашивает, а кэш общий. Списки фильтруются в репозитории по пользователю из контекста,
+// а кэш списков обходится для авторизованных запросов
+func NewVisibleServices(questionService QuestionService, answerService AnswerService) (QuestionService, AnswerService) {
+	return &visibleQuestionService{QuestionService: questionService},
+		&visibleAnswerService{AnswerService: answerService}
+}
+
+// visibleTo виден ли текст со статусом status пользователю из контекста запроса
+func visibleTo(ctx context.Context, status, authorID string) bool {
+	if entity.Published(status) {
+		return true
+	}
+	return authorID != "" && AuditMetaFromContext(ctx).Actor == authorID
+}
+
+// hideInvisible убирает скрытое из найденного и заново собирает missing, чтобы скрытые ID
+// встали в порядке запроса вместе с ненайденными
+func hideInvisible[T any](ids []int, found []T, idOf func(T) int, visible func(T) bool) ([]T, []int) {
+	kept := make([]T, 0, len(found))
+	for _, item := range found {
+		if visible(item) {
+			kept = append(kept, item)
+		}
+	}
+	// размер пакета уже проверен обёрнутым сервисом
+	ids, _ = uniqueIDs(ids)
+	return orderByIDs(ids, kept, idOf)
+}
+
+func (s *visibleQuestionService) GetQuestion(ctx context.Context, id int) (*entity.Question, error) {
+	question, err := s.QuestionService.GetQuestion(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, question.ModerationStatus, question.AuthorID) {
+		return nil, entity.ErrQuestionNotFound
+	}
+	return question, nil
+}
+
+func (s *visibleQuestionService) GetQuestionsByIDs(ctx context.Context, ids []int) ([]entity.Question, []int, error) {
+	questions, _, err := s.QuestionService.GetQuestionsByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	questions, missing := hideInvisible(ids, questions, func(q entity.Question) int { return q.ID }, func(q entity.Question) bool {
+		return visibleTo(ctx, q.ModerationStatus, q.AuthorID)
+	})
+	return questions, missing, nil
+}
+
+func (s *visibleAnswerService) GetAnswer(ctx context.Context, id int) (*entity.Answer, error) {
+	answer, err := s.AnswerService.GetAnswer(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !visibleTo(ctx, answer.ModerationStatus, answer.UserID) {
+		return nil, entity.ErrAnswerNotFound
+	}
+	return answer, nil
+}
+
+func (s *visibleAnswerService) GetAnswersByIDs(ctx context.Context, ids []int) ([]entity.Answer, []int, error) {
+	answers, _, err := s.AnswerService.GetAnswersByIDs(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	answers, missing := hideInvisible(ids, answers, func(a entity.Answer) int { return a.ID }, func(a entity.Answer) bool {
+		return visibleTo(ctx, a.ModerationStatus, a.UserID)
+	})
+	return answers, missing, nil
+}
@@ -0,0 +1,520 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+
+	webhookMinSecretLength   = 16
+	webhookDeliveriesLimit   = 100
+	webhookMaxErrorLength    = 1000
+	webhookDeliveryWorkers   = 8
+	webhookResponseReadLimit = 4096
+)
+
+// errWebhookAddressForbidden адрес получателя во внутренней сети. Ошибки транспорта не пишутся
+// в журнал доставок как есть: владелец вебхука не должен узнавать по ним устройство сети сервиса
+var (
+	errWebhookAddressForbidden = errors.New("адрес получателя запрещён")
+	errWebhookUnreachable      = errors.New("получатель недоступен")
+)
+
+type WebhookService interface {
+	// CreateWebhook создаёт подписку. Пустой secret генерируется; секрет возвращается только здесь
+	CreateWebhook(ctx context.Context, ownerID, rawURL string, eventTypes []string, secret string) (*entity.Webhook, error)
+
+	GetWebhooks(ctx context.Context, ownerID string) ([]entity.Webhook, error)
+
+	GetWebhook(ctx context.Context, ownerID string, id int) (*entity.Webhook, error)
+
+	DeleteWebhook(ctx context.Context, ownerID string, id int) error
+
+	// GetDeliveries возвращает журнал доставок; status=dead — dead-letter список
+	GetDeliveries(ctx context.Context, ownerID string, webhookID int, status string) ([]entity.WebhookDelivery, error)
+
+	// RetryDelivery возвращает доставку (в том числе из dead-letter) в очередь
+	RetryDelivery(ctx context.Context, ownerID string, webhookID int, deliveryID int64) (*entity.WebhookDelivery, error)
+
+	// Publish ставит событие в очередь доставки подписанным вебхукам
+	Publish(ctx context.Context, event events.Event) error
+
+	// DeliverDue отправляет доставки, чьё время подошло, и возвращает их количество
+	DeliverDue(ctx context.Context) (int, error)
+}
+
+// WebhookDeliveryPolicy задаёт повторы: задержка перед попыткой n равна
+// BackoffBase * 2^(n-1), но не больше BackoffMax. После MaxAttempts неудач доставка уходит в dead-letter
+type WebhookDeliveryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	Timeout     time.Duration
+	BatchSize   int
+}
+
+type webhookService struct {
+	repo   repository.WebhookRepository
+	client *http.Client
+	policy WebhookDeliveryPolicy
+	now    func() time.Time
+	// lookupHost резолвит хост вебхука при регистрации
+	lookupHost func(ctx context.Context, host string) ([]net.IPAddr, error)
+}
+
+// NewWebhookService nil client заменяется на NewWebhookClient(). Редиректы запрещены для любого
+// клиента: иначе получатель перенаправит запрос на внутренний адрес
+func NewWebhookService(repo repository.WebhookRepository, client *http.Client, policy WebhookDeliveryPolicy) WebhookService {
+	if client == nil {
+		client = NewWebhookClient()
+	}
+	noRedirects := *client
+	noRedirects.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return &webhookService{
+		repo:       repo,
+		client:     &noRedirects,
+		policy:     policy,
+		now:        time.Now,
+		lookupHost: net.DefaultResolver.LookupIPAddr,
+	}
+}
+
+// NewWebhookClient HTTP клиент доставки, который проверяет адрес в момент соединения:
+// проверка при регистрации не защищает от DNS rebinding, когда имя позже начинает указывать внутрь сети
+func NewWebhookClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || forbiddenWebhookIP(ip) {
+				return errWebhookAddressForbidden
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// прокси соединялся бы вместо получателя, и проверка адреса потеряла бы смысл
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Transport: transport}
+}
+
+// forbiddenWebhookNets сети, которых нет среди проверок net.IP: CGNAT 100.64.0.0/10, сеть тестирования
+// 198.18.0.0/15 и NAT64 64:ff9b::/96, через который IPv6 доходит до любого IPv4, в том числе внутреннего
+var forbiddenWebhookNets = mustParseCIDRs("100.64.0.0/10", "198.18.0.0/15", "64:ff9b::/96")
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = ipNet
+	}
+	return nets
+}
+
+// forbiddenWebhookIP адреса, на которые вебхук не может отправлять: loopback, частные сети,
+// link-local (в том числе метаданные облака 169.254.169.254), multicast, 0.0.0.0 и forbiddenWebhookNets
+func forbiddenWebhookIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, ipNet := range forbiddenWebhookNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// SignWebhookPayload считает подпись "sha256=<hex>" от HMAC-SHA256(secret, "<timestamp>.<body>").
+// Получатель должен сверить подпись и отбросить запросы со старым timestamp
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func ValidateWebhook(rawURL string, eventTypes []string, secret string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return entity.ErrInvalidWebhookURL
+	}
+	if len(eventTypes) == 0 {
+		return entity.ErrInvalidWebhookEventTypes
+	}
+	for _, t := range eventTypes {
+		if !events.IsKnownType(t) {
+			return entity.ErrInvalidWebhookEventTypes
+		}
+	}
+	if secret != "" && len(secret) < webhookMinSecretLength {
+		return entity.ErrInvalidWebhookSecret
+	}
+	return nil
+}
+
+func (s *webhookService) CreateWebhook(ctx context.Context, ownerID, rawURL string, eventTypes []string, secret string) (*entity.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.CreateWebhook")
+	defer span.End()
+
+	if ownerID == "" {
+		return nil, spanError(span, entity.ErrUnauthorized)
+	}
+	if err := ValidateWebhook(rawURL, eventTypes, secret); err != nil {
+		return nil, spanError(span, err)
+	}
+	if err := s.checkWebhookHost(ctx, rawURL); err != nil {
+		return nil, spanError(span, err)
+	}
+	if secret == "" {
+		generated := make([]byte, 32)
+		if _, err := rand.Read(generated); err != nil {
+			return nil, spanError(span, err)
+		}
+		secret = hex.EncodeToString(generated)
+	}
+
+	webhook := &entity.Webhook{
+		OwnerID:    ownerID,
+		URL:        rawURL,
+		EventTypes: dedupe(eventTypes),
+		Secret:     secret,
+		Active:     true,
+	}
+	if err := s.repo.Create(ctx, webhook); err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return webhook, nil
+}
+
+// checkWebhookHost отклоняет URL, хост которого резолвится во внутренний адрес. Доставка проверяет адрес
+// ещё раз при соединении (NewWebhookClient)
+func (s *webhookService) checkWebhookHost(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return entity.ErrInvalidWebhookURL
+	}
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if forbiddenWebhookIP(ip) {
+			return entity.ErrForbiddenWebhookURL
+		}
+		return nil
+	}
+	addrs, err := s.lookupHost(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return entity.ErrInvalidWebhookURL
+	}
+	for _, addr := range addrs {
+		if forbiddenWebhookIP(addr.IP) {
+			return entity.ErrForbiddenWebhookURL
+		}
+	}
+	return nil
+}
+
+func (s *webhookService) GetWebhooks(ctx context.Context, ownerID string) ([]entity.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.GetWebhooks")
+	defer span.End()
+
+	webhooks, err := s.repo.GetByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return webhooks, nil
+}
+
+func (s *webhookService) GetWebhook(ctx context.Context, ownerID string, id int) (*entity.Webhook, error) {
+	ctx, span := startSpan(ctx, "WebhookService.GetWebhook")
+	defer span.End()
+
+	webhook, err := s.ownedWebhook(ctx, ownerID, id)
+	if err != nil {
+		return nil, spanError(span, err)
+	}
+	return webhook, nil
+}
+
+func (s *webhookService) DeleteWebhook(ctx context.Context, ownerID string, id int) error {
+	ctx, span := startSpan(ctx, "WebhookService.DeleteWebhook")
+	defer span.End()
+
+	if _, err := s.ownedWebhook(ctx, ownerID, id); err != nil {
+		return spanError(span, err)
+	}
+	if err := s.repo.Delete(ctx, id); err != nil {
+		if err == entity.ErrWebhookNotFound {
+			return spanError(span, entity.ErrWebhookNotFound)
+		}
+		return spanError(span, entity.ErrDatabaseQuery)
+	}
+	return nil
+}
+
+func (s *webhookService) GetDeliveries(ctx context.Context, ownerID string, webhookID int, status string) ([]entity.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookService.GetDeliveries")
+	defer span.End()
+
+	if _, err := s.ownedWebhook(ctx, ownerID, webhookID); err != nil {
+		return nil, spanError(span, err)
+	}
+	deliveries, err := s.repo.GetDeliveries(ctx, webhookID, status, webhookDeliveriesLimit)
+	if err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return deliveries, nil
+}
+
+func (s *webhookService) RetryDelivery(ctx context.Context, ownerID string, webhookID int, deliveryID int64) (*entity.WebhookDelivery, error) {
+	ctx, span := startSpan(ctx, "WebhookService.RetryDelivery")
+	defer span.End()
+
+	if _, err := s.ownedWebhook(ctx, ownerID, webhookID); err != nil {
+		return nil, spanError(span, err)
+	}
+	delivery, err := s.repo.GetDeliveryByID(ctx, deliveryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, spanError(span, entity.ErrWebhookDeliveryNotFound)
+		}
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	if delivery.WebhookID != webhookID {
+		return nil, spanError(span, entity.ErrWebhookDeliveryNotFound)
+	}
+
+	delivery.Status = entity.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = s.now()
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		return nil, spanError(span, entity.ErrDatabaseQuery)
+	}
+	return delivery, nil
+}
+
+func (s *webhookService) Publish(ctx context.Context, event events.Event) error {
+	ctx, span := startSpan(ctx, "WebhookService.Publish")
+	defer span.End()
+
+	webhooks, err := s.repo.GetActiveByEventType(ctx, event.Type)
+	if err != nil {
+		return spanError(span, err)
+	}
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return spanError(span, err)
+	}
+
+	now := s.now()
+	deliveries := make([]entity.WebhookDelivery, len(webhooks))
+	for i, webhook := range webhooks {
+		deliveries[i] = entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       payload,
+			Status:        entity.WebhookDeliveryPending,
+			NextAttemptAt: now,
+		}
+	}
+	if err := s.repo.CreateDeliveries(ctx, deliveries); err != nil {
+		return spanError(span, err)
+	}
+	return nil
+}
+
+func (s *webhookService) DeliverDue(ctx context.Context) (int, error) {
+	ctx, span := startSpan(ctx, "WebhookService.DeliverDue")
+	defer span.End()
+
+	// аренда покрывает таймаут запроса с запасом, иначе другая реплика может отправить доставку повторно
+	lease := 2*s.policy.Timeout + time.Minute
+	deliveries, err := s.repo.ClaimDueDeliveries(ctx, s.now(), lease, s.policy.BatchSize)
+	if err != nil {
+		return 0, spanError(span, err)
+	}
+
+	webhooks := make(map[int]*entity.Webhook)
+	for _, d := range deliveries {
+		if _, ok := webhooks[d.WebhookID]; ok {
+			continue
+		}
+		webhook, err := s.repo.GetByID(ctx, d.WebhookID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, spanError(span, err)
+		}
+		webhooks[d.WebhookID] = webhook
+	}
+
+	sem := make(chan struct{}, webhookDeliveryWorkers)
+	var wg sync.WaitGroup
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		webhook := webhooks[delivery.WebhookID]
+		// вебхук удалён после постановки в очередь, доставки удалятся каскадно
+		if webhook == nil {
+			continue
+		}
+
+		sem <- struct{}{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			s.attempt(ctx, webhook, delivery)
+		}()
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+// attempt выполняет одну попытку доставки и сохраняет её результат
+func (s *webhookService) attempt(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) {
+	statusCode, err := s.send(ctx, webhook, delivery)
+
+	now := s.now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = statusCode
+	delivery.LastError = ""
+
+	switch {
+	case err == nil:
+		delivery.Status = entity.WebhookDeliverySucceeded
+	case delivery.Attempts >= s.policy.MaxAttempts:
+		delivery.Status = entity.WebhookDeliveryDead
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLength)
+		log.Printf("Доставка %d вебхука %d перемещена в dead-letter после %d попыток: %v", delivery.ID, webhook.ID, delivery.Attempts, err)
+	default:
+		delivery.NextAttemptAt = now.Add(s.backoff(delivery.Attempts))
+		delivery.LastError = truncate(err.Error(), webhookMaxErrorLength)
+	}
+
+	if err := s.repo.UpdateDelivery(ctx, delivery); err != nil {
+		log.Printf("Ошибка сохранения доставки %d вебхука %d: %v", delivery.ID, webhook.ID, err)
+	}
+}
+
+func (s *webhookService) send(ctx context.Context, webhook *entity.Webhook, delivery *entity.WebhookDelivery) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.policy.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := s.now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "answer-questions-webhooks/1.0")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		log.Printf("Ошибка доставки %d вебхука %d: %v", delivery.ID, webhook.ID, err)
+		if errors.Is(err, errWebhookAddressForbidden) {
+			return 0, errWebhookAddressForbidden
+		}
+		return 0, errWebhookUnreachable
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseReadLimit))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("получатель ответил %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s *webhookService) backoff(attempts int) time.Duration {
+	delay := s.policy.BackoffBase
+	for i := 1; i < attempts && delay < s.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+	return min(delay, s.policy.BackoffMax)
+}
+
+// ownedWebhook скрывает чужие вебхуки за 404, чтобы не раскрывать их существование
+func (s *webhookService) ownedWebhook(ctx context.Context, ownerID string, id int) (*entity.Webhook, error) {
+	webhook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, entity.ErrWebhookNotFound
+		}
+		return nil, entity.ErrDatabaseQuery
+	}
+	if webhook.OwnerID != ownerID {
+		return nil, entity.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]struct{}, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		if _, ok := seen[v]; ok {
+			continue
+		}
+		seen[v] = struct{}{}
+		result = append(result, v)
+	}
+	return result
+}
+
+// truncate обрезает строку по границе руны: Postgres не примет невалидный UTF-8
+func truncate(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	return s[:limit]
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrey-samosuk/answer-questions/internal/entity"
+	"github.com/andrey-samosuk/answer-questions/internal/events"
+	"github.com/andrey-samosuk/answer-questions/internal/repository"
+)
+
+// memoryWebhookRepository хранит вебхуки и доставки в памяти; ClaimDueDeliveries не учитывает аренду
+type memoryWebhookRepository struct {
+	repository.WebhookRepository
+	mu         sync.Mutex
+	webhooks   map[int]*entity.Webhook
+	deliveries []*entity.WebhookDelivery
+}
+
+func (r *memoryWebhookRepository) GetByID(ctx context.Context, id int) (*entity.Webhook, error) {
+	return r.webhooks[id], nil
+}
+
+func (r *memoryWebhookRepository) Create(ctx context.Context, webhook *entity.Webhook) error {
+	webhook.ID = len(r.webhooks) + 1
+	r.webhooks[webhook.ID] = webhook
+	return nil
+}
+
+func (r *memoryWebhookRepository) GetActiveByEventType(ctx context.Context, eventType string) ([]entity.Webhook, error) {
+	var result []entity.Webhook
+	for _, w := range r.webhooks {
+		if w.Active && w.Subscribed(eventType) {
+			result = append(result, *w)
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryWebhookRepository) CreateDeliveries(ctx context.Context, deliveries []entity.WebhookDelivery) error {
+	for i := range deliveries {
+		d := deliveries[i]
+		d.ID = int64(len(r.deliveries) + 1)
+		r.deliveries = append(r.deliveries, &d)
+	}
+	return nil
+}
+
+func (r *memoryWebhookRepository) ClaimDueDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]entity.WebhookDelivery, error) {
+	var due []entity.WebhookDelivery
+	for _, d := range r.deliveries {
+		if d.Status == entity.WebhookDeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, *d)
+		}
+	}
+	return due, nil
+}
+
+func (r *memoryWebhookRepository) UpdateDelivery(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *delivery
+	r.deliveries[delivery.ID-1] = &copied
+	return nil
+}
+
+func TestWebhookService_SignedDeliveryRetriesAndDeadLetter(t *testing.T) {
+	const secret = "0123456789abcdef"
+
+	var mu sync.Mutex
+	failing := true
+	var received []*http.Request
+	var bodies [][]byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, r)
+		bodies = append(bodies, body)
+		if failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{webhooks: map[int]*entity.Webhook{
+		1: {ID: 1, OwnerID: "user1", URL: receiver.URL, EventTypes: []string{events.TypeAnswerCreated}, Secret: secret, Active: true},
+		2: {ID: 2, OwnerID: "user1", URL: receiver.URL, EventTypes: []string{events.TypeQuestionCreated}, Secret: secret, Active: true},
+	}}
+	svc := NewWebhookService(repo, receiver.Client(), WebhookDeliveryPolicy{
+		MaxAttempts: 3,
+		BackoffBase: time.Second,
+		BackoffMax:  time.Minute,
+		Timeout:     time.Second,
+		BatchSize:   10,
+	}).(*webhookService)
+	now := time.Now()
+	svc.now = func() time.Time { return now }
+
+	event, err := events.NewEvent(events.TypeAnswerCreated, 1, map[string]int{"id": 10})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := svc.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repo.deliveries) != 1 || repo.deliveries[0].WebhookID != 1 {
+		t.Fatalf("expected 1 delivery for webhook 1, got %+v", repo.deliveries)
+	}
+
+	if _, err := svc.DeliverDue(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	delivery := repo.deliveries[0]
+	if delivery.Status != entity.WebhookDeliveryPending || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusInternalServerError {
+		t.Errorf("expected pending delivery after 1 failed attempt, got %+v", delivery)
+	}
+	if !delivery.NextAttemptAt.Equal(now.Add(time.Second)) {
+		t.Errorf("expected retry in 1s, got %v", delivery.NextAttemptAt.Sub(now))
+	}
+
+	req := received[0]
+	timestamp, _ := strconv.ParseInt(req.Header.Get(WebhookTimestampHeader), 10, 64)
+	if got, want := req.Header.Get(WebhookSignatureHeader), SignWebhookPayload(secret, timestamp, bodies[0]); got != want {
+		t.Errorf("expected signature %s, got %s", want, got)
+	}
+	if req.Header.Get(WebhookEventHeader) != events.TypeAnswerCreated {
+		t.Errorf("expected event header %s, got %s", events.TypeAnswerCreated, req.Header.Get(WebhookEventHeader))
+	}
+
+	// до истечения задержки доставка не повторяется
+	if processed, _ := svc.DeliverDue(context.Background()); processed != 0 {
+		t.Errorf("expected no due deliveries, got %d", processed)
+	}
+
+	now = now.Add(time.Second)
+	_, _ = svc.DeliverDue(context.Background())
+	if delivery := repo.deliveries[0]; !delivery.NextAttemptAt.Equal(now.Add(2 * time.Second)) {
+		t.Errorf("expected retry in 2s, got %v", delivery.NextAttemptAt.Sub(now))
+	}
+
+	now = now.Add(2 * time.Second)
+	_, _ = svc.DeliverDue(context.Background())
+	if delivery := repo.deliveries[0]; delivery.Status != entity.WebhookDeliveryDead || delivery.Attempts != 3 {
+		t.Errorf("expected dead delivery after 3 attempts, got %+v", delivery)
+	}
+
+	// повтор из dead-letter после починки получателя
+	mu.Lock()
+	failing = false
+	mu.Unlock()
+	repo.deliveries[0].Status = entity.WebhookDeliveryPending
+	repo.deliveries[0].Attempts = 0
+	_, _ = svc.DeliverDue(context.Background())
+	if delivery := repo.deliveries[0]; delivery.Status != entity.WebhookDeliverySucceeded || delivery.ResponseStatus != http.StatusNoContent {
+		t.Errorf("expected succeeded delivery, got %+v", delivery)
+	}
+}
+
+func TestValidateWebhook(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		eventTypes []string
+		secret     string
+		want       error
+	}{
+		{"valid", "https://example.com/hook", []string{events.TypeAnswerCreated}, "", nil},
+		{"relative url", "/hook", []string{events.TypeAnswerCreated}, "", entity.ErrInvalidWebhookURL},
+		{"unsupported scheme", "ftp://example.com", []string{events.TypeAnswerCreated}, "", entity.ErrInvalidWebhookURL},
+		{"no event types", "https://example.com/hook", nil, "", entity.ErrInvalidWebhookEventTypes},
+		{"unknown event type", "https://example.com/hook", []string{"answer.edited"}, "", entity.ErrInvalidWebhookEventTypes},
+		{"short secret", "https://example.com/hook", []string{events.TypeAnswerCreated}, "short", entity.ErrInvalidWebhookSecret},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateWebhook(tt.url, tt.eventTypes, tt.secret); err != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestForbiddenWebhookIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", false},
+		{"2606:2800:220:1::1", false},
+		{"127.0.0.1", true},
+		{"10.0.0.5", true},
+		{"169.254.169.254", true},
+		{"::ffff:192.168.1.1", true},
+		{"100.64.0.1", true},
+		{"100.127.255.254", true},
+		{"100.128.0.1", false},
+		{"198.18.0.1", true},
+		{"198.19.255.254", true},
+		{"198.20.0.1", false},
+		{"64:ff9b::a00:5", true},
+		{"64:ff9b::5db8:d822", true},
+		{"64:ff9b:1::a00:5", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := forbiddenWebhookIP(net.ParseIP(tt.ip)); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWebhookService_RejectsInternalAddresses(t *testing.T) {
+	repo := &memoryWebhookRepository{webhooks: map[int]*entity.Webhook{}}
+	svc := NewWebhookService(repo, nil, WebhookDeliveryPolicy{MaxAttempts: 1, Timeout: time.Second, BatchSize: 10}).(*webhookService)
+	svc.lookupHost = func(ctx context.Context, host string) ([]net.IPAddr, error) {
+		if host == "internal.example.com" {
+			return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}, {IP: net.ParseIP("10.0.0.5")}}, nil
+		}
+		return []net.IPAddr{{IP: net.ParseIP("93.184.216.34")}}, nil
+	}
+
+	tests := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{"loopback", "http://127.0.0.1:8080/hook", entity.ErrForbiddenWebhookURL},
+		{"ipv6 loopback", "http://[::1]/hook", entity.ErrForbiddenWebhookURL},
+		{"cloud metadata", "http://169.254.169.254/latest/meta-data", entity.ErrForbiddenWebhookURL},
+		{"unspecified", "http://0.0.0.0/hook", entity.ErrForbiddenWebhookURL},
+		{"resolves to private", "https://internal.example.com/hook", entity.ErrForbiddenWebhookURL},
+		{"public", "https://example.com/hook", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.CreateWebhook(context.Background(), "user1", tt.url, []string{events.TypeAnswerCreated}, "")
+			if !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	// имя, которое после регистрации стало указывать на loopback, блокируется при соединении,
+	// а в журнал доставок не попадает текст ошибки транспорта
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected delivery to internal address to be blocked")
+	}))
+	defer receiver.Close()
+	repo.webhooks[1].URL = receiver.URL
+	event, _ := events.NewEvent(events.TypeAnswerCreated, 1, map[string]int{"id": 10})
+	if err := svc.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = svc.DeliverDue(context.Background())
+	if delivery := repo.deliveries[0]; delivery.LastError != errWebhookAddressForbidden.Error() {
+		t.Errorf("expected forbidden address error, got %q", delivery.LastError)
+	}
+}
+
+func TestWebhookService_DoesNotFollowRedirects(t *testing.T) {
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("expected redirect not to be followed")
+	}))
+	defer target.Close()
+	receiver := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusFound))
+	defer receiver.Close()
+
+	repo := &memoryWebhookRepository{webhooks: map[int]*entity.Webhook{
+		1: {ID: 1, OwnerID: "user1", URL: receiver.URL, EventTypes: []string{events.TypeAnswerCreated}, Secret: "0123456789abcdef", Active: true},
+	}}
+	svc := NewWebhookService(repo, receiver.Client(), WebhookDeliveryPolicy{MaxAttempts: 3, BackoffBase: time.Second, BackoffMax: time.Minute, Timeout: time.Second, BatchSize: 10})
+	event, _ := events.NewEvent(events.TypeAnswerCreated, 1, map[string]int{"id": 10})
+	if err := svc.Publish(context.Background(), event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	_, _ = svc.DeliverDue(context.Background())
+	if delivery := repo.deliveries[0]; delivery.Status != entity.WebhookDeliveryPending || delivery.ResponseStatus != http.StatusFound {
+		t.Errorf("expected failed attempt with 302, got %+v", delivery)
+	}
+}
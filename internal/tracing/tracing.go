@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+
+	"github.com/andrey-samosuk/answer-questions/internal/config"
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// ShutdownFunc сбрасывает накопленные спаны и освобождает ресурсы экспортера
+type ShutdownFunc func(ctx context.Context) error
+
+// Setup настраивает глобальные TracerProvider, MeterProvider и W3C propagator (traceparent, baggage).
+// Метрики экспортируются тем же способом, что и спаны.
+// При Exporter=none спаны не экспортируются, но контекст трассировки всё равно пробрасывается.
+func Setup(ctx context.Context, cfg config.TracingConfig) (ShutdownFunc, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	if cfg.Exporter == ExporterNone || cfg.Exporter == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, closer, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания ресурса трассировки: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	var writer io.Writer = os.Stdout
+	if file, ok := closer.(io.Writer); ok {
+		writer = file
+	}
+	metricExporter, err := newMetricExporter(ctx, cfg, writer)
+	if err != nil {
+		return nil, err
+	}
+	meterProvider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(meterProvider)
+
+	return func(ctx context.Context) error {
+		err := errors.Join(provider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+		if closer != nil {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg config.TracingConfig) (sdktrace.SpanExporter, io.Closer, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка создания OTLP экспортера: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка создания stdout экспортера: %w", err)
+		}
+		return exporter, nil, nil
+	case ExporterFile:
+		file, err := os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("ошибка открытия файла трассировки: %w", err)
+		}
+		exporter, err := stdouttrace.New(stdouttrace.WithWriter(file))
+		if err != nil {
+			_ = file.Close()
+			return nil, nil, fmt.Errorf("ошибка создания файлового экспортера: %w", err)
+		}
+		return exporter, file, nil
+	default:
+		return nil, nil, fmt.Errorf("неизвестный экспортер трассировки: %s", cfg.Exporter)
+	}
+}
+
+func newMetricExporter(ctx context.Context, cfg config.TracingConfig, writer io.Writer) (sdkmetric.Exporter, error) {
+	switch cfg.Exporter {
+	case ExporterOTLP:
+		opts := []otlpmetrichttp.Option{otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			opts = append(opts, otlpmetrichttp.WithInsecure())
+		}
+		exporter, err := otlpmetrichttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания OTLP экспортера метрик: %w", err)
+		}
+		return exporter, nil
+	default:
+		exporter, err := stdoutmetric.New(stdoutmetric.WithWriter(writer))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания экспортера метрик: %w", err)
+		}
+		return exporter, nil
+	}
+}
@@ -0,0 +1,20 @@
+-- +goose Up
+-- Create idempotency keys table
+CREATE TABLE idempotency_keys (
+    scope VARCHAR(255) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    fingerprint CHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    response_status INTEGER,
+    response_body BYTEA,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (scope, key)
+);
+
+CREATE INDEX idx_idempotency_keys_expires_at ON idempotency_keys (expires_at);
+
+
+-- +goose Down
+-- Drop idempotency keys table
+DROP TABLE idempotency_keys;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Add version and updated_at columns for ETag and conditional requests
+ALTER TABLE questions
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE answers
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE questions SET updated_at = created_at;
+UPDATE answers SET updated_at = created_at;
+
+
+-- +goose Down
+-- Drop version and updated_at columns
+ALTER TABLE answers
+    DROP COLUMN updated_at,
+    DROP COLUMN version;
+
+ALTER TABLE questions
+    DROP COLUMN updated_at,
+    DROP COLUMN version;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Create background jobs table for admin bulk operations
+CREATE TABLE admin_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(64) NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    params JSONB NOT NULL,
+    total BIGINT NOT NULL DEFAULT 0,
+    processed BIGINT NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by VARCHAR(255) NOT NULL,
+    lease_until TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_admin_jobs_active ON admin_jobs (id) WHERE status IN ('pending', 'running');
+CREATE INDEX idx_answers_user_id ON answers (user_id);
+CREATE INDEX idx_answers_created_at ON answers (created_at);
+
+
+-- +goose Down
+-- Drop admin jobs table
+DROP INDEX idx_answers_created_at;
+DROP INDEX idx_answers_user_id;
+DROP TABLE admin_jobs;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Create append-only audit log of mutating operations
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    entity_type VARCHAR(32) NOT NULL,
+    entity_id INTEGER NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(64) NOT NULL,
+    ip VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_events_entity ON audit_events (entity_type, entity_id, id DESC);
+CREATE INDEX idx_audit_events_actor ON audit_events (actor, id DESC);
+
+-- журнал только дополняется: изменение и удаление записей запрещены на уровне БД
+-- +goose StatementBegin
+CREATE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW EXECUTE FUNCTION audit_events_append_only();
+
+
+-- +goose Down
+-- Drop audit log
+DROP TABLE audit_events;
+DROP FUNCTION audit_events_append_only();
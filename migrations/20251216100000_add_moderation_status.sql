@@ -0,0 +1,38 @@
+-- +goose Up
+-- Add moderation workflow to questions and answers and notifications for authors
+ALTER TABLE questions
+    ADD COLUMN author_id VARCHAR(255) NOT NULL DEFAULT '',
+    ADD COLUMN moderation_status VARCHAR(16) NOT NULL DEFAULT 'approved',
+    ADD COLUMN moderation_reason VARCHAR(1000) NOT NULL DEFAULT '';
+
+ALTER TABLE answers
+    ADD COLUMN moderation_status VARCHAR(16) NOT NULL DEFAULT 'approved',
+    ADD COLUMN moderation_reason VARCHAR(1000) NOT NULL DEFAULT '';
+
+-- очередь модерации читается по возрастанию ID, в индексы попадают только ожидающие проверки
+CREATE INDEX idx_questions_moderation_pending ON questions (id) WHERE moderation_status = 'pending';
+CREATE INDEX idx_answers_moderation_pending ON answers (id) WHERE moderation_status = 'pending';
+
+CREATE TABLE notifications (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL,
+    type VARCHAR(64) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, id DESC);
+
+
+-- +goose Down
+-- Drop moderation workflow
+DROP TABLE notifications;
+
+ALTER TABLE answers
+    DROP COLUMN moderation_reason,
+    DROP COLUMN moderation_status;
+
+ALTER TABLE questions
+    DROP COLUMN moderation_reason,
+    DROP COLUMN moderation_status,
+    DROP COLUMN author_id;
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Create user flags on questions and answers
+CREATE TABLE flags (
+    id BIGSERIAL PRIMARY KEY,
+    item_type VARCHAR(16) NOT NULL,
+    item_id INTEGER NOT NULL,
+    user_id VARCHAR(255) NOT NULL,
+    reason VARCHAR(32) NOT NULL,
+    comment VARCHAR(1000) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'open',
+    resolved_by VARCHAR(255) NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- одна открытая жалоба пользователя на вопрос или ответ; после разбора можно пожаловаться снова
+CREATE UNIQUE INDEX idx_flags_open_user ON flags (item_type, item_id, user_id) WHERE status = 'open';
+CREATE INDEX idx_flags_user_id ON flags (user_id, id);
+
+
+-- +goose Down
+-- Drop user flags
+DROP TABLE flags;
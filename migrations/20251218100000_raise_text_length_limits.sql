@@ -0,0 +1,10 @@
+-- +goose Up
+-- Raise question and answer text limits for Markdown with code blocks
+ALTER TABLE questions ALTER COLUMN text TYPE VARCHAR(10000);
+ALTER TABLE answers ALTER COLUMN text TYPE VARCHAR(10000);
+
+
+-- +goose Down
+-- Restore 1000 character text limits; longer texts are truncated
+ALTER TABLE answers ALTER COLUMN text TYPE VARCHAR(1000) USING left(text, 1000);
+ALTER TABLE questions ALTER COLUMN text TYPE VARCHAR(1000) USING left(text, 1000);
@@ -0,0 +1,23 @@
+-- +goose Up
+-- Create comments on answers and questions
+CREATE TABLE comments (
+    id SERIAL PRIMARY KEY,
+    answer_id INTEGER REFERENCES answers(id) ON DELETE CASCADE,
+    question_id INTEGER REFERENCES questions(id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL,
+    text VARCHAR(1000) NOT NULL,
+    version INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    -- комментарий относится ровно к одному ответу или вопросу
+    CONSTRAINT comments_single_target CHECK (num_nonnulls(answer_id, question_id) = 1)
+);
+
+CREATE INDEX idx_comments_answer_id ON comments (answer_id, id) WHERE answer_id IS NOT NULL;
+CREATE INDEX idx_comments_question_id ON comments (question_id, id) WHERE question_id IS NOT NULL;
+CREATE INDEX idx_comments_user_id ON comments (user_id, id);
+
+
+-- +goose Down
+-- Drop comments
+DROP TABLE comments;
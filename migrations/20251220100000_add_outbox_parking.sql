@@ -0,0 +1,20 @@
+-- +goose Up
+-- Park outbox events after max attempts and track delivery per sink
+ALTER TABLE outbox
+    ADD COLUMN delivered_sinks JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN parked_at TIMESTAMP;
+
+DROP INDEX idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox (id) WHERE published_at IS NULL AND parked_at IS NULL;
+CREATE INDEX idx_outbox_parked ON outbox (id) WHERE parked_at IS NOT NULL;
+
+
+-- +goose Down
+-- Remove outbox parking and per-sink delivery
+DROP INDEX idx_outbox_parked;
+DROP INDEX idx_outbox_unpublished;
+CREATE INDEX idx_outbox_unpublished ON outbox (id) WHERE published_at IS NULL;
+
+ALTER TABLE outbox
+    DROP COLUMN parked_at,
+    DROP COLUMN delivered_sinks;
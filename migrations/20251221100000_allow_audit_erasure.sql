@@ -0,0 +1,24 @@
+-- +goose Up
+-- Allow audit log updates only inside a user data erasure transaction
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    -- удаление данных пользователя (GDPR) обезличивает его записи; флаг ставится только на свою транзакцию
+    IF TG_OP = 'UPDATE' AND current_setting('app.audit_erasure', true) = 'on' THEN
+        RETURN NEW;
+    END IF;
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+
+-- +goose Down
+-- Make audit log strictly append-only again
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- Scope idempotency keys by client and store replayed response headers
+ALTER TABLE idempotency_keys
+    ALTER COLUMN scope TYPE VARCHAR(1024),
+    ADD COLUMN response_headers JSONB;
+
+
+-- +goose Down
+-- Remove replayed response headers; keys scoped by client no longer fit the old column
+DELETE FROM idempotency_keys WHERE LENGTH(scope) > 255;
+
+ALTER TABLE idempotency_keys
+    DROP COLUMN response_headers,
+    ALTER COLUMN scope TYPE VARCHAR(255);
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Fence admin job updates with a lease token
+ALTER TABLE admin_jobs ADD COLUMN lease_token VARCHAR(32);
+
+
+-- +goose Down
+-- Remove admin job lease token
+ALTER TABLE admin_jobs DROP COLUMN lease_token;
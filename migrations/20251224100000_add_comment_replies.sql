@@ -0,0 +1,10 @@
+-- +goose Up
+-- Allow one level of replies to comments
+ALTER TABLE comments ADD COLUMN parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+
+CREATE INDEX idx_comments_parent_id ON comments (parent_id) WHERE parent_id IS NOT NULL;
+
+
+-- +goose Down
+-- Remove comment replies
+ALTER TABLE comments DROP COLUMN parent_id;